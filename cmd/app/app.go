@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"subcalc/internal/config"
+	"subcalc/internal/domain"
+	"subcalc/internal/infrastructure/db"
+	"subcalc/internal/infrastructure/server"
+	loggerpkg "subcalc/internal/logger"
+	memrepo "subcalc/internal/repository/memory"
+	"subcalc/internal/tenant"
+	"subcalc/internal/usecase"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// app holds what every command needs: the config and a logger.
+type app struct {
+	cfg *config.Config
+	log *zap.SugaredLogger
+	raw *zap.Logger
+}
+
+// newApp loads the config and sets up logging. Only serve logs to stdout;
+// the other commands log to stderr so their output can be piped.
+func newApp(toStdout bool) (*app, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var raw *zap.Logger
+	if toStdout {
+		raw, err = loggerpkg.New(cfg.LogLevel, false)
+	} else {
+		raw, err = loggerpkg.NewStderr(cfg.LogLevel)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to init logger: %w", err)
+	}
+	return &app{cfg: cfg, log: raw.Sugar(), raw: raw}, nil
+}
+
+func (a *app) close() {
+	_ = a.raw.Sync()
+}
+
+func (a *app) openDB() (*gorm.DB, error) {
+	database, err := db.Open(a.cfg, a.log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to db: %w", err)
+	}
+	return database, nil
+}
+
+// storage is the opened STORAGE backend.
+type storage struct {
+	repos server.Repositories
+	// db is nil for memory storage
+	db *gorm.DB
+	// save persists memory storage to its snapshot file, if one is set
+	save func() error
+}
+
+func (a *app) openStorage() (*storage, error) {
+	if a.cfg.Storage == config.StorageMemory {
+		store := memrepo.NewStore()
+		st := &storage{repos: server.MemoryRepositories(store), save: func() error { return nil }}
+		if path := a.cfg.StorageSnapshotFile; path != "" {
+			if err := store.Load(path); err != nil {
+				return nil, fmt.Errorf("load snapshot: %w", err)
+			}
+			st.save = func() error { return store.Save(path) }
+		}
+		return st, nil
+	}
+
+	database, err := a.openDB()
+	if err != nil {
+		return nil, err
+	}
+	return &storage{
+		repos: server.GormRepositories(database),
+		db:    database,
+		save:  func() error { return nil },
+	}, nil
+}
+
+// dataCommand is the shared setup of the commands working on subscriptions:
+// a usecase without a principal (full access) bound to the -org tenant.
+type dataCommand struct {
+	*app
+	store *storage
+	uc    usecase.SubscriptionUsecase
+	ctx   context.Context
+}
+
+func newDataCommand(orgFlag string) (*dataCommand, error) {
+	a, err := newApp(false)
+	if err != nil {
+		return nil, err
+	}
+	st, err := a.openStorage()
+	if err != nil {
+		a.close()
+		return nil, err
+	}
+
+	orgID := tenant.DefaultOrgID
+	if orgFlag != "" {
+		if orgID, err = uuid.Parse(orgFlag); err != nil {
+			a.close()
+			return nil, fmt.Errorf("-org must be a UUID")
+		}
+	}
+	ctx := context.Background()
+	org, err := st.repos.Organizations.GetByID(ctx, orgID)
+	if errors.Is(err, domain.ErrNotFound) {
+		a.close()
+		return nil, fmt.Errorf("organization %s not found", orgID)
+	}
+	if err != nil {
+		a.close()
+		return nil, fmt.Errorf("resolve organization: %w", err)
+	}
+
+	return &dataCommand{
+		app:   a,
+		store: st,
+		uc:    usecase.NewSubscriptionUsecase(st.repos.Subscriptions),
+		ctx:   tenant.WithOrganization(ctx, org),
+	}, nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+func parseUserFlag(s string) (*uuid.UUID, error) {
+	if s == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("-user must be a UUID")
+	}
+	return &id, nil
+}
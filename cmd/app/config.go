@@ -0,0 +1,18 @@
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+func runConfig(args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return errors.New("expected print")
+	}
+	a, err := newApp(false)
+	if err != nil {
+		return err
+	}
+	defer a.close()
+	return a.cfg.Print(os.Stdout)
+}
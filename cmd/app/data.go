@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"subcalc/internal/tenant"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var seedServices = []struct {
+	name  string
+	price int
+}{
+	{"Netflix", 799},
+	{"Yandex Plus", 399},
+	{"Spotify", 299},
+	{"Kinopoisk", 269},
+	{"YouTube Premium", 299},
+	{"iCloud", 149},
+}
+
+func runSeed(args []string) error {
+	fs := newFlagSet("seed")
+	users := fs.Int("users", 3, "number of users to create")
+	perUser := fs.Int("per-user", 4, "subscriptions per user")
+	seed := fs.Int64("seed", time.Now().UnixNano(), "random seed")
+	org := fs.String("org", "", "organization id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *users < 1 || *perUser < 1 {
+		return errors.New("-users and -per-user must be positive")
+	}
+
+	dc, err := newDataCommand(*org)
+	if err != nil {
+		return err
+	}
+	defer dc.close()
+
+	rnd := rand.New(rand.NewSource(*seed))
+	now := time.Now().UTC()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	created := 0
+	for range *users {
+		userID := uuid.New()
+		for range *perUser {
+			svc := seedServices[rnd.Intn(len(seedServices))]
+			sub := &domain.Subscription{
+				ServiceName: svc.name,
+				Price:       svc.price,
+				UserID:      userID,
+				StartDate:   thisMonth.AddDate(0, -rnd.Intn(24), 0),
+			}
+			// about a third of them have already ended or end soon
+			if rnd.Intn(3) == 0 {
+				end := sub.StartDate.AddDate(0, 1+rnd.Intn(12), 0)
+				sub.EndDate = &end
+			}
+			if err := dc.uc.Create(dc.ctx, sub); err != nil {
+				return fmt.Errorf("create subscription: %w", err)
+			}
+			created++
+		}
+		fmt.Fprintf(os.Stdout, "user %s\n", userID)
+	}
+	if err := dc.store.save(); err != nil {
+		return fmt.Errorf("save snapshot: %w", err)
+	}
+	dc.log.Infof("seeded %d subscriptions for %d users", created, *users)
+	return nil
+}
+
+func runSum(args []string) error {
+	fs := newFlagSet("sum")
+	fromStr := fs.String("from", "", "first month, MM-YYYY (required)")
+	toStr := fs.String("to", "", "last month, MM-YYYY (required)")
+	user := fs.String("user", "", "user id")
+	service := fs.String("service", "", "service name")
+	org := fs.String("org", "", "organization id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *fromStr == "" || *toStr == "" {
+		return errors.New("-from and -to are required")
+	}
+	from, err := domain.ParseMonthYear(*fromStr)
+	if err != nil {
+		return fmt.Errorf("-from: %w", err)
+	}
+	to, err := domain.ParseMonthYear(*toStr)
+	if err != nil {
+		return fmt.Errorf("-to: %w", err)
+	}
+	if from.After(to) {
+		return errors.New("-from must be before or equal to -to")
+	}
+	filter := repository.SubscriptionFilter{From: &from, To: &to}
+	if filter.UserID, err = parseUserFlag(*user); err != nil {
+		return err
+	}
+	if *service != "" {
+		filter.ServiceName = service
+	}
+
+	dc, err := newDataCommand(*org)
+	if err != nil {
+		return err
+	}
+	defer dc.close()
+
+	total, err := dc.uc.SumSubscriptions(dc.ctx, filter)
+	if err != nil {
+		return err
+	}
+	currency := "RUB"
+	if o, ok := tenant.Organization(dc.ctx); ok {
+		currency = o.DefaultCurrency
+	}
+	fmt.Fprintf(os.Stdout, "%d %s\n", total, currency)
+	return nil
+}
+
+func runExport(args []string) error {
+	fs := newFlagSet("export")
+	out := fs.String("out", "", "output file (default stdout)")
+	user := fs.String("user", "", "only this user's subscriptions")
+	org := fs.String("org", "", "organization id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var filter repository.SubscriptionFilter
+	var err error
+	if filter.UserID, err = parseUserFlag(*user); err != nil {
+		return err
+	}
+
+	dc, err := newDataCommand(*org)
+	if err != nil {
+		return err
+	}
+	defer dc.close()
+
+	// one query sized by Count instead of paging, which would need a stable
+	// order to not skip or repeat rows
+	n, err := dc.uc.Count(dc.ctx, filter)
+	if err != nil {
+		return err
+	}
+	subs := []*domain.Subscription{}
+	if n > 0 {
+		filter.Limit = int(n)
+		if subs, err = dc.uc.List(dc.ctx, filter); err != nil {
+			return err
+		}
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(subs); err != nil {
+		return err
+	}
+	dc.log.Infof("exported %d subscriptions", len(subs))
+	return nil
+}
+
+// runImport reads the format written by export. Subscriptions go to the
+// -org organization whatever org_id the file says; ids are kept unless
+// -new-ids is set, so importing the same file twice fails on the duplicates.
+func runImport(args []string) error {
+	fs := newFlagSet("import")
+	in := fs.String("in", "", "input file (default stdin)")
+	newIDs := fs.Bool("new-ids", false, "assign fresh ids instead of keeping the file's")
+	org := fs.String("org", "", "organization id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	r := io.Reader(os.Stdin)
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	var subs []*domain.Subscription
+	if err := json.NewDecoder(r).Decode(&subs); err != nil {
+		return fmt.Errorf("decode input: %w", err)
+	}
+
+	dc, err := newDataCommand(*org)
+	if err != nil {
+		return err
+	}
+	defer dc.close()
+
+	failed := 0
+	for i, sub := range subs {
+		if err := validateImported(sub); err != nil {
+			dc.log.Errorf("item %d: %v", i, err)
+			failed++
+			continue
+		}
+		if *newIDs {
+			sub.ID = uuid.Nil
+		}
+		if err := dc.uc.Create(dc.ctx, sub); err != nil {
+			dc.log.Errorf("item %d (%s): %v", i, sub.ID, err)
+			failed++
+		}
+	}
+	if err := dc.store.save(); err != nil {
+		return fmt.Errorf("save snapshot: %w", err)
+	}
+	dc.log.Infof("imported %d of %d subscriptions", len(subs)-failed, len(subs))
+	if failed > 0 {
+		return fmt.Errorf("%d subscriptions failed to import", failed)
+	}
+	return nil
+}
+
+// validateImported applies the rules the create endpoint enforces.
+func validateImported(sub *domain.Subscription) error {
+	switch {
+	case sub == nil:
+		return errors.New("null item")
+	case strings.TrimSpace(sub.ServiceName) == "" || len(sub.ServiceName) > 255:
+		return errors.New("service_name is required and must be <=255 chars")
+	case sub.Price < 0:
+		return errors.New("price must be >= 0")
+	case sub.UserID == uuid.Nil:
+		return errors.New("user_id is required")
+	case sub.EndDate != nil && sub.EndDate.Before(sub.StartDate):
+		return errors.New("end_date must be after or equal to start_date")
+	}
+	return nil
+}
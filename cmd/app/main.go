@@ -3,55 +3,60 @@ package main
 // @title Subscriptions API
 // @version 1.0
 // @description Simple service to track user subscriptions (monthly prices).
+// @description Errors are ErrorResponse objects, or RFC 7807 Problem objects for clients sending "Accept: application/problem+json".
+// @description Error messages follow Accept-Language; English and Russian are available.
 
 import (
-	"log"
-	"subcalc/internal/config"
-	"subcalc/internal/infrastructure/db"
-	"subcalc/internal/infrastructure/server"
-	loggerpkg "subcalc/internal/logger"
+	"fmt"
+	"os"
+	"strings"
 )
 
-func main() {
-	// CFG
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatalf("failed to load config: %v", err)
-	}
-
-	// LOGGER
-
-	rawLog, err := loggerpkg.New(cfg.LogLevel, false)
-	if err != nil {
-		log.Fatalf("failed to init logger: %v", err)
-	}
-	defer func() {
-		_ = rawLog.Sync()
-	}()
-
-	sugar := rawLog.Sugar()
-	sugar.Infof("starting subscriptions service on port %s", cfg.AppPort)
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
 
-	// DB
+var commands = []command{
+	{"serve", "serve                                  run the HTTP API (default)", runServe},
+	{"migrate", "migrate up | down [-steps N] | status  apply, revert or inspect migrations", runMigrate},
+	{"seed", "seed [-users N] [-per-user N]          insert demo subscriptions", runSeed},
+	{"sum", "sum -from MM-YYYY -to MM-YYYY [-user ID] [-service NAME]", runSum},
+	{"export", "export [-out FILE] [-user ID]          write subscriptions as JSON", runExport},
+	{"import", "import [-in FILE] [-new-ids]           create subscriptions from JSON", runImport},
+	{"config", "config print                           show effective settings, secrets masked", runConfig},
+}
 
-	database, err := db.NewPostgres(cfg, sugar)
-	if err != nil {
-		sugar.Fatalf("failed to connect to db: %v", err)
+func main() {
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
 	}
 
-	if cfg.AutoMigrate {
-		if err := db.AutoMigrate(database); err != nil {
-			sugar.Fatalf("auto-migrate failed: %v", err)
+	for _, c := range commands {
+		if c.name == name {
+			if err := c.run(args); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+				os.Exit(1)
+			}
+			return
 		}
-		sugar.Info("auto-migrate finished")
-	} else {
-		sugar.Info("auto-migrate disabled")
 	}
 
-	// APP
+	if name != "help" {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	}
+	usage()
+	os.Exit(2)
+}
 
-	s := server.NewServer(cfg, database, sugar)
-	if err := s.Run(); err != nil {
-		sugar.Fatalf("server stopped with error: %v", err)
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: subscriptions <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %s\n", c.usage)
 	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "data commands accept -org ID; the default organization is used otherwise.")
 }
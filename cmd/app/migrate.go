@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"subcalc/internal/config"
+	"subcalc/internal/infrastructure/db"
+	"subcalc/internal/migrate"
+)
+
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected up, down or status")
+	}
+	action, args := args[0], args[1:]
+
+	fs := newFlagSet("migrate " + action)
+	steps := 1
+	if action == "down" {
+		fs.IntVar(&steps, "steps", 1, "number of migrations to revert")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if steps < 1 {
+		return errors.New("-steps must be at least 1")
+	}
+
+	a, err := newApp(false)
+	if err != nil {
+		return err
+	}
+	defer a.close()
+	if a.cfg.Storage != config.StorageDatabase {
+		return fmt.Errorf("migrations apply to STORAGE=%s only", config.StorageDatabase)
+	}
+
+	database, err := a.openDB()
+	if err != nil {
+		return err
+	}
+	m, err := db.NewMigrator(database, a.log)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch action {
+	case "up":
+		if err := m.Up(ctx); err != nil {
+			return err
+		}
+	case "down":
+		if err := m.Down(ctx, steps); err != nil {
+			return err
+		}
+	case "status":
+	default:
+		return fmt.Errorf("unknown action %q, expected up, down or status", action)
+	}
+	return printStatus(ctx, m)
+}
+
+func printStatus(ctx context.Context, m *migrate.Migrator) error {
+	st, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "version %d of %d", st.Current, st.Latest)
+	if st.Dirty {
+		fmt.Fprint(os.Stdout, " (dirty)")
+	}
+	fmt.Fprintln(os.Stdout)
+	for _, mig := range st.Migrations {
+		mark := " "
+		if mig.Applied {
+			mark = "x"
+		}
+		fmt.Fprintf(os.Stdout, "  [%s] %04d_%s\n", mark, mig.Version, mig.Name)
+	}
+	return nil
+}
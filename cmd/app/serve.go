@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"subcalc/internal/infrastructure/db"
+	"subcalc/internal/infrastructure/server"
+	"subcalc/internal/tracing"
+	"time"
+)
+
+func runServe(args []string) error {
+	if err := newFlagSet("serve").Parse(args); err != nil {
+		return err
+	}
+
+	a, err := newApp(true)
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	a.log.Infof("starting subscriptions service on port %s", a.cfg.AppPort)
+
+	// TRACING
+
+	shutdownTracing, err := tracing.Setup(context.Background(), a.cfg.TracingExporter, a.cfg.TracingSampleRatio)
+	if err != nil {
+		return fmt.Errorf("failed to init tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			a.log.Errorf("tracing shutdown: %v", err)
+		}
+	}()
+
+	// STORAGE
+
+	st, err := a.openStorage()
+	if err != nil {
+		return err
+	}
+
+	if st.db == nil {
+		a.log.Infof("using memory storage, snapshot file %q", a.cfg.StorageSnapshotFile)
+	} else if a.cfg.AutoMigrate {
+		m, err := db.NewMigrator(st.db, a.log)
+		if err != nil {
+			return fmt.Errorf("auto-migrate failed: %w", err)
+		}
+		if err := m.Up(context.Background()); err != nil {
+			return fmt.Errorf("auto-migrate failed: %w", err)
+		}
+		a.log.Infof("auto-migrate finished at version %d", m.Latest())
+	} else {
+		a.log.Info("auto-migrate disabled")
+	}
+
+	// APP
+
+	s := server.NewServer(a.cfg, st.repos, st.db, a.log)
+	runErr := s.Run()
+	if err := st.save(); err != nil {
+		a.log.Errorf("save snapshot: %v", err)
+	}
+	if runErr != nil {
+		return fmt.Errorf("server stopped with error: %w", runErr)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type Claims struct {
+	Role string `json:"role"`
+	Org  string `json:"org,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// ParseToken validates an HS256 token and builds a principal from its claims.
+// "sub" must hold the user uuid and the optional "org" the organization
+// uuid; see ParseRole for the "role" claim.
+func ParseToken(secret, tokenStr string) (*Principal, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(tokenStr, &claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	uid, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: sub must be a uuid", ErrInvalidToken)
+	}
+
+	role, ok := ParseRole(claims.Role)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidToken, claims.Role)
+	}
+
+	p := &Principal{UserID: uid, Role: role}
+	if claims.Org != "" {
+		orgID, err := uuid.Parse(claims.Org)
+		if err != nil {
+			return nil, fmt.Errorf("%w: org must be a uuid", ErrInvalidToken)
+		}
+		p.OrgID = &orgID
+	}
+	return p, nil
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID uuid.UUID
+	Role   Role
+	// Organization the credentials were issued for; nil means the default
+	// organization.
+	OrgID *uuid.UUID
+
+	// Set only for callers authenticated with an API key.
+	APIKeyID *uuid.UUID
+	Scopes   []Permission
+}
+
+// Can reports whether the principal holds perm, through its scopes for API
+// keys and through its role otherwise.
+func (p *Principal) Can(perm Permission) bool {
+	if p.APIKeyID != nil {
+		return slices.Contains(p.Scopes, perm)
+	}
+	return slices.Contains(rolePermissions[p.Role], perm)
+}
+
+// SeesAllUsers reports whether the principal is not bound to a single user.
+// API keys are not tied to a user and are limited by scopes instead.
+func (p *Principal) SeesAllUsers() bool {
+	return p.Role == RoleService || p.Can(PermAllUsers)
+}
+
+// CanAccessUser reports whether the principal may see data owned by userID.
+func (p *Principal) CanAccessUser(userID uuid.UUID) bool {
+	return p.SeesAllUsers() || p.UserID == userID
+}
+
+type ctxKeyType struct{}
+
+var principalCtxKey = ctxKeyType{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalCtxKey, p)
+}
+
+// FromContext returns the principal stored in ctx. Requests that went through
+// no authentication (auth disabled, internal callers) have no principal.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalCtxKey).(*Principal)
+	return p, ok && p != nil
+}
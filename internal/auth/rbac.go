@@ -0,0 +1,73 @@
+package auth
+
+import "slices"
+
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+	// RoleService is assigned to API keys; what they may do is defined by
+	// their scopes, not by a role.
+	RoleService Role = "service"
+)
+
+type Permission string
+
+const (
+	PermSubscriptionsRead       Permission = "subscriptions:read"
+	PermSubscriptionsWrite      Permission = "subscriptions:write"
+	PermSubscriptionsBulkDelete Permission = "subscriptions:bulk_delete"
+	PermSubscriptionsPurge      Permission = "subscriptions:purge"
+	PermReportsRead             Permission = "reports:read"
+	// PermAllUsers lifts per-user isolation: cross-user lists and sums.
+	PermAllUsers      Permission = "users:all"
+	PermAPIKeysManage Permission = "api_keys:manage"
+	PermOrgManage     Permission = "org:manage"
+	// PermWebhooksManage covers registering webhooks and inspecting their
+	// deliveries.
+	PermWebhooksManage Permission = "webhooks:manage"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleViewer: {
+		PermSubscriptionsRead,
+		PermReportsRead,
+	},
+	RoleEditor: {
+		PermSubscriptionsRead,
+		PermSubscriptionsWrite,
+		PermReportsRead,
+	},
+	RoleAdmin: {
+		PermSubscriptionsRead,
+		PermSubscriptionsWrite,
+		PermSubscriptionsBulkDelete,
+		PermSubscriptionsPurge,
+		PermReportsRead,
+		PermAllUsers,
+		PermAPIKeysManage,
+		PermOrgManage,
+		PermWebhooksManage,
+	},
+}
+
+// APIKeyScopes are the permissions that may be granted to an API key.
+var APIKeyScopes = []Permission{PermSubscriptionsRead, PermSubscriptionsWrite, PermReportsRead}
+
+func ValidScope(s Permission) bool {
+	return slices.Contains(APIKeyScopes, s)
+}
+
+// ParseRole maps a token role claim to a role. The legacy "user" role and an
+// empty claim mean editor.
+func ParseRole(s string) (Role, bool) {
+	switch Role(s) {
+	case "", "user":
+		return RoleEditor, true
+	case RoleViewer, RoleEditor, RoleAdmin:
+		return Role(s), true
+	}
+	return "", false
+}
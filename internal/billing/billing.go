@@ -0,0 +1,160 @@
+// Package billing computes what subscriptions charge over a range of
+// months. It is the reference for the SQL in the repositories'
+// SumForPeriod, which the property tests hold it to.
+package billing
+
+import (
+	"slices"
+	"strings"
+	"subcalc/internal/domain"
+	"time"
+)
+
+// ChargedMonths is the number of months sub is charged for within the
+// months from..to, both inclusive: every month of the overlap counts once.
+// Dates are expected on the 1st; for other days the count follows Postgres
+// AGE, where a month only counts once its day is reached.
+func ChargedMonths(sub *domain.Subscription, from, to time.Time) int {
+	s, e, ok := overlap(sub, from, to)
+	if !ok {
+		return 0
+	}
+	return ageMonths(e, s) + 1
+}
+
+// Total is the sum of price times charged months over subs.
+func Total(subs []*domain.Subscription, from, to time.Time) int64 {
+	var total int64
+	for _, sub := range subs {
+		total += int64(sub.Price) * int64(ChargedMonths(sub, from, to))
+	}
+	return total
+}
+
+// MonthTotal is what is charged in one calendar month.
+type MonthTotal struct {
+	Month time.Time
+	Total int64
+}
+
+// ServiceTotal is what one service charges over a range.
+type ServiceTotal struct {
+	ServiceName string
+	Total       int64
+}
+
+// Breakdown splits a total by calendar month and by service.
+type Breakdown struct {
+	From     time.Time
+	To       time.Time
+	Total    int64
+	Months   []MonthTotal
+	Services []ServiceTotal
+}
+
+// Compute breaks down what subs charge over from..to. Every month of the
+// range is listed, including months nothing is charged in; services are
+// sorted by total, largest first.
+func Compute(subs []*domain.Subscription, from, to time.Time) Breakdown {
+	from, to = Month(from), Month(to)
+	b := Breakdown{From: from, To: to}
+	if to.Before(from) {
+		return b
+	}
+
+	index := map[time.Time]int{}
+	for m := from; !m.After(to); m = m.AddDate(0, 1, 0) {
+		index[m] = len(b.Months)
+		b.Months = append(b.Months, MonthTotal{Month: m})
+	}
+
+	byService := map[string]int64{}
+	for _, sub := range subs {
+		n := ChargedMonths(sub, from, to)
+		if n == 0 {
+			continue
+		}
+		// a charge falls on the months counted from the start of the overlap
+		s, _, _ := overlap(sub, from, to)
+		for i, m := 0, Month(s); i < n; i, m = i+1, m.AddDate(0, 1, 0) {
+			b.Months[index[m]].Total += int64(sub.Price)
+		}
+		charged := int64(sub.Price) * int64(n)
+		byService[sub.ServiceName] += charged
+		b.Total += charged
+	}
+
+	for name, total := range byService {
+		b.Services = append(b.Services, ServiceTotal{ServiceName: name, Total: total})
+	}
+	slices.SortFunc(b.Services, func(x, y ServiceTotal) int {
+		if x.Total != y.Total {
+			if x.Total > y.Total {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(x.ServiceName, y.ServiceName)
+	})
+	return b
+}
+
+// Forecast breaks down the next months, starting with the month of from,
+// assuming every subscription runs until its end date and open-ended ones
+// keep running.
+func Forecast(subs []*domain.Subscription, from time.Time, months int) Breakdown {
+	from = Month(from)
+	if months < 1 {
+		return Breakdown{From: from, To: from}
+	}
+	return Compute(subs, from, from.AddDate(0, months-1, 0))
+}
+
+// Month truncates t to the first of its month, UTC.
+func Month(t time.Time) time.Time {
+	y, m, _ := t.Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// overlap returns the part of sub within the months from..to, like the SQL:
+// GREATEST(start_date, from) and LEAST(COALESCE(end_date, to), to).
+func overlap(sub *domain.Subscription, from, to time.Time) (s, e time.Time, ok bool) {
+	from, to = Month(from), Month(to)
+	start := day(sub.StartDate)
+	if start.After(to) {
+		return s, e, false
+	}
+	var end *time.Time
+	if sub.EndDate != nil {
+		d := day(*sub.EndDate)
+		if d.Before(from) {
+			return s, e, false
+		}
+		end = &d
+	}
+
+	s = start
+	if from.After(s) {
+		s = from
+	}
+	e = to
+	if end != nil && end.Before(to) {
+		e = *end
+	}
+	return s, e, !e.Before(s)
+}
+
+// ageMonths is the whole months of AGE(e, s) for e >= s.
+func ageMonths(e, s time.Time) int {
+	months := (e.Year()-s.Year())*12 + int(e.Month()) - int(s.Month())
+	if e.Day() < s.Day() {
+		months--
+	}
+	return months
+}
+
+// day drops the time of day, as a date column does.
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
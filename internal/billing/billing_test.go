@@ -0,0 +1,72 @@
+package billing
+
+import (
+	"subcalc/internal/domain"
+	"subcalc/internal/repository/repotest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestChargedMonths_SumCases(t *testing.T) {
+	for _, c := range repotest.SumCases {
+		sub := &domain.Subscription{ID: uuid.New(), Price: c.Price, StartDate: c.Start, EndDate: c.End}
+		if got := Total([]*domain.Subscription{sub}, c.From, c.To); got != c.Want {
+			t.Errorf("%s: total = %d, want %d", c.Name, got, c.Want)
+		}
+	}
+}
+
+func TestCompute_SplitsByMonthAndService(t *testing.T) {
+	end := repotest.Month(2025, 3)
+	subs := []*domain.Subscription{
+		{ServiceName: "Netflix", Price: 500, StartDate: repotest.Month(2024, 1)},
+		{ServiceName: "Spotify", Price: 300, StartDate: repotest.Month(2025, 2), EndDate: &end},
+		{ServiceName: "Netflix", Price: 100, StartDate: repotest.Month(2025, 4)},
+	}
+
+	b := Compute(subs, time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), repotest.Month(2025, 4))
+	wantMonths := []int64{500, 800, 800, 600}
+	if len(b.Months) != len(wantMonths) {
+		t.Fatalf("months = %d, want %d", len(b.Months), len(wantMonths))
+	}
+	for i, want := range wantMonths {
+		if b.Months[i].Total != want || !b.Months[i].Month.Equal(repotest.Month(2025, time.Month(i+1))) {
+			t.Errorf("month %d: %v %d, want %d", i, b.Months[i].Month, b.Months[i].Total, want)
+		}
+	}
+	if b.Total != 2700 || b.Total != Total(subs, b.From, b.To) {
+		t.Fatalf("total = %d, want 2700", b.Total)
+	}
+	if len(b.Services) != 2 || b.Services[0] != (ServiceTotal{"Netflix", 2100}) || b.Services[1] != (ServiceTotal{"Spotify", 600}) {
+		t.Fatalf("services = %+v", b.Services)
+	}
+}
+
+func TestCompute_EmptyAndReversedRanges(t *testing.T) {
+	if b := Compute(nil, repotest.Month(2025, 1), repotest.Month(2025, 3)); len(b.Months) != 3 || b.Total != 0 || b.Services != nil {
+		t.Fatalf("empty input: %+v", b)
+	}
+	if b := Compute(nil, repotest.Month(2025, 3), repotest.Month(2025, 1)); b.Months != nil {
+		t.Fatalf("reversed range must have no months: %+v", b)
+	}
+}
+
+func TestForecast(t *testing.T) {
+	end := repotest.Month(2026, 1)
+	subs := []*domain.Subscription{
+		{ServiceName: "A", Price: 10, StartDate: repotest.Month(2024, 1)},
+		{ServiceName: "B", Price: 5, StartDate: repotest.Month(2025, 1), EndDate: &end},
+	}
+	b := Forecast(subs, time.Date(2025, 11, 18, 0, 0, 0, 0, time.UTC), 4)
+	if !b.From.Equal(repotest.Month(2025, 11)) || !b.To.Equal(repotest.Month(2026, 2)) {
+		t.Fatalf("range %v..%v", b.From, b.To)
+	}
+	if b.Total != 4*10+3*5 {
+		t.Fatalf("total = %d", b.Total)
+	}
+	if b := Forecast(subs, repotest.Month(2025, 1), 0); b.Total != 0 || b.Months != nil {
+		t.Fatalf("zero months: %+v", b)
+	}
+}
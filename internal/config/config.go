@@ -3,12 +3,28 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
 
+const (
+	StorageDatabase = "database"
+	StorageMemory   = "memory"
+
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 type Config struct {
+	// DBDriver is the database of database storage: "postgres" or "sqlite".
+	DBDriver string
+	// DBPath is the SQLite database file.
+	DBPath string
+
 	DBHost     string
 	DBPort     int
 	DBUser     string
@@ -16,11 +32,70 @@ type Config struct {
 	DBName     string
 	DBSSLMode  string
 
+	// Storage selects the repositories: "database" or "memory".
+	Storage string
+	// StorageSnapshotFile is where memory storage is loaded from on start
+	// and saved to on shutdown; empty keeps it in memory only.
+	StorageSnapshotFile string
+
 	AppPort     string
 	LogLevel    string
 	AutoMigrate bool
+
+	AuthJWTSecret string
+
+	RateLimitEnabled bool
+	RateLimitRPS     float64
+	RateLimitBurst   int
+	// RateLimitSum* limit the aggregates: sum, breakdown and forecast.
+	RateLimitSumRPS   float64
+	RateLimitSumBurst int
+
+	// StrictQueryParams rejects requests with query parameters the endpoint
+	// does not know.
+	StrictQueryParams bool
+
+	MetricsEnabled bool
+
+	TracingExporter    string
+	TracingSampleRatio float64
+
+	ReadinessTimeout   time.Duration
+	ShutdownDrainDelay time.Duration
+
+	// Webhook deliveries: the outbox poll interval, per-request timeout,
+	// attempts before a delivery fails, the exponential backoff between
+	// them, and how long finished deliveries are kept.
+	WebhookPollInterval time.Duration
+	WebhookTimeout      time.Duration
+	WebhookMaxAttempts  int
+	WebhookBackoffBase  time.Duration
+	WebhookBackoffMax   time.Duration
+	WebhookRetention    time.Duration
+
+	// Event streams: how often the event log is polled for new events, and
+	// the pause after which an idle stream gets a heartbeat.
+	EventsPollInterval      time.Duration
+	EventsHeartbeatInterval time.Duration
+
+	// Reminders: how far ahead renewals and ends are announced, the time
+	// of day (UTC) of the daily run, the notifiers sent through (log,
+	// webhook, smtp; none turns the scheduler off) and the mail server of
+	// the smtp notifier.
+	ReminderWindow       time.Duration
+	ReminderRunAt        time.Duration
+	ReminderNotifiers    []string
+	ReminderSMTPHost     string
+	ReminderSMTPPort     int
+	ReminderSMTPUsername string
+	ReminderSMTPPassword string
+	ReminderSMTPFrom     string
+	ReminderSMTPTo       []string
 }
 
+// reminderNotifiers are the valid REMINDER_NOTIFIERS, besides "none".
+var reminderNotifiers = []string{"log", "webhook", "smtp"}
+
 func Load() (*Config, error) {
 	if _, err := os.Stat(".env"); err == nil {
 		_ = godotenv.Load(".env")
@@ -29,17 +104,55 @@ func Load() (*Config, error) {
 	v := viper.New()
 	v.AutomaticEnv()
 
+	v.SetDefault("DB_DRIVER", DriverPostgres)
+	v.SetDefault("DB_PATH", "subscriptions.db")
 	v.SetDefault("DB_HOST", "localhost")
 	v.SetDefault("DB_PORT", 5432)
 	v.SetDefault("DB_USER", "postgres")
 	v.SetDefault("DB_PASSWORD", "postgres")
 	v.SetDefault("DB_NAME", "subscriptions")
 	v.SetDefault("DB_SSLMODE", "disable")
+	v.SetDefault("STORAGE", StorageDatabase)
+	v.SetDefault("STORAGE_SNAPSHOT_FILE", "")
 	v.SetDefault("APP_PORT", "8080")
 	v.SetDefault("LOG_LEVEL", "info")
 	v.SetDefault("AUTO_MIGRATE", false)
+	v.SetDefault("AUTH_JWT_SECRET", "")
+	v.SetDefault("RATE_LIMIT_ENABLED", true)
+	v.SetDefault("RATE_LIMIT_RPS", 20)
+	v.SetDefault("RATE_LIMIT_BURST", 40)
+	v.SetDefault("RATE_LIMIT_SUM_RPS", 1)
+	v.SetDefault("RATE_LIMIT_SUM_BURST", 5)
+	v.SetDefault("STRICT_QUERY_PARAMS", false)
+	v.SetDefault("METRICS_ENABLED", true)
+	v.SetDefault("TRACING_EXPORTER", "none")
+	v.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	v.SetDefault("READINESS_TIMEOUT", "2s")
+	v.SetDefault("SHUTDOWN_DRAIN_DELAY", "5s")
+	v.SetDefault("WEBHOOK_POLL_INTERVAL", "1s")
+	v.SetDefault("WEBHOOK_TIMEOUT", "10s")
+	v.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	v.SetDefault("WEBHOOK_BACKOFF_BASE", "30s")
+	v.SetDefault("WEBHOOK_BACKOFF_MAX", "1h")
+	v.SetDefault("WEBHOOK_RETENTION", "168h")
+	v.SetDefault("EVENTS_POLL_INTERVAL", "1s")
+	v.SetDefault("EVENTS_HEARTBEAT_INTERVAL", "15s")
+	v.SetDefault("REMINDER_WINDOW", "72h")
+	v.SetDefault("REMINDER_RUN_AT", "09:00")
+	// webhook is on by default so that webhooks subscribed to
+	// subscription.renewing and subscription.ending_soon are sent them
+	v.SetDefault("REMINDER_NOTIFIERS", "log,webhook")
+	v.SetDefault("REMINDER_SMTP_HOST", "localhost")
+	v.SetDefault("REMINDER_SMTP_PORT", 1025)
+	v.SetDefault("REMINDER_SMTP_USERNAME", "")
+	v.SetDefault("REMINDER_SMTP_PASSWORD", "")
+	v.SetDefault("REMINDER_SMTP_FROM", "reminders@subcalc.local")
+	v.SetDefault("REMINDER_SMTP_TO", "")
 
 	cfg := &Config{
+		DBDriver: v.GetString("DB_DRIVER"),
+		DBPath:   v.GetString("DB_PATH"),
+
 		DBHost:     v.GetString("DB_HOST"),
 		DBPort:     v.GetInt("DB_PORT"),
 		DBUser:     v.GetString("DB_USER"),
@@ -47,13 +160,107 @@ func Load() (*Config, error) {
 		DBName:     v.GetString("DB_NAME"),
 		DBSSLMode:  v.GetString("DB_SSLMODE"),
 
+		Storage:             v.GetString("STORAGE"),
+		StorageSnapshotFile: v.GetString("STORAGE_SNAPSHOT_FILE"),
+
 		AppPort:     v.GetString("APP_PORT"),
 		LogLevel:    v.GetString("LOG_LEVEL"),
 		AutoMigrate: v.GetBool("AUTO_MIGRATE"),
+
+		AuthJWTSecret: v.GetString("AUTH_JWT_SECRET"),
+
+		RateLimitEnabled:  v.GetBool("RATE_LIMIT_ENABLED"),
+		RateLimitRPS:      v.GetFloat64("RATE_LIMIT_RPS"),
+		RateLimitBurst:    v.GetInt("RATE_LIMIT_BURST"),
+		RateLimitSumRPS:   v.GetFloat64("RATE_LIMIT_SUM_RPS"),
+		RateLimitSumBurst: v.GetInt("RATE_LIMIT_SUM_BURST"),
+		StrictQueryParams: v.GetBool("STRICT_QUERY_PARAMS"),
+
+		MetricsEnabled: v.GetBool("METRICS_ENABLED"),
+
+		TracingExporter:    v.GetString("TRACING_EXPORTER"),
+		TracingSampleRatio: v.GetFloat64("TRACING_SAMPLE_RATIO"),
+
+		ReadinessTimeout:   v.GetDuration("READINESS_TIMEOUT"),
+		ShutdownDrainDelay: v.GetDuration("SHUTDOWN_DRAIN_DELAY"),
+
+		WebhookPollInterval: v.GetDuration("WEBHOOK_POLL_INTERVAL"),
+		WebhookTimeout:      v.GetDuration("WEBHOOK_TIMEOUT"),
+		WebhookMaxAttempts:  v.GetInt("WEBHOOK_MAX_ATTEMPTS"),
+		WebhookBackoffBase:  v.GetDuration("WEBHOOK_BACKOFF_BASE"),
+		WebhookBackoffMax:   v.GetDuration("WEBHOOK_BACKOFF_MAX"),
+		WebhookRetention:    v.GetDuration("WEBHOOK_RETENTION"),
+
+		EventsPollInterval:      v.GetDuration("EVENTS_POLL_INTERVAL"),
+		EventsHeartbeatInterval: v.GetDuration("EVENTS_HEARTBEAT_INTERVAL"),
+
+		ReminderWindow:       v.GetDuration("REMINDER_WINDOW"),
+		ReminderNotifiers:    splitList(v.GetString("REMINDER_NOTIFIERS")),
+		ReminderSMTPHost:     v.GetString("REMINDER_SMTP_HOST"),
+		ReminderSMTPPort:     v.GetInt("REMINDER_SMTP_PORT"),
+		ReminderSMTPUsername: v.GetString("REMINDER_SMTP_USERNAME"),
+		ReminderSMTPPassword: v.GetString("REMINDER_SMTP_PASSWORD"),
+		ReminderSMTPFrom:     v.GetString("REMINDER_SMTP_FROM"),
+		ReminderSMTPTo:       splitList(v.GetString("REMINDER_SMTP_TO")),
 	}
 
-	if cfg.DBHost == "" || cfg.DBUser == "" {
-		return nil, fmt.Errorf("invalid db config")
+	switch cfg.DBDriver {
+	case DriverPostgres:
+		if cfg.DBHost == "" || cfg.DBUser == "" {
+			return nil, fmt.Errorf("invalid db config")
+		}
+	case DriverSQLite:
+		if cfg.DBPath == "" {
+			return nil, fmt.Errorf("invalid db config: DB_PATH is required for sqlite")
+		}
+	default:
+		return nil, fmt.Errorf("invalid DB_DRIVER %q, expected %s or %s", cfg.DBDriver, DriverPostgres, DriverSQLite)
+	}
+	if cfg.Storage != StorageDatabase && cfg.Storage != StorageMemory {
+		return nil, fmt.Errorf("invalid STORAGE %q, expected %s or %s", cfg.Storage, StorageDatabase, StorageMemory)
+	}
+	if cfg.RateLimitEnabled && (cfg.RateLimitRPS <= 0 || cfg.RateLimitBurst < 1 || cfg.RateLimitSumRPS <= 0 || cfg.RateLimitSumBurst < 1) {
+		return nil, fmt.Errorf("invalid rate limit config")
+	}
+	if cfg.WebhookPollInterval <= 0 || cfg.WebhookTimeout <= 0 || cfg.WebhookMaxAttempts < 1 ||
+		cfg.WebhookBackoffBase <= 0 || cfg.WebhookBackoffMax < cfg.WebhookBackoffBase || cfg.WebhookRetention <= 0 {
+		return nil, fmt.Errorf("invalid webhook config")
+	}
+	if cfg.EventsPollInterval <= 0 || cfg.EventsHeartbeatInterval <= 0 {
+		return nil, fmt.Errorf("invalid events config")
+	}
+	runAt, err := time.Parse("15:04", v.GetString("REMINDER_RUN_AT"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REMINDER_RUN_AT %q, expected HH:MM", v.GetString("REMINDER_RUN_AT"))
+	}
+	cfg.ReminderRunAt = time.Duration(runAt.Hour())*time.Hour + time.Duration(runAt.Minute())*time.Minute
+	if cfg.ReminderWindow <= 0 {
+		return nil, fmt.Errorf("invalid reminder config")
+	}
+	// an empty setting falls back to the default, so turning reminders off
+	// takes a word
+	if slices.Equal(cfg.ReminderNotifiers, []string{"none"}) {
+		cfg.ReminderNotifiers = nil
+	}
+	for _, n := range cfg.ReminderNotifiers {
+		if !slices.Contains(reminderNotifiers, n) {
+			return nil, fmt.Errorf("invalid REMINDER_NOTIFIERS entry %q, expected %s", n, strings.Join(reminderNotifiers, ", "))
+		}
+	}
+	if slices.Contains(cfg.ReminderNotifiers, "smtp") &&
+		(cfg.ReminderSMTPHost == "" || cfg.ReminderSMTPPort <= 0 || cfg.ReminderSMTPFrom == "" || len(cfg.ReminderSMTPTo) == 0) {
+		return nil, fmt.Errorf("invalid reminder smtp config: REMINDER_SMTP_HOST, _PORT, _FROM and _TO are required")
 	}
 	return cfg, nil
 }
+
+// splitList splits a comma-separated setting, dropping empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
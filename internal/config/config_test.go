@@ -0,0 +1,32 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLoad_ReminderNotifiers(t *testing.T) {
+	cases := map[string][]string{
+		"":            {"log", "webhook"},
+		"webhook":     {"webhook"},
+		"log, smtp":   {"log", "smtp"},
+		"none":        nil,
+		"log,webhook": {"log", "webhook"},
+	}
+	for setting, want := range cases {
+		t.Setenv("REMINDER_NOTIFIERS", setting)
+		t.Setenv("REMINDER_SMTP_TO", "billing@example.com")
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("REMINDER_NOTIFIERS=%q: %v", setting, err)
+		}
+		if !slices.Equal(cfg.ReminderNotifiers, want) {
+			t.Errorf("REMINDER_NOTIFIERS=%q: got %v, want %v", setting, cfg.ReminderNotifiers, want)
+		}
+	}
+
+	t.Setenv("REMINDER_NOTIFIERS", "pager")
+	if _, err := Load(); err == nil {
+		t.Fatalf("want an unknown notifier rejected")
+	}
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const redacted = "******"
+
+type Entry struct {
+	Key    string
+	Value  string
+	Secret bool
+}
+
+// Entries lists the effective settings under their environment names.
+func (c *Config) Entries() []Entry {
+	return []Entry{
+		{Key: "DB_DRIVER", Value: c.DBDriver},
+		{Key: "DB_PATH", Value: c.DBPath},
+		{Key: "DB_HOST", Value: c.DBHost},
+		{Key: "DB_PORT", Value: strconv.Itoa(c.DBPort)},
+		{Key: "DB_USER", Value: c.DBUser},
+		{Key: "DB_PASSWORD", Value: c.DBPassword, Secret: true},
+		{Key: "DB_NAME", Value: c.DBName},
+		{Key: "DB_SSLMODE", Value: c.DBSSLMode},
+		{Key: "STORAGE", Value: c.Storage},
+		{Key: "STORAGE_SNAPSHOT_FILE", Value: c.StorageSnapshotFile},
+		{Key: "APP_PORT", Value: c.AppPort},
+		{Key: "LOG_LEVEL", Value: c.LogLevel},
+		{Key: "AUTO_MIGRATE", Value: strconv.FormatBool(c.AutoMigrate)},
+		{Key: "AUTH_JWT_SECRET", Value: c.AuthJWTSecret, Secret: true},
+		{Key: "RATE_LIMIT_ENABLED", Value: strconv.FormatBool(c.RateLimitEnabled)},
+		{Key: "RATE_LIMIT_RPS", Value: formatFloat(c.RateLimitRPS)},
+		{Key: "RATE_LIMIT_BURST", Value: strconv.Itoa(c.RateLimitBurst)},
+		{Key: "RATE_LIMIT_SUM_RPS", Value: formatFloat(c.RateLimitSumRPS)},
+		{Key: "RATE_LIMIT_SUM_BURST", Value: strconv.Itoa(c.RateLimitSumBurst)},
+		{Key: "STRICT_QUERY_PARAMS", Value: strconv.FormatBool(c.StrictQueryParams)},
+		{Key: "METRICS_ENABLED", Value: strconv.FormatBool(c.MetricsEnabled)},
+		{Key: "TRACING_EXPORTER", Value: c.TracingExporter},
+		{Key: "TRACING_SAMPLE_RATIO", Value: formatFloat(c.TracingSampleRatio)},
+		{Key: "READINESS_TIMEOUT", Value: c.ReadinessTimeout.String()},
+		{Key: "SHUTDOWN_DRAIN_DELAY", Value: c.ShutdownDrainDelay.String()},
+		{Key: "WEBHOOK_POLL_INTERVAL", Value: c.WebhookPollInterval.String()},
+		{Key: "WEBHOOK_TIMEOUT", Value: c.WebhookTimeout.String()},
+		{Key: "WEBHOOK_MAX_ATTEMPTS", Value: strconv.Itoa(c.WebhookMaxAttempts)},
+		{Key: "WEBHOOK_BACKOFF_BASE", Value: c.WebhookBackoffBase.String()},
+		{Key: "WEBHOOK_BACKOFF_MAX", Value: c.WebhookBackoffMax.String()},
+		{Key: "WEBHOOK_RETENTION", Value: c.WebhookRetention.String()},
+		{Key: "EVENTS_POLL_INTERVAL", Value: c.EventsPollInterval.String()},
+		{Key: "EVENTS_HEARTBEAT_INTERVAL", Value: c.EventsHeartbeatInterval.String()},
+		{Key: "REMINDER_WINDOW", Value: c.ReminderWindow.String()},
+		{Key: "REMINDER_RUN_AT", Value: fmt.Sprintf("%02d:%02d", int(c.ReminderRunAt.Hours()), int(c.ReminderRunAt.Minutes())%60)},
+		{Key: "REMINDER_NOTIFIERS", Value: strings.Join(c.ReminderNotifiers, ",")},
+		{Key: "REMINDER_SMTP_HOST", Value: c.ReminderSMTPHost},
+		{Key: "REMINDER_SMTP_PORT", Value: strconv.Itoa(c.ReminderSMTPPort)},
+		{Key: "REMINDER_SMTP_USERNAME", Value: c.ReminderSMTPUsername},
+		{Key: "REMINDER_SMTP_PASSWORD", Value: c.ReminderSMTPPassword, Secret: true},
+		{Key: "REMINDER_SMTP_FROM", Value: c.ReminderSMTPFrom},
+		{Key: "REMINDER_SMTP_TO", Value: strings.Join(c.ReminderSMTPTo, ",")},
+	}
+}
+
+// Print writes the settings in .env format with secrets masked. An unset
+// secret prints empty, so it stays visible that none is configured.
+func (c *Config) Print(w io.Writer) error {
+	for _, e := range c.Entries() {
+		v := e.Value
+		if e.Secret && v != "" {
+			v = redacted
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", e.Key, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrint_RedactsSecrets(t *testing.T) {
+	cfg := &Config{DBHost: "db", DBPassword: "hunter2", AuthJWTSecret: "s3cret"}
+
+	var b strings.Builder
+	if err := cfg.Print(&b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := b.String()
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "s3cret") {
+		t.Fatalf("secrets leaked:\n%s", out)
+	}
+	if !strings.Contains(out, "DB_PASSWORD="+redacted+"\n") || !strings.Contains(out, "DB_HOST=db\n") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+}
+
+func TestPrint_EmptySecretStaysEmpty(t *testing.T) {
+	var b strings.Builder
+	_ = (&Config{}).Print(&b)
+	if !strings.Contains(b.String(), "AUTH_JWT_SECRET=\n") {
+		t.Fatalf("expected empty secret, got:\n%s", b.String())
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	httpdto "subcalc/internal/delivery/http"
+	"subcalc/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type APIKeyHandler struct {
+	usecase usecase.APIKeyUsecase
+	log     *zap.SugaredLogger
+}
+
+func NewAPIKeyHandler(u usecase.APIKeyUsecase, log *zap.SugaredLogger) *APIKeyHandler {
+	return &APIKeyHandler{usecase: u, log: log}
+}
+
+func (h *APIKeyHandler) RegisterRoutes(r *gin.Engine, middleware ...gin.HandlerFunc) {
+	keys := r.Group("/api/keys", middleware...)
+	keys.Use(Authorize())
+	{
+		keys.POST("", h.Create)
+		keys.GET("", h.List)
+		keys.DELETE("/:id", h.Revoke)
+	}
+}
+
+// Create godoc
+// @Summary Issue API key
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param input body httpdto.CreateAPIKeyRequest true "api key"
+// @Success 201 {object} httpdto.CreateAPIKeyResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/keys [post]
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req httpdto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warnf("invalid api key body: %v", err)
+		RespondError(c, http.StatusBadRequest, "invalid_payload", "invalid request body", map[string]string{"body": err.Error()})
+		return
+	}
+
+	key, raw, err := h.usecase.Issue(ctx, req.Name, req.Scopes)
+	if err != nil {
+		respondErr(c, h.log, "issue", err)
+		return
+	}
+	c.JSON(http.StatusCreated, httpdto.CreateAPIKeyResponse{Key: raw, APIKey: key})
+}
+
+// List godoc
+// @Summary List API keys
+// @Tags api-keys
+// @Produce json
+// @Success 200 {array} domain.APIKey
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/keys [get]
+func (h *APIKeyHandler) List(c *gin.Context) {
+	keys, err := h.usecase.List(c.Request.Context())
+	if err != nil {
+		respondErr(c, h.log, "list", err)
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// Revoke godoc
+// @Summary Revoke API key
+// @Tags api-keys
+// @Param id path string true "api key id"
+// @Success 204
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondError(c, http.StatusBadRequest, "invalid_field", "invalid id", map[string]string{"id": "invalid uuid"})
+		return
+	}
+	if err := h.usecase.Revoke(c.Request.Context(), id); err != nil {
+		respondErr(c, h.log, "revoke", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"subcalc/internal/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// cursorToken is what a page cursor carries. Clients treat the encoded
+// token as opaque.
+type cursorToken struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+	Backward  bool      `json:"b,omitempty"`
+}
+
+func encodeCursor(c *repository.Cursor) string {
+	b, _ := json.Marshal(cursorToken{CreatedAt: c.CreatedAt, ID: c.ID, Backward: c.Backward})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (*repository.Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var tok cursorToken
+	if err := json.Unmarshal(b, &tok); err != nil {
+		return nil, err
+	}
+	if tok.ID == uuid.Nil || tok.CreatedAt.IsZero() {
+		return nil, fmt.Errorf("incomplete cursor")
+	}
+	return &repository.Cursor{CreatedAt: tok.CreatedAt, ID: tok.ID, Backward: tok.Backward}, nil
+}
+
+// setPageLinks sets the RFC 8288 Link header and the X-Next-Cursor and
+// X-Prev-Cursor headers for the neighbours of the current page. A nil
+// cursor means there is no such page.
+func setPageLinks(c *gin.Context, next, prev *repository.Cursor) {
+	var links []string
+	for _, l := range []struct {
+		rel    string
+		cursor *repository.Cursor
+		header string
+	}{
+		{"next", next, "X-Next-Cursor"},
+		{"prev", prev, "X-Prev-Cursor"},
+	} {
+		if l.cursor == nil {
+			continue
+		}
+		tok := encodeCursor(l.cursor)
+		c.Header(l.header, tok)
+		links = append(links, pageLink(c, l.rel, func(q url.Values) {
+			q.Del("offset")
+			q.Set("cursor", tok)
+		}))
+	}
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+}
+
+// setOffsetLinks sets the Link header of a page in a custom order, which
+// cursors do not page through.
+func setOffsetLinks(c *gin.Context, limit, offset int, more bool) {
+	var links []string
+	if more {
+		links = append(links, pageLink(c, "next", func(q url.Values) {
+			q.Set("offset", strconv.Itoa(offset+limit))
+		}))
+	}
+	if offset > 0 {
+		links = append(links, pageLink(c, "prev", func(q url.Values) {
+			q.Set("offset", strconv.Itoa(max(offset-limit, 0)))
+		}))
+	}
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+}
+
+// pageLink is a link to the current request with the query changed by set.
+func pageLink(c *gin.Context, rel string, set func(url.Values)) string {
+	u := *c.Request.URL
+	q := u.Query()
+	set(q)
+	u.RawQuery = q.Encode()
+	return fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel)
+}
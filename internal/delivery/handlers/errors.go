@@ -1,9 +1,27 @@
 package handlers
 
 import (
+	"errors"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"subcalc/internal/domain"
+	"subcalc/internal/i18n"
+	"subcalc/internal/usecase"
+
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
+// ProblemContentType is the media type of Problem, which clients ask for in
+// Accept; everyone else gets ErrorResponse.
+const ProblemContentType = "application/problem+json"
+
+// problemTypeBase prefixes the error code to form a Problem's type URI.
+const problemTypeBase = "/problems/"
+
 // swagger:model ErrorResponse
 type ErrorResponse struct {
 	// Machine-readable error code
@@ -19,10 +37,131 @@ type ErrorResponse struct {
 	Fields map[string]string `json:"fields,omitempty"`
 }
 
+// Problem is an RFC 7807 problem details object, the error body sent to
+// clients accepting application/problem+json.
+// swagger:model Problem
+type Problem struct {
+	// URI reference identifying the kind of problem
+	// example: /problems/invalid_field
+	Type string `json:"type" example:"/problems/invalid_field"`
+
+	// Short summary of the kind of problem
+	// example: Bad Request
+	Title string `json:"title" example:"Bad Request"`
+
+	// example: 400
+	Status int `json:"status" example:"400"`
+
+	// Explanation of this occurrence
+	// example: end_date must be equal or after start_date
+	Detail string `json:"detail,omitempty" example:"end_date must be equal or after start_date"`
+
+	// ID of the request, as in the X-Request-Id header
+	// example: 6f1c1e52-8a53-4f4e-9f43-6a4b1c1f1e55
+	Instance string `json:"instance,omitempty" example:"6f1c1e52-8a53-4f4e-9f43-6a4b1c1f1e55"`
+
+	// Machine-readable error code, the last segment of type
+	// example: invalid_field
+	Code string `json:"code" example:"invalid_field"`
+
+	// Per-parameter validation errors
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+}
+
+// swagger:model InvalidParam
+type InvalidParam struct {
+	// example: end_date
+	Name string `json:"name" example:"end_date"`
+	// example: must be >= start_date
+	Reason string `json:"reason" example:"must be >= start_date"`
+}
+
+// RespondError writes an error as a Problem or, unless the client accepts
+// problem details, as an ErrorResponse. Messages are translated into the
+// language of Accept-Language.
 func RespondError(c *gin.Context, httpStatus int, code, message string, fields map[string]string) {
-	c.JSON(httpStatus, ErrorResponse{
-		Code:    code,
-		Message: message,
-		Fields:  fields,
-	})
+	lang := i18n.Negotiate(c.GetHeader("Accept-Language"))
+	message = i18n.Message(lang, code, message)
+	if len(fields) > 0 {
+		translated := make(map[string]string, len(fields))
+		for name, reason := range fields {
+			translated[name] = i18n.Reason(lang, reason)
+		}
+		fields = translated
+	}
+	c.Header("Content-Language", string(lang))
+	c.Writer.Header().Add("Vary", "Accept, Accept-Language")
+
+	if !acceptsProblem(c.GetHeader("Accept")) {
+		c.JSON(httpStatus, ErrorResponse{
+			Code:    code,
+			Message: message,
+			Fields:  fields,
+		})
+		return
+	}
+
+	p := Problem{
+		Type:     problemTypeBase + code,
+		Title:    i18n.Title(lang, code, http.StatusText(httpStatus)),
+		Status:   httpStatus,
+		Detail:   message,
+		Instance: c.GetHeader("X-Request-Id"),
+		Code:     code,
+	}
+	for name, reason := range fields {
+		p.InvalidParams = append(p.InvalidParams, InvalidParam{Name: name, Reason: reason})
+	}
+	sort.Slice(p.InvalidParams, func(i, j int) bool { return p.InvalidParams[i].Name < p.InvalidParams[j].Name })
+	// c.JSON keeps a Content-Type that is already set
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(httpStatus, p)
+}
+
+// respondErr responds with what an error of the usecases or repositories
+// means to the client. Errors of no known kind are logged with op, the
+// operation that failed, and hidden behind a 500.
+func respondErr(c *gin.Context, log *zap.SugaredLogger, op string, err error) {
+	var verr *domain.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		RespondError(c, http.StatusBadRequest, "invalid_field", verr.Message, verr.Fields)
+	case errors.Is(err, domain.ErrValidation):
+		RespondError(c, http.StatusBadRequest, "invalid_field", err.Error(), nil)
+	case errors.Is(err, domain.ErrNotFound):
+		RespondError(c, http.StatusNotFound, "not_found", "not found", nil)
+	case errors.Is(err, domain.ErrConflict):
+		RespondError(c, http.StatusConflict, "conflict", "conflicts with an existing resource", nil)
+	case errors.Is(err, usecase.ErrForbidden):
+		RespondError(c, http.StatusForbidden, "forbidden", detail(err, usecase.ErrForbidden, "insufficient permissions"), nil)
+	default:
+		log.Errorf("%s failed: %v", op, err)
+		RespondError(c, http.StatusInternalServerError, "internal_error", op+" failed", nil)
+	}
+}
+
+// detail is what err adds to the sentinel it wraps with "%w: ...", or def
+// when it adds nothing.
+func detail(err, sentinel error, def string) string {
+	if s, ok := strings.CutPrefix(err.Error(), sentinel.Error()+": "); ok {
+		return s
+	}
+	return def
+}
+
+// acceptsProblem reports whether an Accept header lists problem details
+// with a non-zero quality. Wildcards do not count, so that clients which
+// accept anything keep receiving ErrorResponse.
+func acceptsProblem(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || mt != ProblemContentType {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			continue
+		}
+		return true
+	}
+	return false
 }
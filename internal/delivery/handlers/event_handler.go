@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"subcalc/internal/usecase"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const lastEventIDHeader = "Last-Event-ID"
+
+type EventHandler struct {
+	usecase usecase.EventUsecase
+	// heartbeat is the pause after which an idle stream gets a comment,
+	// so proxies and clients can tell it from a dead one.
+	heartbeat time.Duration
+	log       *zap.SugaredLogger
+}
+
+func NewEventHandler(u usecase.EventUsecase, heartbeat time.Duration, log *zap.SugaredLogger) *EventHandler {
+	return &EventHandler{usecase: u, heartbeat: heartbeat, log: log}
+}
+
+func (h *EventHandler) RegisterRoutes(r *gin.Engine, middleware ...gin.HandlerFunc) {
+	events := r.Group("/api/subscriptions/events", middleware...)
+	events.Use(Authorize())
+	{
+		events.GET("", h.Stream)
+	}
+}
+
+// Stream godoc
+// @Summary Stream subscription events
+// @Description Server-sent events: subscription.created, subscription.updated and subscription.deleted as they happen, each with its outbox id as the event id and the event as JSON data. Idle streams get a heartbeat comment. A client reconnecting with Last-Event-ID first receives the events it missed, as far back as the event log is retained.
+// @Tags subscriptions
+// @Produce text/event-stream
+// @Param user_id query []string false "only events of these users (repeated or comma-separated)"
+// @Param Last-Event-ID header int false "resume after this event"
+// @Success 200 {object} domain.Event
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/subscriptions/events [get]
+func (h *EventHandler) Stream(c *gin.Context) {
+	q := bindQuery(c)
+	userIDs := q.UUIDs("user_id")
+	if !q.Valid() {
+		return
+	}
+	var afterID int64
+	if v := c.GetHeader(lastEventIDHeader); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id < 0 {
+			RespondError(c, http.StatusBadRequest, "invalid_field", "invalid Last-Event-ID",
+				map[string]string{lastEventIDHeader: "expected an integer >= 0"})
+			return
+		}
+		afterID = id
+	}
+
+	events, err := h.usecase.Stream(c.Request.Context(), userIDs, afterID)
+	if err != nil {
+		respondErr(c, h.log, "stream events", err)
+		return
+	}
+
+	c.Header("Content-Type", sse.ContentType)
+	c.Header("Cache-Control", "no-cache")
+	// nginx would otherwise hold events back in its buffer
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			err = sse.Encode(c.Writer, sse.Event{Id: strconv.FormatInt(e.ID, 10), Event: string(e.Type), Data: e})
+			heartbeat.Reset(h.heartbeat)
+		case <-heartbeat.C:
+			_, err = io.WriteString(c.Writer, ": heartbeat\n\n")
+		}
+		if err != nil {
+			// the client is gone; its context ends the stream
+			return
+		}
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	httpdto "subcalc/internal/delivery/http"
+	"subcalc/internal/domain"
+	"subcalc/internal/events"
+	memrepo "subcalc/internal/repository/memory"
+	"subcalc/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestEventStream_ResumesAndHeartbeats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := zap.NewNop().Sugar()
+	store := memrepo.NewStore()
+	broker := events.NewBroker(memrepo.NewMemoryEventRepo(store), events.Options{Interval: 5 * time.Millisecond, Buffer: 10}, log)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go broker.Run(ctx)
+
+	r := gin.New()
+	NewHandler(usecase.NewSubscriptionUsecase(memrepo.NewMemorySubscriptionRepo(store)), log).RegisterRoutes(r)
+	NewEventHandler(usecase.NewEventUsecase(broker), 20*time.Millisecond, log).RegisterRoutes(r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	create := func(user string) {
+		body, _ := json.Marshal(httpdto.CreateSubscriptionRequest{ServiceName: "Netflix", Price: 499, UserID: user, StartDate: "07-2025"})
+		resp, err := http.Post(srv.URL+"/api/subscriptions", "application/json", bytes.NewReader(body))
+		if err != nil || resp.StatusCode != http.StatusCreated {
+			t.Fatalf("create: %v %v", resp, err)
+		}
+		resp.Body.Close()
+	}
+	alice, bob := uuid.NewString(), uuid.NewString()
+	create(alice)
+	create(bob)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/subscriptions/events?user_id="+bob, nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("stream: got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	lines := make(chan string)
+	go func() {
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+		close(lines)
+	}()
+	next := func() string {
+		select {
+		case l := <-lines:
+			return l
+		case <-time.After(2 * time.Second):
+			t.Fatalf("stream stalled")
+		}
+		return ""
+	}
+	readEvent := func() (string, *domain.Event) {
+		var id string
+		for {
+			l := next()
+			switch {
+			case strings.HasPrefix(l, "id:"):
+				id = l[len("id:"):]
+			case strings.HasPrefix(l, "data:"):
+				var e domain.Event
+				if err := json.Unmarshal([]byte(l[len("data:"):]), &e); err != nil {
+					t.Fatalf("data %q: %v", l, err)
+				}
+				return id, &e
+			}
+		}
+	}
+
+	if id, e := readEvent(); id != "2" || e.Type != domain.EventSubscriptionCreated || e.Data.Subscription.UserID.String() != bob {
+		t.Fatalf("want bob's event 2 replayed, got %s %+v", id, e)
+	}
+	create(alice)
+	create(bob)
+	if id, e := readEvent(); id != "4" || e.Data.Subscription.UserID.String() != bob {
+		t.Fatalf("want bob's live event 4, got %s %+v", id, e)
+	}
+	for l := next(); l != ": heartbeat"; l = next() {
+	}
+}
+
+func TestEventStream_RejectsInvalidLastEventID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	NewEventHandler(usecase.NewEventUsecase(nil), time.Second, zap.NewNop().Sugar()).RegisterRoutes(r)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/subscriptions/events", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "Last-Event-ID") {
+		t.Fatalf("expected 400 on Last-Event-ID, got %d %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"subcalc/internal/repository"
+)
+
+// parseFilter binds the conditions the list and report endpoints share:
+// everything but the period, paging and order.
+func parseFilter(q *query) repository.SubscriptionFilter {
+	var filter repository.SubscriptionFilter
+
+	switch uids := q.UUIDs("user_id"); len(uids) {
+	case 0:
+	case 1:
+		filter.UserID = &uids[0]
+	default:
+		filter.UserIDs = uids
+	}
+
+	filter.ServiceName = q.String("service_name")
+	filter.ServiceNamePrefix = q.String("service_name_prefix")
+	if v := q.Bool("ignore_case"); v != nil {
+		filter.IgnoreCase = *v
+	}
+
+	filter.PriceMin = q.IntRange("price_min", 0, math.MaxInt)
+	filter.PriceMax = q.IntRange("price_max", 0, math.MaxInt)
+	if filter.PriceMin != nil && filter.PriceMax != nil && *filter.PriceMin > *filter.PriceMax {
+		q.fail("price_min", "must be <= price_max")
+	}
+
+	filter.ActiveOn = q.Month("active_on")
+	filter.OpenEnded = q.Bool("open_ended")
+	filter.CreatedAfter = q.Time("created_after")
+	filter.UpdatedAfter = q.Time("updated_after")
+	return filter
+}
+
+// parsePeriod binds the required from/to period of the report endpoints
+// into filter.
+func parsePeriod(q *query, filter *repository.SubscriptionFilter) {
+	for _, name := range []string{"from", "to"} {
+		if q.value(name) == "" {
+			q.fail(name, "required")
+		}
+	}
+	filter.From, filter.To = q.Month("from"), q.Month("to")
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		q.fail("from", "must be <= to")
+	}
+}
+
+// parseSort reads an order like "price,-start_date": keys in priority
+// order, descending when prefixed with a minus.
+func parseSort(s string) ([]repository.Sort, error) {
+	var out []repository.Sort
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		var o repository.Sort
+		if rest, ok := strings.CutPrefix(part, "-"); ok {
+			o.Desc = true
+			part = rest
+		}
+		o.Key = repository.SortKey(part)
+		if !o.Key.Valid() {
+			return nil, fmt.Errorf("unknown sort key %q", part)
+		}
+		out = append(out, o)
+	}
+	return out, nil
+}
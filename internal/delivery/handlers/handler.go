@@ -1,20 +1,30 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"mime"
 	"net/http"
 	"strconv"
 	"strings"
 	httpdto "subcalc/internal/delivery/http"
 	"subcalc/internal/domain"
 	"subcalc/internal/repository"
+	"subcalc/internal/tenant"
 	"subcalc/internal/usecase"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+// defaultCurrency is reported when no organization was resolved.
+const defaultCurrency = "RUB"
+
 type Handler struct {
 	usecase usecase.SubscriptionUsecase
 	log     *zap.SugaredLogger
@@ -24,16 +34,23 @@ func NewHandler(u usecase.SubscriptionUsecase, log *zap.SugaredLogger) *Handler
 	return &Handler{usecase: u, log: log}
 }
 
-func (h *Handler) RegisterRoutes(r *gin.Engine) {
-	api := r.Group("/api")
+func (h *Handler) RegisterRoutes(r *gin.Engine, middleware ...gin.HandlerFunc) {
+	api := r.Group("/api", middleware...)
+	api.Use(Authorize())
 	{
 		s := api.Group("/subscriptions")
 		{
 			s.POST("", h.Create)
 			s.GET("", h.List)
 			s.GET("/sum", h.Sum)
+			s.GET("/search", h.Search)
+			s.GET("/breakdown", h.Breakdown)
+			s.GET("/forecast", h.Forecast)
+			s.POST("/bulk-delete", h.BulkDelete)
+			s.POST("/purge", h.Purge)
 			s.GET("/:id", h.GetByID)
 			s.PUT("/:id", h.Update)
+			s.PATCH("/:id", h.Patch)
 			s.DELETE("/:id", h.Delete)
 		}
 	}
@@ -47,6 +64,7 @@ func (h *Handler) RegisterRoutes(r *gin.Engine) {
 // @Param input body httpdto.CreateSubscriptionRequest true "subscription"
 // @Success 201 {object} domain.Subscription
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/subscriptions [post]
 func (h *Handler) Create(c *gin.Context) {
@@ -58,46 +76,12 @@ func (h *Handler) Create(c *gin.Context) {
 		RespondError(c, http.StatusBadRequest, "invalid_payload", "invalid request body", map[string]string{"body": err.Error()})
 		return
 	}
-	uid, err := uuid.Parse(req.UserID)
-	if err != nil {
-		RespondError(c, http.StatusBadRequest, "invalid_field", "user_id must be a UUID", map[string]string{"user_id": "invalid uuid"})
-		return
-	}
-	start, err := parseMonthYear(req.StartDate)
-	if err != nil {
-		RespondError(c, http.StatusBadRequest, "invalid_field", "start_date must be in format MM-YYYY", map[string]string{"start_date": "expected MM-YYYY"})
-		return
-	}
-	var endPtr *time.Time
-	if req.EndDate != nil {
-		t, err := parseMonthYear(*req.EndDate)
-		if err != nil {
-			RespondError(c, http.StatusBadRequest, "invalid_field", "end_date must be in format MM-YYYY", map[string]string{"end_date": "expected MM-YYYY"})
-			return
-		}
-		if t.Before(start) {
-			RespondError(c, http.StatusBadRequest, "invalid_field", "end_date must be equal or after start_date", map[string]string{"end_date": "must be >= start_date"})
-			return
-		}
-		endPtr = &t
-	}
-
-	req.ServiceName = strings.TrimSpace(req.ServiceName)
-	if req.ServiceName == "" || len(req.ServiceName) > 255 {
-		RespondError(c, http.StatusBadRequest, "invalid_field", "service_name is required and must be <=255 chars", map[string]string{"service_name": "required, max 255 chars"})
+	sub, ok := newSubscription(c, req.ServiceName, req.Price, req.UserID, req.StartDate, req.EndDate)
+	if !ok {
 		return
 	}
-
-	sub := &domain.Subscription{
-		ServiceName: req.ServiceName,
-		Price:       req.Price,
-		UserID:      uid,
-		StartDate:   start,
-		EndDate:     endPtr,
-	}
 	if err := h.usecase.Create(ctx, sub); err != nil {
-		h.log.Errorf("create failed: %v", err)
-		RespondError(c, http.StatusInternalServerError, "internal_error", "create failed", nil)
+		respondErr(c, h.log, "create", err)
 		return
 	}
 	c.JSON(http.StatusCreated, sub)
@@ -107,88 +91,113 @@ func (h *Handler) Create(c *gin.Context) {
 // @Summary List subscriptions
 // @Tags subscriptions
 // @Produce json
-// @Param user_id query string false "user uuid"
+// @Param user_id query []string false "user uuids, repeated or comma-separated" collectionFormat(csv)
 // @Param service_name query string false "service name"
-// @Param limit query int false "limit"
-// @Param offset query int false "offset"
+// @Param service_name_prefix query string false "service name prefix"
+// @Param ignore_case query bool false "match service_name and service_name_prefix ignoring case"
+// @Param price_min query int false "minimum price, inclusive"
+// @Param price_max query int false "maximum price, inclusive"
+// @Param from query string false "overlapping period start MM-YYYY"
+// @Param to query string false "overlapping period end MM-YYYY"
+// @Param active_on query string false "running in month MM-YYYY"
+// @Param open_ended query bool false "without (true) or with (false) an end date"
+// @Param created_after query string false "RFC 3339 timestamp"
+// @Param updated_after query string false "RFC 3339 timestamp"
+// @Param sort query string false "keys like price,-start_date; cannot be combined with cursor"
+// @Param limit query int false "page size, 1..1000 (default 100)"
+// @Param offset query int false "offset; cannot be combined with cursor"
+// @Param cursor query string false "page cursor from X-Next-Cursor, X-Prev-Cursor or a Link header"
 // @Success 200 {array} domain.Subscription
 // @Header 200 {string} X-Total-Count "Total number of subscriptions matching the filter"
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page, if there is one"
+// @Header 200 {string} X-Prev-Cursor "Cursor of the previous page, if there is one"
+// @Header 200 {string} Link "RFC 8288 links to the next and previous pages"
+// @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/subscriptions [get]
 func (h *Handler) List(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	var filter repository.SubscriptionFilter
-	if uidStr := c.Query("user_id"); uidStr != "" {
-		uid, err := uuid.Parse(uidStr)
-		if err == nil {
-			filter.UserID = &uid
-		}
-	}
-	if s := c.Query("service_name"); s != "" {
-		filter.ServiceName = &s
-	}
-
 	const (
 		defaultLimit = 100
 		maxLimit     = 1000
 	)
-	limit := defaultLimit
-	if l := c.Query("limit"); l != "" {
-		if v, err := strconv.Atoi(l); err == nil {
-			if v > 0 {
-				limit = v
-			}
-		}
-	}
-	if limit > maxLimit {
-		limit = maxLimit
-	}
-	offset := 0
-	if o := c.Query("offset"); o != "" {
-		if v, err := strconv.Atoi(o); err == nil && v >= 0 {
-			offset = v
-		}
-	}
-	filter.Limit = limit
+	q := bindQuery(c)
+	filter := parseFilter(q)
+	filter.From, filter.To = q.Month("from"), q.Month("to")
+	limit := q.Int("limit", defaultLimit, 1, maxLimit)
+	offset := q.Int("offset", 0, 0, math.MaxInt)
 	filter.Offset = offset
 
-	if fromStr := c.Query("from"); fromStr != "" {
-		if t, err := parseMonthYear(fromStr); err == nil {
-			filter.From = &t
-		} else {
-			RespondError(c, http.StatusBadRequest, "invalid_field", "from must be MM-YYYY", map[string]string{"from": "expected MM-YYYY"})
-			return
+	cs, ss := q.value("cursor"), q.value("sort")
+	if cs != "" {
+		cursor, err := decodeCursor(cs)
+		if err != nil {
+			q.fail("cursor", "invalid")
+		}
+		filter.Cursor = cursor
+		if q.value("offset") != "" {
+			q.fail("offset", "not allowed with cursor")
+		}
+		if ss != "" {
+			q.fail("sort", "not allowed with cursor, which pages in the default order")
 		}
 	}
-	if toStr := c.Query("to"); toStr != "" {
-		if t, err := parseMonthYear(toStr); err == nil {
-			filter.To = &t
-		} else {
-			RespondError(c, http.StatusBadRequest, "invalid_field", "to must be MM-YYYY", map[string]string{"to": "expected MM-YYYY"})
-			return
+	if ss != "" {
+		sort, err := parseSort(ss)
+		if err != nil {
+			q.fail("sort", err.Error())
 		}
+		filter.Sort = sort
+	}
+	if !q.Valid() {
+		return
 	}
+	// one row more than asked for tells whether there is a further page
+	filter.Limit = limit + 1
 
 	total, err := h.usecase.Count(ctx, filter)
 	if err != nil {
-		h.log.Errorf("count failed: %v", err)
-		RespondError(c, http.StatusInternalServerError, "internal_error", "list failed", nil)
+		respondErr(c, h.log, "list", err)
 		return
 	}
 	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
 
 	subs, err := h.usecase.List(ctx, filter)
 	if err != nil {
-		h.log.Errorf("list failed: %v", err)
-		RespondError(c, http.StatusInternalServerError, "internal_error", "list failed", nil)
+		respondErr(c, h.log, "list", err)
 		return
 	}
+	backward := filter.Cursor != nil && filter.Cursor.Backward
+	more := len(subs) > limit
+	if more {
+		if backward {
+			subs = subs[len(subs)-limit:]
+		} else {
+			subs = subs[:limit]
+		}
+	}
+	if len(filter.Sort) > 0 {
+		setOffsetLinks(c, limit, offset, more)
+		c.JSON(http.StatusOK, subs)
+		return
+	}
+	var next, prev *repository.Cursor
+	if len(subs) > 0 {
+		if more || backward {
+			next = repository.CursorAfter(subs[len(subs)-1])
+		}
+		if (more && backward) || (!backward && (filter.Cursor != nil || offset > 0)) {
+			prev = repository.CursorBefore(subs[0])
+		}
+	}
+	setPageLinks(c, next, prev)
 	c.JSON(http.StatusOK, subs)
 }
 
 // Sum godoc
 // @Summary Sum subscriptions for period
+// @Description Also takes the filters of the list endpoint, except paging and sort.
 // @Tags subscriptions
 // @Produce json
 // @Param from query string true "start month-year MM-YYYY"
@@ -202,47 +211,132 @@ func (h *Handler) List(c *gin.Context) {
 func (h *Handler) Sum(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	fromStr := c.Query("from")
-	toStr := c.Query("to")
-	if fromStr == "" || toStr == "" {
-		RespondError(c, http.StatusBadRequest, "invalid_request", "from and to query params required, format MM-YYYY", map[string]string{"from": "required", "to": "required"})
+	q := bindQuery(c)
+	filter := parseFilter(q)
+	parsePeriod(q, &filter)
+	if !q.Valid() {
 		return
 	}
-	from, err := parseMonthYear(fromStr)
+
+	total, err := h.usecase.SumSubscriptions(ctx, filter)
 	if err != nil {
-		RespondError(c, http.StatusBadRequest, "invalid_field", "invalid from format, expected MM-YYYY", map[string]string{"from": "expected MM-YYYY"})
+		respondErr(c, h.log, "sum", err)
+		return
+	}
+	c.JSON(http.StatusOK, httpdto.TotalResponse{Total: total, Currency: currency(c)})
+}
+
+// Search godoc
+// @Summary Search subscriptions by service name
+// @Description Fuzzy and case-insensitive, so "yandx" finds "Yandex Plus". Results are best first. Also takes the filters of the list endpoint, except paging and sort.
+// @Tags subscriptions
+// @Produce json
+// @Param q query string true "search text"
+// @Param limit query int false "number of results, 1..100 (default 20)"
+// @Success 200 {array} httpdto.SearchResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/subscriptions/search [get]
+func (h *Handler) Search(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	const (
+		defaultLimit = 20
+		maxLimit     = 100
+		maxQueryLen  = 100
+	)
+	q := bindQuery(c)
+	text := q.Required("q")
+	if utf8.RuneCountInString(text) > maxQueryLen {
+		q.fail("q", "at most 100 characters")
+	}
+	filter := parseFilter(q)
+	filter.Limit = q.Int("limit", defaultLimit, 1, maxLimit)
+	if !q.Valid() {
 		return
 	}
-	to, err := parseMonthYear(toStr)
+
+	hits, err := h.usecase.Search(ctx, text, filter)
 	if err != nil {
-		RespondError(c, http.StatusBadRequest, "invalid_field", "invalid to format, expected MM-YYYY", map[string]string{"to": "expected MM-YYYY"})
+		respondErr(c, h.log, "search", err)
 		return
 	}
-	if from.After(to) {
-		RespondError(c, http.StatusBadRequest, "invalid_request", "'from' must be before or equal to 'to'", map[string]string{"from": "must be <= to"})
+	c.JSON(http.StatusOK, httpdto.NewSearchResults(hits, text))
+}
+
+// Breakdown godoc
+// @Summary Sum subscriptions for period, split by month and service
+// @Description Also takes the filters of the list endpoint, except paging and sort.
+// @Tags subscriptions
+// @Produce json
+// @Param from query string true "start month-year MM-YYYY"
+// @Param to query string true "end month-year MM-YYYY"
+// @Param user_id query string false "user uuid"
+// @Param service_name query string false "service name"
+// @Success 200 {object} httpdto.BreakdownResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/subscriptions/breakdown [get]
+func (h *Handler) Breakdown(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	q := bindQuery(c)
+	filter := parseFilter(q)
+	parsePeriod(q, &filter)
+	if !q.Valid() {
 		return
 	}
 
-	var filter repository.SubscriptionFilter
-	filter.From = &from
-	filter.To = &to
-	if uidStr := c.Query("user_id"); uidStr != "" {
-		uid, err := uuid.Parse(uidStr)
-		if err == nil {
-			filter.UserID = &uid
-		}
+	b, err := h.usecase.Breakdown(ctx, filter)
+	if err != nil {
+		respondErr(c, h.log, "breakdown", err)
+		return
 	}
-	if s := c.Query("service_name"); s != "" {
-		filter.ServiceName = &s
+	c.JSON(http.StatusOK, httpdto.NewBreakdownResponse(b, currency(c)))
+}
+
+// Forecast godoc
+// @Summary Project charges of the coming months
+// @Description Open-ended subscriptions are assumed to keep running. Also takes the filters of the list endpoint, except paging and sort.
+// @Tags subscriptions
+// @Produce json
+// @Param months query int false "number of months, 1..60 (default 12)"
+// @Param from query string false "first month MM-YYYY (default current month)"
+// @Param user_id query string false "user uuid"
+// @Param service_name query string false "service name"
+// @Success 200 {object} httpdto.BreakdownResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/subscriptions/forecast [get]
+func (h *Handler) Forecast(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	const (
+		defaultMonths = 12
+		maxMonths     = 60
+	)
+	q := bindQuery(c)
+	filter := parseFilter(q)
+	filter.From = q.Month("from")
+	months := q.Int("months", defaultMonths, 1, maxMonths)
+	if !q.Valid() {
+		return
 	}
 
-	total, err := h.usecase.SumSubscriptions(ctx, filter)
+	b, err := h.usecase.Forecast(ctx, filter, months)
 	if err != nil {
-		h.log.Errorf("sum failed: %v", err)
-		RespondError(c, http.StatusInternalServerError, "internal_error", "sum failed", nil)
+		respondErr(c, h.log, "forecast", err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"total": total})
+	c.JSON(http.StatusOK, httpdto.NewBreakdownResponse(b, currency(c)))
+}
+
+// currency is the currency totals are reported in: the organization's.
+func currency(c *gin.Context) string {
+	if org, ok := tenant.Organization(c.Request.Context()); ok {
+		return org.DefaultCurrency
+	}
+	return defaultCurrency
 }
 
 // GetByID godoc
@@ -265,106 +359,177 @@ func (h *Handler) GetByID(c *gin.Context) {
 	}
 	sub, err := h.usecase.GetByID(ctx, id)
 	if err != nil {
-		h.log.Errorf("get failed: %v", err)
-		RespondError(c, http.StatusInternalServerError, "internal_error", "get failed", nil)
-		return
-	}
-	if sub == nil {
-		RespondError(c, http.StatusNotFound, "not_found", "not found", nil)
+		respondErr(c, h.log, "get", err)
 		return
 	}
 	c.JSON(http.StatusOK, sub)
 }
 
 // Update godoc
-// @Summary Update subscription
+// @Summary Replace subscription
+// @Description Replaces every field; an end_date left out makes the subscription open-ended. Use PATCH to change single fields.
 // @Tags subscriptions
 // @Accept json
 // @Produce json
 // @Param id path string true "subscription id"
-// @Param input body httpdto.UpdateSubscriptionRequest true "update fields"
+// @Param input body httpdto.ReplaceSubscriptionRequest true "subscription"
 // @Success 200 {object} domain.Subscription
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/subscriptions/{id} [put]
 func (h *Handler) Update(c *gin.Context) {
-	ctx := c.Request.Context()
+	existing, ok := h.existing(c)
+	if !ok {
+		return
+	}
+	var req httpdto.ReplaceSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warnf("invalid update body: %v", err)
+		RespondError(c, http.StatusBadRequest, "invalid_payload", "invalid request body", map[string]string{"body": err.Error()})
+		return
+	}
+	h.replace(c, existing, req)
+}
 
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+// Patch godoc
+// @Summary Patch subscription
+// @Description Takes a JSON merge patch (RFC 7396), where null clears end_date, or a JSON patch (RFC 6902), applied to the subscription as GET returns it. id, org_id, created_at and updated_at are read-only.
+// @Tags subscriptions
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path string true "subscription id"
+// @Param input body httpdto.UpdateSubscriptionRequest true "merge patch or JSON patch operations"
+// @Success 200 {object} domain.Subscription
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "a test operation failed"
+// @Failure 415 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/subscriptions/{id} [patch]
+func (h *Handler) Patch(c *gin.Context) {
+	mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+	if mediaType != mergePatchType && mediaType != jsonPatchType {
+		c.Header("Accept-Patch", mergePatchType+", "+jsonPatchType)
+		RespondError(c, http.StatusUnsupportedMediaType, "unsupported_media_type", "expected a merge patch or a JSON patch", nil)
+		return
+	}
+	existing, ok := h.existing(c)
+	if !ok {
+		return
+	}
+	patch, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		RespondError(c, http.StatusBadRequest, "invalid_field", "invalid id", map[string]string{"id": "invalid uuid"})
+		RespondError(c, http.StatusBadRequest, "invalid_payload", "invalid request body", map[string]string{"body": err.Error()})
 		return
 	}
-	existing, err := h.usecase.GetByID(ctx, id)
+
+	doc, err := json.Marshal(existing)
 	if err != nil {
-		h.log.Errorf("get for update failed: %v", err)
-		RespondError(c, http.StatusInternalServerError, "internal_error", "get failed", nil)
+		respondErr(c, h.log, "patch", err)
+		return
+	}
+	if mediaType == mergePatchType {
+		doc, err = applyMergePatch(doc, patch)
+	} else {
+		doc, err = applyJSONPatch(doc, patch)
+	}
+	if errors.Is(err, errPatchTest) {
+		RespondError(c, http.StatusConflict, "conflict", "test operation failed", map[string]string{"body": err.Error()})
 		return
 	}
-	if existing == nil {
-		RespondError(c, http.StatusNotFound, "not_found", "not found", nil)
+	if err != nil {
+		RespondError(c, http.StatusBadRequest, "invalid_payload", "invalid patch", map[string]string{"body": err.Error()})
 		return
 	}
 
-	var req httpdto.UpdateSubscriptionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.log.Warnf("invalid update body: %v", err)
+	req, fields, err := decodePatched(doc, existing)
+	if err != nil {
 		RespondError(c, http.StatusBadRequest, "invalid_payload", "invalid request body", map[string]string{"body": err.Error()})
 		return
 	}
+	if len(fields) > 0 {
+		RespondError(c, http.StatusBadRequest, "invalid_field", "read-only fields cannot be changed", fields)
+		return
+	}
+	h.replace(c, existing, *req)
+}
 
-	if req.ServiceName != nil {
-		s := strings.TrimSpace(*req.ServiceName)
-		if s == "" || len(s) > 255 {
-			RespondError(c, http.StatusBadRequest, "invalid_field", "service_name must be non-empty and <=255 chars", map[string]string{"service_name": "required, max 255 chars"})
-			return
-		}
-		existing.ServiceName = s
+// existing loads the subscription named by the id parameter, responding
+// when there is none.
+func (h *Handler) existing(c *gin.Context) (*domain.Subscription, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondError(c, http.StatusBadRequest, "invalid_field", "invalid id", map[string]string{"id": "invalid uuid"})
+		return nil, false
 	}
-	if req.Price != nil {
-		if *req.Price < 0 {
-			RespondError(c, http.StatusBadRequest, "invalid_field", "price must be >= 0", map[string]string{"price": "must be >= 0"})
-			return
-		}
-		existing.Price = *req.Price
+	sub, err := h.usecase.GetByID(c.Request.Context(), id)
+	if err != nil {
+		respondErr(c, h.log, "get", err)
+		return nil, false
+	}
+	return sub, true
+}
+
+// replace stores req as the new state of existing and responds with it.
+func (h *Handler) replace(c *gin.Context, existing *domain.Subscription, req httpdto.ReplaceSubscriptionRequest) {
+	sub, ok := newSubscription(c, req.ServiceName, *req.Price, req.UserID, req.StartDate, req.EndDate)
+	if !ok {
+		return
+	}
+	sub.ID, sub.OrgID, sub.CreatedAt = existing.ID, existing.OrgID, existing.CreatedAt
+	if err := h.usecase.Update(c.Request.Context(), sub); err != nil {
+		respondErr(c, h.log, "update", err)
+		return
 	}
-	if req.StartDate != nil {
-		t, err := parseMonthYear(*req.StartDate)
+	c.JSON(http.StatusOK, sub)
+}
+
+// newSubscription checks a full representation of a subscription, as sent
+// to Create and Update, responding with the first problem found.
+func newSubscription(c *gin.Context, serviceName string, price int, userID, startDate string, endDate *string) (*domain.Subscription, bool) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		RespondError(c, http.StatusBadRequest, "invalid_field", "user_id must be a UUID", map[string]string{"user_id": "invalid uuid"})
+		return nil, false
+	}
+	start, err := parseMonthYear(startDate)
+	if err != nil {
+		RespondError(c, http.StatusBadRequest, "invalid_field", "start_date must be in format MM-YYYY", map[string]string{"start_date": "expected MM-YYYY"})
+		return nil, false
+	}
+	var endPtr *time.Time
+	if endDate != nil {
+		t, err := parseMonthYear(*endDate)
 		if err != nil {
-			RespondError(c, http.StatusBadRequest, "invalid_field", "invalid start_date, expected MM-YYYY", map[string]string{"start_date": "expected MM-YYYY"})
-			return
-		}
-		existing.StartDate = t
-		if existing.EndDate != nil && existing.EndDate.Before(existing.StartDate) {
-			RespondError(c, http.StatusBadRequest, "invalid_field", "existing end_date is before new start_date", map[string]string{"end_date": "must be >= start_date"})
-			return
+			RespondError(c, http.StatusBadRequest, "invalid_field", "end_date must be in format MM-YYYY", map[string]string{"end_date": "expected MM-YYYY"})
+			return nil, false
 		}
-	}
-	if req.EndDate != nil {
-		if *req.EndDate == "" {
-			existing.EndDate = nil
-		} else {
-			t, err := parseMonthYear(*req.EndDate)
-			if err != nil {
-				RespondError(c, http.StatusBadRequest, "invalid_field", "invalid end_date, expected MM-YYYY", map[string]string{"end_date": "expected MM-YYYY"})
-				return
-			}
-			if t.Before(existing.StartDate) {
-				RespondError(c, http.StatusBadRequest, "invalid_field", "end_date must be equal or after start_date", map[string]string{"end_date": "must be >= start_date"})
-				return
-			}
-			existing.EndDate = &t
+		if t.Before(start) {
+			RespondError(c, http.StatusBadRequest, "invalid_field", "end_date must be equal or after start_date", map[string]string{"end_date": "must be >= start_date"})
+			return nil, false
 		}
+		endPtr = &t
 	}
 
-	if err := h.usecase.Update(ctx, existing); err != nil {
-		h.log.Errorf("update failed: %v", err)
-		RespondError(c, http.StatusInternalServerError, "internal_error", "update failed", nil)
-		return
+	serviceName = strings.TrimSpace(serviceName)
+	if serviceName == "" || len(serviceName) > 255 {
+		RespondError(c, http.StatusBadRequest, "invalid_field", "service_name is required and must be <=255 chars", map[string]string{"service_name": "required, max 255 chars"})
+		return nil, false
 	}
-	c.JSON(http.StatusOK, existing)
+	if price < 0 {
+		RespondError(c, http.StatusBadRequest, "invalid_field", "price must be >= 0", map[string]string{"price": "must be >= 0"})
+		return nil, false
+	}
+	return &domain.Subscription{
+		ServiceName: serviceName,
+		Price:       price,
+		UserID:      uid,
+		StartDate:   start,
+		EndDate:     endPtr,
+	}, true
 }
 
 // Delete godoc
@@ -372,6 +537,7 @@ func (h *Handler) Update(c *gin.Context) {
 // @Tags subscriptions
 // @Param id path string true "subscription id"
 // @Success 204
+// @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/subscriptions/{id} [delete]
 func (h *Handler) Delete(c *gin.Context) {
@@ -384,9 +550,80 @@ func (h *Handler) Delete(c *gin.Context) {
 		return
 	}
 	if err := h.usecase.Delete(ctx, id); err != nil {
-		h.log.Errorf("delete failed: %v", err)
-		RespondError(c, http.StatusInternalServerError, "internal_error", "delete failed", nil)
+		respondErr(c, h.log, "delete", err)
 		return
 	}
 	c.Status(http.StatusNoContent)
 }
+
+// BulkDelete godoc
+// @Summary Delete several subscriptions (admin)
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param input body httpdto.BulkDeleteRequest true "subscription ids"
+// @Success 200 {object} httpdto.DeletedResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/subscriptions/bulk-delete [post]
+func (h *Handler) BulkDelete(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req httpdto.BulkDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warnf("invalid bulk delete body: %v", err)
+		RespondError(c, http.StatusBadRequest, "invalid_payload", "invalid request body", map[string]string{"body": err.Error()})
+		return
+	}
+	ids := make([]uuid.UUID, 0, len(req.IDs))
+	for _, s := range req.IDs {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			RespondError(c, http.StatusBadRequest, "invalid_field", "ids must be UUIDs", map[string]string{"ids": "invalid uuid: " + s})
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	n, err := h.usecase.BulkDelete(ctx, ids)
+	if err != nil {
+		respondErr(c, h.log, "bulk delete", err)
+		return
+	}
+	c.JSON(http.StatusOK, httpdto.DeletedResponse{Deleted: n})
+}
+
+// Purge godoc
+// @Summary Delete all subscriptions of a user (admin)
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param input body httpdto.PurgeRequest true "user to purge"
+// @Success 200 {object} httpdto.DeletedResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/subscriptions/purge [post]
+func (h *Handler) Purge(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req httpdto.PurgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warnf("invalid purge body: %v", err)
+		RespondError(c, http.StatusBadRequest, "invalid_payload", "invalid request body", map[string]string{"body": err.Error()})
+		return
+	}
+	uid, err := uuid.Parse(req.UserID)
+	if err != nil {
+		RespondError(c, http.StatusBadRequest, "invalid_field", "user_id must be a UUID", map[string]string{"user_id": "invalid uuid"})
+		return
+	}
+
+	n, err := h.usecase.PurgeUser(ctx, uid)
+	if err != nil {
+		respondErr(c, h.log, "purge", err)
+		return
+	}
+	c.JSON(http.StatusOK, httpdto.DeletedResponse{Deleted: n})
+}
@@ -0,0 +1,522 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	httpdto "subcalc/internal/delivery/http"
+	"subcalc/internal/domain"
+	memrepo "subcalc/internal/repository/memory"
+	"subcalc/internal/usecase"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func newMemoryRouter(middleware ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware...)
+	uc := usecase.NewSubscriptionUsecase(memrepo.NewMemorySubscriptionRepo(memrepo.NewStore()))
+	NewHandler(uc, zap.NewNop().Sugar()).RegisterRoutes(r)
+	return r
+}
+
+func TestCreateThenSum(t *testing.T) {
+	r := newMemoryRouter()
+	user := uuid.NewString()
+
+	body, _ := json.Marshal(httpdto.CreateSubscriptionRequest{
+		ServiceName: "Netflix", Price: 499, UserID: user, StartDate: "07-2025", EndDate: ptr("09-2025"),
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/subscriptions", bytes.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/subscriptions/sum?from=01-2025&to=12-2025&user_id="+user, nil))
+	var total httpdto.TotalResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &total)
+	if w.Code != http.StatusOK || total.Total != 3*499 || total.Currency != "RUB" {
+		t.Fatalf("sum: got %d %s", w.Code, w.Body)
+	}
+}
+
+func TestBreakdownAndForecast(t *testing.T) {
+	r := newMemoryRouter()
+	user := uuid.NewString()
+
+	for _, req := range []httpdto.CreateSubscriptionRequest{
+		{ServiceName: "Netflix", Price: 499, UserID: user, StartDate: "07-2025", EndDate: ptr("09-2025")},
+		{ServiceName: "Spotify", Price: 199, UserID: user, StartDate: "09-2025"},
+	} {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/subscriptions", bytes.NewReader(body)))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/subscriptions/breakdown?from=08-2025&to=10-2025&user_id="+user, nil))
+	var b httpdto.BreakdownResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &b)
+	if w.Code != http.StatusOK || b.Total != 2*499+2*199 || len(b.Months) != 3 || len(b.Services) != 2 {
+		t.Fatalf("breakdown: got %d %s", w.Code, w.Body)
+	}
+	if b.Months[1].Month != "09-2025" || b.Months[1].Total != 499+199 || b.Services[0].ServiceName != "Netflix" {
+		t.Fatalf("breakdown: unexpected split %s", w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/subscriptions/forecast?months=6&from=01-2026&user_id="+user, nil))
+	b = httpdto.BreakdownResponse{}
+	_ = json.Unmarshal(w.Body.Bytes(), &b)
+	if w.Code != http.StatusOK || b.From != "01-2026" || b.To != "06-2026" || b.Total != 6*199 {
+		t.Fatalf("forecast: got %d %s", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/subscriptions/forecast?months=61", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("forecast: expected 400 for months=61, got %d", w.Code)
+	}
+}
+
+func TestListCursorPagination(t *testing.T) {
+	r := newMemoryRouter()
+	for i := range 5 {
+		body, _ := json.Marshal(httpdto.CreateSubscriptionRequest{
+			ServiceName: "S", Price: i + 1, UserID: uuid.NewString(), StartDate: "07-2025",
+		})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/subscriptions", bytes.NewReader(body)))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body)
+		}
+	}
+
+	get := func(url string) ([]domain.Subscription, http.Header) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: got %d %s", url, w.Code, w.Body)
+		}
+		var subs []domain.Subscription
+		_ = json.Unmarshal(w.Body.Bytes(), &subs)
+		return subs, w.Header()
+	}
+	link := func(h http.Header, rel string) string {
+		for _, l := range strings.Split(h.Get("Link"), ", ") {
+			if target, ok := strings.CutSuffix(l, `>; rel="`+rel+`"`); ok {
+				return strings.TrimPrefix(target, "<")
+			}
+		}
+		return ""
+	}
+
+	var prices []int
+	url := "/api/subscriptions?limit=2"
+	var last http.Header
+	for url != "" {
+		subs, h := get(url)
+		for _, s := range subs {
+			prices = append(prices, s.Price)
+		}
+		last, url = h, link(h, "next")
+	}
+	if !slices.Equal(prices, []int{1, 2, 3, 4, 5}) {
+		t.Fatalf("pages by next link: got prices %v", prices)
+	}
+	if last.Get("X-Total-Count") != "5" || last.Get("X-Next-Cursor") != "" {
+		t.Fatalf("last page headers: %v", last)
+	}
+
+	subs, h := get(link(last, "prev"))
+	if len(subs) != 2 || subs[0].Price != 3 || subs[1].Price != 4 {
+		t.Fatalf("prev page: got %+v", subs)
+	}
+	if subs, _ = get(link(h, "prev")); len(subs) != 2 || subs[0].Price != 1 {
+		t.Fatalf("first page by prev link: got %+v", subs)
+	}
+
+	if _, h := get("/api/subscriptions?limit=2&offset=2"); link(h, "prev") == "" || link(h, "next") == "" {
+		t.Fatalf("offset page must link both ways, got %q", h.Get("Link"))
+	}
+
+	for _, url := range []string{"/api/subscriptions?cursor=bogus", "/api/subscriptions?cursor=" + h.Get("X-Prev-Cursor") + "&offset=1"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("GET %s: expected 400, got %d", url, w.Code)
+		}
+	}
+}
+
+func TestListSortAndFilters(t *testing.T) {
+	r := newMemoryRouter()
+	alice, bob := uuid.NewString(), uuid.NewString()
+	for _, req := range []httpdto.CreateSubscriptionRequest{
+		{ServiceName: "Netflix", Price: 499, UserID: alice, StartDate: "01-2025"},
+		{ServiceName: "Spotify", Price: 199, UserID: alice, StartDate: "03-2025", EndDate: ptr("06-2025")},
+		{ServiceName: "Netflix Kids", Price: 299, UserID: bob, StartDate: "05-2025"},
+		{ServiceName: "YouTube", Price: 399, UserID: uuid.NewString(), StartDate: "02-2025"},
+	} {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/subscriptions", bytes.NewReader(body)))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body)
+		}
+	}
+
+	cases := []struct {
+		query string
+		want  []int
+	}{
+		{"sort=-price", []int{499, 399, 299, 199}},
+		{"sort=service_name,-start_date&service_name_prefix=netflix&ignore_case=true", []int{499, 299}},
+		{"user_id=" + alice + "," + bob + "&sort=price", []int{199, 299, 499}},
+		{"user_id=" + alice + "&user_id=" + bob + "&price_min=200&price_max=500&sort=price", []int{299, 499}},
+		{"active_on=06-2025&open_ended=false", []int{199}},
+		{"created_after=2000-01-01T00:00:00Z&sort=-start_date&limit=2", []int{299, 199}},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/subscriptions?"+c.query, nil))
+		var subs []domain.Subscription
+		_ = json.Unmarshal(w.Body.Bytes(), &subs)
+		var got []int
+		for _, s := range subs {
+			got = append(got, s.Price)
+		}
+		if w.Code != http.StatusOK || !slices.Equal(got, c.want) {
+			t.Errorf("?%s: got %d %v, want %v", c.query, w.Code, got, c.want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/subscriptions?sort=price&limit=2&offset=1", nil))
+	if link := w.Header().Get("Link"); !strings.Contains(link, "offset=3") || !strings.Contains(link, "offset=0") || w.Header().Get("X-Next-Cursor") != "" {
+		t.Errorf("sorted page links: got %q", link)
+	}
+
+	for _, query := range []string{"sort=colour", "sort=price&cursor=x", "price_min=-1", "price_min=5&price_max=1", "open_ended=maybe", "active_on=2025-06", "updated_after=yesterday"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/subscriptions?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("?%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	r := newMemoryRouter()
+	for _, name := range []string{"Yandex Plus", "Netflix", "Yandex <Music>"} {
+		body, _ := json.Marshal(httpdto.CreateSubscriptionRequest{ServiceName: name, Price: 299, UserID: uuid.NewString(), StartDate: "01-2025"})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/subscriptions", bytes.NewReader(body)))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/subscriptions/search?q=yandx", nil))
+	var results []httpdto.SearchResult
+	_ = json.Unmarshal(w.Body.Bytes(), &results)
+	if w.Code != http.StatusOK || len(results) != 2 {
+		t.Fatalf("search: got %d %s", w.Code, w.Body)
+	}
+	highlights := []string{results[0].Highlight, results[1].Highlight}
+	slices.Sort(highlights)
+	if highlights[0] != "<em>Yandex</em> &lt;Music&gt;" || highlights[1] != "<em>Yandex</em> Plus" {
+		t.Fatalf("search: unexpected highlights %q", highlights)
+	}
+
+	for _, query := range []string{"", "q=%20", "q=x&limit=0", "q=" + strings.Repeat("x", 101)} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/subscriptions/search?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("?%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestQueryValidation(t *testing.T) {
+	cases := []struct {
+		name   string
+		strict bool
+		url    string
+		fields []string
+	}{
+		// a typo in the uuid used to drop the filter and sum every user
+		{"invalid user_id on sum", false, "/api/subscriptions/sum?from=01-2025&to=12-2025&user_id=1c9d4f8b-f0f1-4b9a-8f5e", []string{"user_id"}},
+		{"every problem at once", false, "/api/subscriptions?limit=abc&offset=-1&user_id=x&from=2025-01", []string{"limit", "offset", "user_id", "from"}},
+		{"limit over the maximum", false, "/api/subscriptions?limit=5000", []string{"limit"}},
+		{"missing period", false, "/api/subscriptions/breakdown?service_name=Netflix", []string{"from", "to"}},
+		{"reversed period", false, "/api/subscriptions/sum?from=12-2025&to=01-2025", []string{"from"}},
+		{"unknown parameter when strict", true, "/api/subscriptions?limit=10&usr_id=x", []string{"usr_id"}},
+		{"unknown parameter on search when strict", true, "/api/subscriptions/search?q=x&sort=price", []string{"sort"}},
+	}
+	for _, c := range cases {
+		var mw []gin.HandlerFunc
+		if c.strict {
+			mw = append(mw, StrictQuery())
+		}
+		r := newMemoryRouter(mw...)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, c.url, nil))
+		var resp ErrorResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || resp.Code != "invalid_query" {
+			t.Errorf("%s: expected 400 invalid_query, got %d %s", c.name, w.Code, w.Body)
+			continue
+		}
+		got := make([]string, 0, len(resp.Fields))
+		for f := range resp.Fields {
+			got = append(got, f)
+		}
+		slices.Sort(got)
+		want := slices.Sorted(slices.Values(c.fields))
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: fields %v, want %v", c.name, resp.Fields, want)
+		}
+	}
+
+	// known parameters pass strict mode, unknown ones pass otherwise
+	for _, c := range []struct {
+		strict bool
+		url    string
+	}{
+		{true, "/api/subscriptions?limit=10&offset=0&sort=price&user_id=" + uuid.NewString()},
+		{false, "/api/subscriptions?usr_id=x"},
+	} {
+		var mw []gin.HandlerFunc
+		if c.strict {
+			mw = append(mw, StrictQuery())
+		}
+		w := httptest.NewRecorder()
+		newMemoryRouter(mw...).ServeHTTP(w, httptest.NewRequest(http.MethodGet, c.url, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("%s (strict %v): expected 200, got %d %s", c.url, c.strict, w.Code, w.Body)
+		}
+	}
+}
+
+func TestProblemDetails(t *testing.T) {
+	r := newMemoryRouter()
+	missing := "/api/subscriptions/" + uuid.NewString()
+
+	// without asking for problem details the old shape is kept
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, missing, nil))
+	var legacy ErrorResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &legacy)
+	if w.Code != http.StatusNotFound || legacy.Code != "not_found" || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Fatalf("get unknown id: got %d %s %s", w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, missing, nil)
+	req.Header.Set("Accept", "application/json, application/problem+json")
+	req.Header.Set("X-Request-Id", "req-1")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var p Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &p)
+	if w.Code != http.StatusNotFound || w.Header().Get("Content-Type") != ProblemContentType {
+		t.Fatalf("get unknown id: got %d %s %s", w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+	want := Problem{Type: "/problems/not_found", Title: "Not Found", Status: 404, Detail: "not found", Instance: "req-1", Code: "not_found"}
+	if !reflect.DeepEqual(p, want) {
+		t.Fatalf("problem = %+v, want %+v", p, want)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/subscriptions?limit=0&offset=-1", nil)
+	req.Header.Set("Accept", ProblemContentType)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	p = Problem{}
+	_ = json.Unmarshal(w.Body.Bytes(), &p)
+	names := []string{}
+	for _, ip := range p.InvalidParams {
+		names = append(names, ip.Name)
+	}
+	if w.Code != http.StatusBadRequest || p.Status != 400 || !slices.Equal(names, []string{"limit", "offset"}) {
+		t.Fatalf("invalid query: got %d %s", w.Code, w.Body)
+	}
+}
+
+func TestAcceptsProblem(t *testing.T) {
+	cases := map[string]bool{
+		"":                         false,
+		"*/*":                      false,
+		"application/json":         false,
+		"application/problem+json": true,
+		"application/json, application/problem+json;q=0.5": true,
+		"application/problem+json;q=0":                     false,
+	}
+	for accept, want := range cases {
+		if got := acceptsProblem(accept); got != want {
+			t.Errorf("acceptsProblem(%q) = %v, want %v", accept, got, want)
+		}
+	}
+}
+
+func TestLocalizedErrors(t *testing.T) {
+	r := newMemoryRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/subscriptions?limit=0&from=13-2025", nil)
+	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en;q=0.8")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var resp ErrorResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	want := ErrorResponse{
+		Code:    "invalid_query",
+		Message: "некорректные параметры запроса",
+		Fields:  map[string]string{"limit": "ожидается целое число от 1 до 1000", "from": "ожидается ММ-ГГГГ"},
+	}
+	if w.Code != http.StatusBadRequest || !reflect.DeepEqual(resp, want) || w.Header().Get("Content-Language") != "ru" {
+		t.Fatalf("got %d %s %s", w.Code, w.Header().Get("Content-Language"), w.Body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/subscriptions/"+uuid.NewString(), nil)
+	req.Header.Set("Accept-Language", "ru")
+	req.Header.Set("Accept", ProblemContentType)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var p Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &p)
+	if p.Title != "не найдено" || p.Detail != "не найдено" || p.Code != "not_found" {
+		t.Fatalf("problem: %s", w.Body)
+	}
+}
+
+func TestUpdateReplaces(t *testing.T) {
+	r := newMemoryRouter()
+	user := uuid.NewString()
+	sub := create(t, r, httpdto.CreateSubscriptionRequest{ServiceName: "Netflix", Price: 499, UserID: user, StartDate: "07-2025", EndDate: ptr("12-2025")})
+
+	body := `{"service_name":"Netflix Premium","price":0,"user_id":"` + user + `","start_date":"08-2025"}`
+	w := send(r, http.MethodPut, "/api/subscriptions/"+sub.ID.String(), "application/json", body)
+	var got domain.Subscription
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if w.Code != http.StatusOK || got.ServiceName != "Netflix Premium" || got.Price != 0 || got.EndDate != nil || got.ID != sub.ID {
+		t.Fatalf("put: got %d %s", w.Code, w.Body)
+	}
+
+	// every field is required
+	w = send(r, http.MethodPut, "/api/subscriptions/"+sub.ID.String(), "application/json", `{"price":100}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("partial put: expected 400, got %d", w.Code)
+	}
+}
+
+func TestMissingSubscriptionsAreNotFound(t *testing.T) {
+	r := newMemoryRouter()
+	user := uuid.NewString()
+	sub := create(t, r, httpdto.CreateSubscriptionRequest{ServiceName: "Netflix", Price: 499, UserID: user, StartDate: "07-2025"})
+
+	if w := send(r, http.MethodDelete, "/api/subscriptions/"+sub.ID.String(), "", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d: %s", w.Code, w.Body)
+	}
+	if w := send(r, http.MethodDelete, "/api/subscriptions/"+sub.ID.String(), "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("delete again: expected 404, got %d: %s", w.Code, w.Body)
+	}
+	body := `{"service_name":"Netflix","price":499,"user_id":"` + user + `","start_date":"07-2025"}`
+	if w := send(r, http.MethodPut, "/api/subscriptions/"+uuid.NewString(), "application/json", body); w.Code != http.StatusNotFound {
+		t.Fatalf("put unknown id: expected 404, got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	r := newMemoryRouter()
+	sub := create(t, r, httpdto.CreateSubscriptionRequest{ServiceName: "Netflix", Price: 499, UserID: uuid.NewString(), StartDate: "07-2025", EndDate: ptr("12-2025")})
+	url := "/api/subscriptions/" + sub.ID.String()
+
+	cases := []struct {
+		name, contentType, body string
+		status                  int
+		check                   func(*domain.Subscription) bool
+	}{
+		{"merge keeps other fields", mergePatchType, `{"price":599}`, http.StatusOK,
+			func(s *domain.Subscription) bool {
+				return s.Price == 599 && s.ServiceName == "Netflix" && s.EndDate != nil
+			}},
+		{"merge null clears end_date", mergePatchType, `{"end_date":null}`, http.StatusOK,
+			func(s *domain.Subscription) bool { return s.EndDate == nil && s.Price == 599 }},
+		{"json patch", jsonPatchType, `[{"op":"test","path":"/price","value":599},{"op":"replace","path":"/service_name","value":"Netflix 4K"},{"op":"add","path":"/end_date","value":"01-2026"}]`, http.StatusOK,
+			func(s *domain.Subscription) bool {
+				return s.ServiceName == "Netflix 4K" && s.EndDate != nil && s.EndDate.Year() == 2026
+			}},
+		{"json patch remove", jsonPatchType, `[{"op":"remove","path":"/end_date"}]`, http.StatusOK,
+			func(s *domain.Subscription) bool { return s.EndDate == nil }},
+		{"failed test changes nothing", jsonPatchType, `[{"op":"replace","path":"/price","value":1},{"op":"test","path":"/price","value":2}]`, http.StatusConflict, nil},
+		{"same rules as update", mergePatchType, `{"end_date":"01-2020"}`, http.StatusBadRequest, nil},
+		{"negative price", mergePatchType, `{"price":-1}`, http.StatusBadRequest, nil},
+		{"required field removed", jsonPatchType, `[{"op":"remove","path":"/service_name"}]`, http.StatusBadRequest, nil},
+		{"read-only field", mergePatchType, `{"id":"` + uuid.NewString() + `"}`, http.StatusBadRequest, nil},
+		{"unknown field", mergePatchType, `{"colour":"red"}`, http.StatusBadRequest, nil},
+		{"missing path", jsonPatchType, `[{"op":"replace","path":"/nope","value":1}]`, http.StatusBadRequest, nil},
+		{"plain json", "application/json", `{"price":1}`, http.StatusUnsupportedMediaType, nil},
+	}
+	for _, c := range cases {
+		w := send(r, http.MethodPatch, url, c.contentType, c.body)
+		if w.Code != c.status {
+			t.Errorf("%s: expected %d, got %d %s", c.name, c.status, w.Code, w.Body)
+			continue
+		}
+		if c.check != nil {
+			var got domain.Subscription
+			_ = json.Unmarshal(w.Body.Bytes(), &got)
+			if !c.check(&got) {
+				t.Errorf("%s: got %s", c.name, w.Body)
+			}
+		}
+	}
+
+	w := send(r, http.MethodGet, url, "", "")
+	var got domain.Subscription
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if got.Price != 599 || got.ServiceName != "Netflix 4K" {
+		t.Fatalf("rejected patches must not be stored: %s", w.Body)
+	}
+	if w := send(r, http.MethodPatch, "/api/subscriptions/"+uuid.NewString(), mergePatchType, `{}`); w.Code != http.StatusNotFound {
+		t.Fatalf("patch unknown id: expected 404, got %d", w.Code)
+	}
+}
+
+func create(t *testing.T, r *gin.Engine, req httpdto.CreateSubscriptionRequest) *domain.Subscription {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	w := send(r, http.MethodPost, "/api/subscriptions", "application/json", string(body))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body)
+	}
+	var sub domain.Subscription
+	_ = json.Unmarshal(w.Body.Bytes(), &sub)
+	return &sub
+}
+
+func send(r *gin.Engine, method, url, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+	httpdto "subcalc/internal/delivery/http"
+	"subcalc/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type OrgHandler struct {
+	usecase usecase.OrganizationUsecase
+	log     *zap.SugaredLogger
+}
+
+func NewOrgHandler(u usecase.OrganizationUsecase, log *zap.SugaredLogger) *OrgHandler {
+	return &OrgHandler{usecase: u, log: log}
+}
+
+func (h *OrgHandler) RegisterRoutes(r *gin.Engine, middleware ...gin.HandlerFunc) {
+	org := r.Group("/api/org", middleware...)
+	org.Use(Authorize())
+	{
+		org.GET("", h.Get)
+		org.PUT("", h.Update)
+	}
+}
+
+// Get godoc
+// @Summary Current organization and its settings
+// @Tags organizations
+// @Produce json
+// @Param X-Org-Id header string false "organization id when not bound by the token"
+// @Success 200 {object} domain.Organization
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/org [get]
+func (h *OrgHandler) Get(c *gin.Context) {
+	org, err := h.usecase.Current(c.Request.Context())
+	if err != nil {
+		respondErr(c, h.log, "get", err)
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+// Update godoc
+// @Summary Update organization settings
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param input body httpdto.UpdateOrganizationRequest true "settings"
+// @Success 200 {object} domain.Organization
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/org [put]
+func (h *OrgHandler) Update(c *gin.Context) {
+	var req httpdto.UpdateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warnf("invalid organization body: %v", err)
+		RespondError(c, http.StatusBadRequest, "invalid_payload", "invalid request body", map[string]string{"body": err.Error()})
+		return
+	}
+
+	org, err := h.usecase.UpdateSettings(c.Request.Context(), req.Name, req.DefaultCurrency)
+	if err != nil {
+		respondErr(c, h.log, "update", err)
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	httpdto "subcalc/internal/delivery/http"
+	"subcalc/internal/domain"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	mergePatchType = "application/merge-patch+json"
+	jsonPatchType  = "application/json-patch+json"
+)
+
+// errPatchTest is returned when a JSON patch "test" operation fails.
+var errPatchTest = errors.New("test failed")
+
+// readOnlyFields are the fields of a subscription that patches must leave
+// as they are.
+var readOnlyFields = []string{"id", "org_id", "created_at", "updated_at"}
+
+// decodePatched reads a patched subscription back into the representation
+// Update takes. Changed read-only fields are reported per field.
+func decodePatched(doc []byte, existing *domain.Subscription) (*httpdto.ReplaceSubscriptionRequest, map[string]string, error) {
+	var patched, original map[string]any
+	if err := json.Unmarshal(doc, &patched); err != nil || patched == nil {
+		return nil, nil, errors.New("the patched subscription is not an object")
+	}
+	orig, _ := json.Marshal(existing)
+	_ = json.Unmarshal(orig, &original)
+
+	fields := map[string]string{}
+	for _, name := range readOnlyFields {
+		if !reflect.DeepEqual(patched[name], original[name]) {
+			fields[name] = "read-only"
+		}
+		delete(patched, name)
+	}
+	if len(fields) > 0 {
+		return nil, fields, nil
+	}
+
+	doc, _ = json.Marshal(patched)
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.DisallowUnknownFields()
+	var req httpdto.ReplaceSubscriptionRequest
+	if err := dec.Decode(&req); err != nil {
+		return nil, nil, err
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, nil, err
+	}
+	return &req, nil, nil
+}
+
+// applyMergePatch applies a JSON merge patch (RFC 7396) to doc.
+func applyMergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+type patchOp struct {
+	Op    string           `json:"op"`
+	Path  *string          `json:"path"`
+	From  *string          `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// applyJSONPatch applies the operations of a JSON patch (RFC 6902) to doc,
+// all or none of them.
+func applyJSONPatch(doc, patch []byte) ([]byte, error) {
+	var ops []patchOp
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, err
+	}
+	var root any
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+	for i, op := range ops {
+		var err error
+		if root, err = op.apply(root); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return json.Marshal(root)
+}
+
+func (op patchOp) apply(root any) (any, error) {
+	if op.Path == nil {
+		return nil, errors.New(`missing "path"`)
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+	value := func() (any, error) {
+		if op.Value == nil {
+			return nil, errors.New(`missing "value"`)
+		}
+		var v any
+		err := json.Unmarshal(*op.Value, &v)
+		return v, err
+	}
+	from := func() ([]string, error) {
+		if op.From == nil {
+			return nil, errors.New(`missing "from"`)
+		}
+		return parsePointer(*op.From)
+	}
+
+	switch op.Op {
+	case "add":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		return addAt(root, path, v)
+	case "remove":
+		root, _, err := removeAt(root, path)
+		return root, err
+	case "replace":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return v, nil
+		}
+		if root, _, err = removeAt(root, path); err != nil {
+			return nil, err
+		}
+		return addAt(root, path, v)
+	case "move":
+		src, err := from()
+		if err != nil {
+			return nil, err
+		}
+		if len(src) < len(path) && slices.Equal(path[:len(src)], src) {
+			return nil, errors.New("cannot move a value into itself")
+		}
+		root, v, err := removeAt(root, src)
+		if err != nil {
+			return nil, err
+		}
+		return addAt(root, path, v)
+	case "copy":
+		src, err := from()
+		if err != nil {
+			return nil, err
+		}
+		v, err := getAt(root, src)
+		if err != nil {
+			return nil, err
+		}
+		// a deep copy, so that later operations on either do not affect both
+		raw, _ := json.Marshal(v)
+		_ = json.Unmarshal(raw, &v)
+		return addAt(root, path, v)
+	case "test":
+		want, err := value()
+		if err != nil {
+			return nil, err
+		}
+		got, err := getAt(root, path)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			return nil, fmt.Errorf("%w: %s", errPatchTest, *op.Path)
+		}
+		return root, nil
+	}
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+// parsePointer splits a JSON pointer (RFC 6901) into its reference tokens.
+func parsePointer(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("invalid path %q", s)
+	}
+	toks := strings.Split(s[1:], "/")
+	for i, t := range toks {
+		toks[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return toks, nil
+}
+
+// index is the array position tok names; "-", past the end, only when
+// adding.
+func index(tok string, n int, adding bool) (int, error) {
+	if adding && tok == "-" {
+		return n, nil
+	}
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || i > n || (i == n && !adding) || (tok != "0" && strings.HasPrefix(tok, "0")) {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	return i, nil
+}
+
+func getAt(node any, path []string) (any, error) {
+	for _, tok := range path {
+		switch n := node.(type) {
+		case map[string]any:
+			v, ok := n[tok]
+			if !ok {
+				return nil, fmt.Errorf("no member %q", tok)
+			}
+			node = v
+		case []any:
+			i, err := index(tok, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("cannot descend into %q", tok)
+		}
+	}
+	return node, nil
+}
+
+// addAt returns node with v added at path. Objects are changed in place;
+// arrays may move, so the result replaces node.
+func addAt(node any, path []string, v any) (any, error) {
+	if len(path) == 0 {
+		return v, nil
+	}
+	tok, last := path[0], len(path) == 1
+	switch n := node.(type) {
+	case map[string]any:
+		if last {
+			n[tok] = v
+			return n, nil
+		}
+		child, ok := n[tok]
+		if !ok {
+			return nil, fmt.Errorf("no member %q", tok)
+		}
+		child, err := addAt(child, path[1:], v)
+		n[tok] = child
+		return n, err
+	case []any:
+		i, err := index(tok, len(n), last)
+		if err != nil {
+			return nil, err
+		}
+		if last {
+			return append(n[:i], append([]any{v}, n[i:]...)...), nil
+		}
+		n[i], err = addAt(n[i], path[1:], v)
+		return n, err
+	}
+	return nil, fmt.Errorf("cannot descend into %q", tok)
+}
+
+// removeAt returns node without the value at path, and that value.
+func removeAt(node any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("cannot remove the whole document")
+	}
+	tok, last := path[0], len(path) == 1
+	switch n := node.(type) {
+	case map[string]any:
+		child, ok := n[tok]
+		if !ok {
+			return nil, nil, fmt.Errorf("no member %q", tok)
+		}
+		if last {
+			delete(n, tok)
+			return n, child, nil
+		}
+		child, removed, err := removeAt(child, path[1:])
+		n[tok] = child
+		return n, removed, err
+	case []any:
+		i, err := index(tok, len(n), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if last {
+			removed := n[i]
+			return append(n[:i], n[i+1:]...), removed, nil
+		}
+		child, removed, err := removeAt(n[i], path[1:])
+		n[i] = child
+		return n, removed, err
+	}
+	return nil, nil, fmt.Errorf("cannot descend into %q", tok)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"subcalc/internal/auth"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestPolicy_CoversEveryRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	NewHandler(nil, nil).RegisterRoutes(r)
+	NewAPIKeyHandler(nil, nil).RegisterRoutes(r)
+	NewOrgHandler(nil, nil).RegisterRoutes(r)
+	NewWebhookHandler(nil, nil).RegisterRoutes(r)
+	NewEventHandler(nil, 0, nil).RegisterRoutes(r)
+
+	for _, route := range r.Routes() {
+		key := handlerKey(route.Handler)
+		if _, ok := Policy[key]; !ok {
+			t.Errorf("%s %s (%s) has no policy entry", route.Method, route.Path, key)
+		}
+	}
+}
+
+func TestAuthorize_DeniesMissingPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	viewer := &auth.Principal{UserID: uuid.New(), Role: auth.RoleViewer}
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), viewer))
+	})
+	NewHandler(nil, nil).RegisterRoutes(r)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/api/subscriptions/"+uuid.NewString(), nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", w.Code)
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const strictQueryKey = "strict_query"
+
+// StrictQuery makes handlers reject query parameters they do not know, so
+// that a misspelt filter fails instead of widening the result.
+func StrictQuery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(strictQueryKey, true)
+		c.Next()
+	}
+}
+
+// query binds the query parameters of a request. Its getters record what is
+// wrong with a parameter instead of stopping, so that Valid can report every
+// problem in one response.
+type query struct {
+	c      *gin.Context
+	known  map[string]bool
+	fields map[string]string
+}
+
+func bindQuery(c *gin.Context) *query {
+	return &query{c: c, known: map[string]bool{}, fields: map[string]string{}}
+}
+
+// value is the trimmed value of name, empty when absent. Reading a
+// parameter is what makes it known to Valid.
+func (q *query) value(name string) string {
+	q.known[name] = true
+	return strings.TrimSpace(q.c.Query(name))
+}
+
+// fail records a problem with name; the first one recorded is reported.
+func (q *query) fail(name, problem string) {
+	if _, ok := q.fields[name]; !ok {
+		q.fields[name] = problem
+	}
+}
+
+func (q *query) String(name string) *string {
+	if s := q.value(name); s != "" {
+		return &s
+	}
+	return nil
+}
+
+func (q *query) Required(name string) string {
+	s := q.value(name)
+	if s == "" {
+		q.fail(name, "required")
+	}
+	return s
+}
+
+// Int is the integer name, between min and max, or def when absent.
+func (q *query) Int(name string, def, min, max int) int {
+	if v := q.IntRange(name, min, max); v != nil {
+		return *v
+	}
+	return def
+}
+
+// IntRange is the integer name, between min and max, or nil when absent.
+func (q *query) IntRange(name string, min, max int) *int {
+	s := q.value(name)
+	if s == "" {
+		return nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		if max == math.MaxInt {
+			q.fail(name, fmt.Sprintf("expected an integer >= %d", min))
+		} else {
+			q.fail(name, fmt.Sprintf("expected an integer from %d to %d", min, max))
+		}
+		return nil
+	}
+	return &v
+}
+
+func (q *query) Bool(name string) *bool {
+	s := q.value(name)
+	if s == "" {
+		return nil
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		q.fail(name, "expected true or false")
+		return nil
+	}
+	return &v
+}
+
+// Month is name in the MM-YYYY format of the API.
+func (q *query) Month(name string) *time.Time {
+	s := q.value(name)
+	if s == "" {
+		return nil
+	}
+	t, err := parseMonthYear(s)
+	if err != nil {
+		q.fail(name, "expected MM-YYYY")
+		return nil
+	}
+	return &t
+}
+
+// Time is name as an RFC 3339 timestamp.
+func (q *query) Time(name string) *time.Time {
+	s := q.value(name)
+	if s == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		q.fail(name, "expected an RFC 3339 timestamp")
+		return nil
+	}
+	return &t
+}
+
+// UUIDs are the ids in name, which may be repeated or comma-separated.
+func (q *query) UUIDs(name string) []uuid.UUID {
+	q.known[name] = true
+	var out []uuid.UUID
+	for _, v := range q.c.QueryArray(name) {
+		for _, s := range strings.Split(v, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			id, err := uuid.Parse(s)
+			if err != nil {
+				q.fail(name, fmt.Sprintf("invalid uuid %q", s))
+				continue
+			}
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// Valid reports whether the query bound without problems, responding with
+// all of them otherwise. Under StrictQuery, parameters nothing read are
+// problems too.
+func (q *query) Valid() bool {
+	if q.c.GetBool(strictQueryKey) {
+		for name := range q.c.Request.URL.Query() {
+			if !q.known[name] {
+				q.fail(name, "unknown parameter")
+			}
+		}
+	}
+	if len(q.fields) == 0 {
+		return true
+	}
+	RespondError(q.c, http.StatusBadRequest, "invalid_query", "invalid query parameters", q.fields)
+	return false
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"net/http"
+	httpdto "subcalc/internal/delivery/http"
+	"subcalc/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type WebhookHandler struct {
+	usecase usecase.WebhookUsecase
+	log     *zap.SugaredLogger
+}
+
+func NewWebhookHandler(u usecase.WebhookUsecase, log *zap.SugaredLogger) *WebhookHandler {
+	return &WebhookHandler{usecase: u, log: log}
+}
+
+func (h *WebhookHandler) RegisterRoutes(r *gin.Engine, middleware ...gin.HandlerFunc) {
+	hooks := r.Group("/api/webhooks", middleware...)
+	hooks.Use(Authorize())
+	{
+		hooks.POST("", h.Create)
+		hooks.GET("", h.List)
+		hooks.GET("/:id", h.Get)
+		hooks.PUT("/:id", h.Update)
+		hooks.DELETE("/:id", h.Delete)
+		hooks.GET("/:id/deliveries", h.Deliveries)
+	}
+}
+
+// Create godoc
+// @Summary Register webhook
+// @Description Events are POSTed as JSON with an X-Webhook-Signature header "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>" keyed with the secret>". Failed deliveries are retried with exponential backoff.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param input body httpdto.WebhookRequest true "webhook"
+// @Success 201 {object} httpdto.CreateWebhookResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/webhooks [post]
+func (h *WebhookHandler) Create(c *gin.Context) {
+	req, ok := h.bind(c)
+	if !ok {
+		return
+	}
+	hook, secret, err := h.usecase.Create(c.Request.Context(), req.URL, req.Events, active(req))
+	if err != nil {
+		respondErr(c, h.log, "create webhook", err)
+		return
+	}
+	c.JSON(http.StatusCreated, httpdto.CreateWebhookResponse{Secret: secret, Webhook: hook})
+}
+
+// List godoc
+// @Summary List webhooks
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} domain.Webhook
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/webhooks [get]
+func (h *WebhookHandler) List(c *gin.Context) {
+	hooks, err := h.usecase.List(c.Request.Context())
+	if err != nil {
+		respondErr(c, h.log, "list webhooks", err)
+		return
+	}
+	c.JSON(http.StatusOK, hooks)
+}
+
+// Get godoc
+// @Summary Get webhook
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "webhook id"
+// @Success 200 {object} domain.Webhook
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/webhooks/{id} [get]
+func (h *WebhookHandler) Get(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	hook, err := h.usecase.Get(c.Request.Context(), id)
+	if err != nil {
+		respondErr(c, h.log, "get webhook", err)
+		return
+	}
+	c.JSON(http.StatusOK, hook)
+}
+
+// Update godoc
+// @Summary Replace webhook
+// @Description The secret stays the same.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "webhook id"
+// @Param input body httpdto.WebhookRequest true "webhook"
+// @Success 200 {object} domain.Webhook
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/webhooks/{id} [put]
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	req, ok := h.bind(c)
+	if !ok {
+		return
+	}
+	hook, err := h.usecase.Update(c.Request.Context(), id, req.URL, req.Events, active(req))
+	if err != nil {
+		respondErr(c, h.log, "update webhook", err)
+		return
+	}
+	c.JSON(http.StatusOK, hook)
+}
+
+// Delete godoc
+// @Summary Delete webhook
+// @Description Pending deliveries are dropped with it.
+// @Tags webhooks
+// @Param id path string true "webhook id"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	if err := h.usecase.Delete(c.Request.Context(), id); err != nil {
+		respondErr(c, h.log, "delete webhook", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Deliveries godoc
+// @Summary Latest deliveries of a webhook
+// @Description Newest first, with the status and the response of the last attempt.
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "webhook id"
+// @Param limit query int false "number of deliveries, 1..200 (default 50)"
+// @Success 200 {array} domain.WebhookDelivery
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	q := bindQuery(c)
+	limit := q.Int("limit", 0, 1, 200)
+	if !q.Valid() {
+		return
+	}
+	deliveries, err := h.usecase.Deliveries(c.Request.Context(), id, limit)
+	if err != nil {
+		respondErr(c, h.log, "list deliveries", err)
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+func (h *WebhookHandler) bind(c *gin.Context) (*httpdto.WebhookRequest, bool) {
+	var req httpdto.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warnf("invalid webhook body: %v", err)
+		RespondError(c, http.StatusBadRequest, "invalid_payload", "invalid request body", map[string]string{"body": err.Error()})
+		return nil, false
+	}
+	return &req, true
+}
+
+func active(req *httpdto.WebhookRequest) bool {
+	return req.Active == nil || *req.Active
+}
+
+func webhookID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondError(c, http.StatusBadRequest, "invalid_field", "invalid id", map[string]string{"id": "invalid uuid"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -1,5 +1,12 @@
 package httpdto
 
+import (
+	"subcalc/internal/billing"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"subcalc/internal/search"
+)
+
 // swagger:model CreateSubscriptionRequest
 type CreateSubscriptionRequest struct {
 	// Service name (human readable)
@@ -23,15 +30,41 @@ type CreateSubscriptionRequest struct {
 	EndDate *string `json:"end_date,omitempty" example:"12-2025"`
 }
 
+// ReplaceSubscriptionRequest is the full state a subscription is replaced
+// with.
+// swagger:model ReplaceSubscriptionRequest
+type ReplaceSubscriptionRequest struct {
+	// example: Netflix
+	ServiceName string `json:"service_name" binding:"required" example:"Netflix"`
+
+	// Monthly price in whole rubles
+	// example: 499
+	Price *int `json:"price" binding:"required,gte=0" example:"499"`
+
+	// example: 1c9d4f8b-f0f1-4b9a-8f5e-6e9a0b7f8d12
+	UserID string `json:"user_id" binding:"required,uuid" example:"1c9d4f8b-f0f1-4b9a-8f5e-6e9a0b7f8d12"`
+
+	// example: 07-2025
+	StartDate string `json:"start_date" binding:"required" example:"07-2025"`
+
+	// Left out for an open-ended subscription.
+	// example: 12-2025
+	EndDate *string `json:"end_date,omitempty" example:"12-2025"`
+}
+
+// UpdateSubscriptionRequest is a JSON merge patch of a subscription: fields
+// left out are kept.
 // swagger:model UpdateSubscriptionRequest
 type UpdateSubscriptionRequest struct {
 	// example: Spotify
 	ServiceName *string `json:"service_name,omitempty" example:"Spotify"`
 	// example: 299
 	Price *int `json:"price,omitempty" example:"299"`
+	// example: 1c9d4f8b-f0f1-4b9a-8f5e-6e9a0b7f8d12
+	UserID *string `json:"user_id,omitempty" example:"1c9d4f8b-f0f1-4b9a-8f5e-6e9a0b7f8d12"`
 	// example: 07-2025
 	StartDate *string `json:"start_date,omitempty" example:"07-2025"`
-	// To explicitly clear end_date send empty string "".
+	// null clears end_date.
 	// example: 12-2025
 	EndDate *string `json:"end_date,omitempty" example:"12-2025"`
 }
@@ -41,4 +74,166 @@ type TotalResponse struct {
 	// Total amount in whole rubles for the given period (sum over months * price).
 	// example: 1497
 	Total int64 `json:"total" example:"1497"`
+
+	// Currency of the organization the total was computed for.
+	// example: RUB
+	Currency string `json:"currency" example:"RUB"`
+}
+
+// swagger:model BreakdownResponse
+type BreakdownResponse struct {
+	// example: 01-2025
+	From string `json:"from" example:"01-2025"`
+	// example: 12-2025
+	To string `json:"to" example:"12-2025"`
+
+	// Total amount in whole rubles over the whole range.
+	// example: 5988
+	Total int64 `json:"total" example:"5988"`
+	// example: RUB
+	Currency string `json:"currency" example:"RUB"`
+
+	// Every month of the range, including months with nothing charged.
+	Months []MonthTotal `json:"months"`
+	// Services sorted by total, largest first.
+	Services []ServiceTotal `json:"services"`
+}
+
+// swagger:model MonthTotal
+type MonthTotal struct {
+	// example: 07-2025
+	Month string `json:"month" example:"07-2025"`
+	// example: 499
+	Total int64 `json:"total" example:"499"`
+}
+
+// swagger:model ServiceTotal
+type ServiceTotal struct {
+	// example: Netflix
+	ServiceName string `json:"service_name" example:"Netflix"`
+	// example: 5988
+	Total int64 `json:"total" example:"5988"`
+}
+
+// NewBreakdownResponse converts a billing breakdown for the API.
+func NewBreakdownResponse(b *billing.Breakdown, currency string) BreakdownResponse {
+	resp := BreakdownResponse{
+		Total:    b.Total,
+		Currency: currency,
+		Months:   make([]MonthTotal, 0, len(b.Months)),
+		Services: make([]ServiceTotal, 0, len(b.Services)),
+	}
+	if !b.From.IsZero() {
+		resp.From = domain.FormatMonthYear(b.From)
+		resp.To = domain.FormatMonthYear(b.To)
+	}
+	for _, m := range b.Months {
+		resp.Months = append(resp.Months, MonthTotal{Month: domain.FormatMonthYear(m.Month), Total: m.Total})
+	}
+	for _, s := range b.Services {
+		resp.Services = append(resp.Services, ServiceTotal{ServiceName: s.ServiceName, Total: s.Total})
+	}
+	return resp
+}
+
+// swagger:model SearchResult
+type SearchResult struct {
+	Subscription *domain.Subscription `json:"subscription"`
+
+	// How well the query matched, from 0 to 1.
+	// example: 0.44
+	Score float64 `json:"score" example:"0.44"`
+
+	// The service name, HTML-escaped, with the matched words in <em>.
+	// example: <em>Yandex</em> Plus
+	Highlight string `json:"highlight" example:"<em>Yandex</em> Plus"`
+}
+
+// NewSearchResults converts search hits for the API.
+func NewSearchResults(hits []repository.SearchHit, query string) []SearchResult {
+	out := make([]SearchResult, 0, len(hits))
+	for _, h := range hits {
+		out = append(out, SearchResult{
+			Subscription: h.Subscription,
+			Score:        h.Score,
+			Highlight:    search.Highlight(h.Subscription.ServiceName, query),
+		})
+	}
+	return out
+}
+
+// swagger:model BulkDeleteRequest
+type BulkDeleteRequest struct {
+	// example: ["3fa85f64-5717-4562-b3fc-2c963f66afa6"]
+	IDs []string `json:"ids" binding:"required,min=1,max=1000" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"`
+}
+
+// swagger:model PurgeRequest
+type PurgeRequest struct {
+	// User whose subscriptions are all removed
+	// example: 1c9d4f8b-f0f1-4b9a-8f5e-6e9a0b7f8d12
+	UserID string `json:"user_id" binding:"required" example:"1c9d4f8b-f0f1-4b9a-8f5e-6e9a0b7f8d12"`
+}
+
+// swagger:model DeletedResponse
+type DeletedResponse struct {
+	// Number of removed subscriptions
+	// example: 3
+	Deleted int64 `json:"deleted" example:"3"`
+}
+
+// swagger:model CreateAPIKeyRequest
+type CreateAPIKeyRequest struct {
+	// Label of the key owner
+	// example: billing-job
+	Name string `json:"name" binding:"required" example:"billing-job"`
+
+	// Granted scopes: subscriptions:read, subscriptions:write, reports:read
+	// example: ["reports:read"]
+	Scopes []string `json:"scopes" binding:"required" example:"reports:read"`
+}
+
+// swagger:model CreateAPIKeyResponse
+type CreateAPIKeyResponse struct {
+	// Plaintext key, shown only once. Send it in the X-API-Key header.
+	// example: sk_3f9a1c2b...
+	Key string `json:"key" example:"sk_3f9a1c2b..."`
+
+	APIKey *domain.APIKey `json:"api_key"`
+}
+
+// swagger:model UpdateOrganizationRequest
+type UpdateOrganizationRequest struct {
+	// example: Finance department
+	Name *string `json:"name,omitempty" example:"Finance department"`
+	// ISO 4217 currency code
+	// example: RUB
+	DefaultCurrency *string `json:"default_currency,omitempty" example:"RUB"`
+}
+
+// swagger:model WebhookRequest
+type WebhookRequest struct {
+	// Absolute http or https URL the events are POSTed to, on a public
+	// address; redirects are not followed
+	// example: https://example.com/hooks/subscriptions
+	URL string `json:"url" binding:"required" example:"https://example.com/hooks/subscriptions"`
+
+	// Event types to deliver: subscription.created, subscription.updated,
+	// subscription.deleted, subscription.ending_soon, subscription.price_changed,
+	// subscription.renewing
+	// example: ["subscription.created","subscription.price_changed"]
+	Events []string `json:"events" binding:"required" example:"subscription.created,subscription.price_changed"`
+
+	// Whether events are delivered; true when omitted
+	// example: true
+	Active *bool `json:"active,omitempty" example:"true"`
+}
+
+// swagger:model CreateWebhookResponse
+type CreateWebhookResponse struct {
+	// Key of the X-Webhook-Signature HMAC, shown only once
+	// example: whsec_5f2b9c...
+	Secret string `json:"secret" example:"whsec_5f2b9c..."`
+
+	Webhook *domain.Webhook `json:"webhook"`
 }
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// swagger:model APIKey
+type APIKey struct {
+	// example: 8b0d7c7e-4a38-4e8f-9d6f-0f1d2b3c4d5e
+	ID uuid.UUID `json:"id" example:"8b0d7c7e-4a38-4e8f-9d6f-0f1d2b3c4d5e"`
+
+	// Organization the key acts in
+	// example: 00000000-0000-0000-0000-000000000001
+	OrgID uuid.UUID `json:"org_id" example:"00000000-0000-0000-0000-000000000001"`
+
+	// Human-friendly label of the key owner
+	// example: billing-job
+	Name string `json:"name" example:"billing-job"`
+
+	// First characters of the key, safe to display
+	// example: sk_3f9a1c2b
+	Prefix string `json:"prefix" example:"sk_3f9a1c2b"`
+
+	// SHA-256 of the full key, never returned to clients.
+	Hash string `json:"-"`
+
+	// example: ["reports:read"]
+	Scopes []string `json:"scopes" example:"reports:read"`
+
+	// example: 2025-07-01T12:00:00Z
+	CreatedAt time.Time `json:"created_at" example:"2025-07-01T12:00:00Z"`
+
+	// example: 2025-07-02T08:30:00Z
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" example:"2025-07-02T08:30:00Z"`
+
+	// example: 2025-08-01T00:00:00Z
+	RevokedAt *time.Time `json:"revoked_at,omitempty" example:"2025-08-01T00:00:00Z"`
+}
+
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "errors"
+
+// Errors the repositories and usecases report for the delivery layer to map
+// onto responses. They may be wrapped with fmt.Errorf("%w") to add detail.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+)
+
+// ValidationError is an ErrValidation naming what is wrong with each invalid
+// field.
+type ValidationError struct {
+	Message string
+	Fields  map[string]string
+}
+
+func NewValidationError(message string, fields map[string]string) *ValidationError {
+	return &ValidationError{Message: message, Fields: fields}
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType names what happened to a subscription.
+type EventType string
+
+const (
+	EventSubscriptionCreated EventType = "subscription.created"
+	EventSubscriptionUpdated EventType = "subscription.updated"
+	EventSubscriptionDeleted EventType = "subscription.deleted"
+	// EventSubscriptionEndingSoon and EventSubscriptionRenewing are raised
+	// by the reminder scheduler ahead of a subscription's end or next
+	// charge rather than by a change, while its webhook notifier is on.
+	EventSubscriptionEndingSoon EventType = "subscription.ending_soon"
+	EventSubscriptionRenewing   EventType = "subscription.renewing"
+	// EventSubscriptionPriceChanged accompanies the update event of an
+	// update that changed the price.
+	EventSubscriptionPriceChanged EventType = "subscription.price_changed"
+)
+
+// EventTypes lists every event type, in the order documented to clients.
+var EventTypes = []EventType{
+	EventSubscriptionCreated,
+	EventSubscriptionUpdated,
+	EventSubscriptionDeleted,
+	EventSubscriptionEndingSoon,
+	EventSubscriptionPriceChanged,
+	EventSubscriptionRenewing,
+}
+
+func (t EventType) Valid() bool {
+	return slices.Contains(EventTypes, t)
+}
+
+// Event is a change of a subscription, recorded in the outbox in the same
+// transaction as the change itself.
+// swagger:model Event
+type Event struct {
+	// Position in the outbox; later events have greater ids.
+	// example: 42
+	ID int64 `json:"id" example:"42"`
+
+	// example: 00000000-0000-0000-0000-000000000001
+	OrgID uuid.UUID `json:"org_id" example:"00000000-0000-0000-0000-000000000001"`
+
+	// example: subscription.created
+	Type EventType `json:"type" swaggertype:"string" example:"subscription.created"`
+
+	Data EventData `json:"data"`
+
+	// example: 2025-07-01T12:00:00Z
+	CreatedAt time.Time `json:"created_at" example:"2025-07-01T12:00:00Z"`
+}
+
+// swagger:model EventData
+type EventData struct {
+	// The subscription after the change, or as it was before its deletion.
+	Subscription *Subscription `json:"subscription"`
+
+	// Price before the change, for subscription.price_changed
+	// example: 399
+	PreviousPrice *int `json:"previous_price,omitempty" example:"399"`
+
+	// When the subscription renews or ends, for subscription.renewing and
+	// subscription.ending_soon
+	// example: 2025-08-01T00:00:00Z
+	DueOn *time.Time `json:"due_on,omitempty" example:"2025-08-01T00:00:00Z"`
+}
+
+// SubscriptionEvent is an event of type t about sub.
+func SubscriptionEvent(t EventType, sub *Subscription) *Event {
+	c := *sub
+	return &Event{OrgID: sub.OrgID, Type: t, Data: EventData{Subscription: &c}}
+}
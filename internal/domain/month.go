@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+var mmYYYYRe = regexp.MustCompile(`^(0[1-9]|1[0-2])-\d{4}$`)
+
+// ParseMonthYear parses "MM-YYYY" into the first day of that month, UTC.
+func ParseMonthYear(s string) (time.Time, error) {
+	if !mmYYYYRe.MatchString(s) {
+		return time.Time{}, fmt.Errorf("invalid format, expected MM-YYYY")
+	}
+	t, err := time.ParseInLocation("01-2006", s, time.UTC)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+func FormatMonthYear(t time.Time) string {
+	return fmt.Sprintf("%02d-%04d", t.Month(), t.Year())
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// swagger:model Organization
+type Organization struct {
+	// example: 00000000-0000-0000-0000-000000000001
+	ID uuid.UUID `json:"id" example:"00000000-0000-0000-0000-000000000001"`
+
+	// example: Finance department
+	Name string `json:"name" example:"Finance department"`
+
+	// ISO 4217 code used when presenting totals
+	// example: RUB
+	DefaultCurrency string `json:"default_currency" example:"RUB"`
+
+	// example: 2025-07-01T12:00:00Z
+	CreatedAt time.Time `json:"created_at" example:"2025-07-01T12:00:00Z"`
+
+	// example: 2025-07-01T12:00:00Z
+	UpdatedAt time.Time `json:"updated_at" example:"2025-07-01T12:00:00Z"`
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReminderKind says what a reminder announces.
+type ReminderKind string
+
+const (
+	// ReminderRenewal announces that a running subscription is charged
+	// for another month.
+	ReminderRenewal ReminderKind = "renewal"
+	// ReminderEnding announces the end of a subscription's last month.
+	ReminderEnding ReminderKind = "ending"
+)
+
+// Reminder is the record of one notification about a subscription sent
+// through one channel. There is at most one per subscription, kind, due
+// date and channel, which is what keeps it from being sent twice.
+type Reminder struct {
+	ID             uuid.UUID    `json:"id"`
+	OrgID          uuid.UUID    `json:"org_id"`
+	SubscriptionID uuid.UUID    `json:"subscription_id"`
+	Kind           ReminderKind `json:"kind"`
+	// DueOn is the first day of the month the subscription renews for, or
+	// of the first month after its end.
+	DueOn   time.Time `json:"due_on"`
+	Channel string    `json:"channel"`
+	// Attempts counts the sends tried; SentAt is set by the one that
+	// succeeded, Error holds what the last failed one returned.
+	Attempts  int        `json:"attempts"`
+	SentAt    *time.Time `json:"sent_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
@@ -26,6 +26,10 @@ type Subscription struct {
 	// example: 1c9d4f8b-f0f1-4b9a-8f5e-6e9a0b7f8d12
 	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;index" example:"1c9d4f8b-f0f1-4b9a-8f5e-6e9a0b7f8d12"`
 
+	// Owning organization (tenant)
+	// example: 00000000-0000-0000-0000-000000000001
+	OrgID uuid.UUID `json:"org_id" gorm:"type:uuid;index" example:"00000000-0000-0000-0000-000000000001"`
+
 	// Start date (month precision). Rendered в JSON как "MM-YYYY".
 	// example: 07-2025
 	// swagger type: string
@@ -44,30 +48,32 @@ type Subscription struct {
 	UpdatedAt time.Time `json:"updated_at" example:"2025-07-01T12:00:00Z"`
 }
 
-func (s Subscription) MarshalJSON() ([]byte, error) {
-	type aux struct {
-		ID          uuid.UUID `json:"id"`
-		ServiceName string    `json:"service_name"`
-		Price       int       `json:"price"`
-		UserID      uuid.UUID `json:"user_id"`
-		StartDate   string    `json:"start_date"`
-		EndDate     *string   `json:"end_date,omitempty"`
-		CreatedAt   time.Time `json:"created_at"`
-		UpdatedAt   time.Time `json:"updated_at"`
-	}
+type subscriptionJSON struct {
+	ID          uuid.UUID `json:"id"`
+	ServiceName string    `json:"service_name"`
+	Price       int       `json:"price"`
+	UserID      uuid.UUID `json:"user_id"`
+	OrgID       uuid.UUID `json:"org_id"`
+	StartDate   string    `json:"start_date"`
+	EndDate     *string   `json:"end_date,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
 
-	start := fmt.Sprintf("%02d-%04d", s.StartDate.Month(), s.StartDate.Year())
+func (s Subscription) MarshalJSON() ([]byte, error) {
+	start := FormatMonthYear(s.StartDate)
 	var end *string
 	if s.EndDate != nil {
-		t := fmt.Sprintf("%02d-%04d", s.EndDate.Month(), s.EndDate.Year())
+		t := FormatMonthYear(*s.EndDate)
 		end = &t
 	}
 
-	a := aux{
+	a := subscriptionJSON{
 		ID:          s.ID,
 		ServiceName: s.ServiceName,
 		Price:       s.Price,
 		UserID:      s.UserID,
+		OrgID:       s.OrgID,
 		StartDate:   start,
 		EndDate:     end,
 		CreatedAt:   s.CreatedAt,
@@ -76,3 +82,36 @@ func (s Subscription) MarshalJSON() ([]byte, error) {
 
 	return json.Marshal(a)
 }
+
+// UnmarshalJSON reads the format written by MarshalJSON, so exported
+// subscriptions can be read back.
+func (s *Subscription) UnmarshalJSON(data []byte) error {
+	var a subscriptionJSON
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	start, err := ParseMonthYear(a.StartDate)
+	if err != nil {
+		return fmt.Errorf("start_date: %w", err)
+	}
+	var end *time.Time
+	if a.EndDate != nil {
+		t, err := ParseMonthYear(*a.EndDate)
+		if err != nil {
+			return fmt.Errorf("end_date: %w", err)
+		}
+		end = &t
+	}
+	*s = Subscription{
+		ID:          a.ID,
+		ServiceName: a.ServiceName,
+		Price:       a.Price,
+		UserID:      a.UserID,
+		OrgID:       a.OrgID,
+		StartDate:   start,
+		EndDate:     end,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// swagger:model Webhook
+type Webhook struct {
+	// example: 5a0c8d3e-2f4b-4c6d-8e9f-0a1b2c3d4e5f
+	ID uuid.UUID `json:"id" example:"5a0c8d3e-2f4b-4c6d-8e9f-0a1b2c3d4e5f"`
+
+	// example: 00000000-0000-0000-0000-000000000001
+	OrgID uuid.UUID `json:"org_id" example:"00000000-0000-0000-0000-000000000001"`
+
+	// Endpoint the events are POSTed to
+	// example: https://example.com/hooks/subscriptions
+	URL string `json:"url" example:"https://example.com/hooks/subscriptions"`
+
+	// Event types delivered to the endpoint
+	// example: ["subscription.created","subscription.price_changed"]
+	Events []EventType `json:"events" swaggertype:"array,string" example:"subscription.created,subscription.price_changed"`
+
+	// Inactive webhooks receive no new deliveries.
+	// example: true
+	Active bool `json:"active" example:"true"`
+
+	// Key of the HMAC signatures, never returned after creation.
+	Secret string `json:"-"`
+
+	// example: 2025-07-01T12:00:00Z
+	CreatedAt time.Time `json:"created_at" example:"2025-07-01T12:00:00Z"`
+
+	// example: 2025-07-01T12:00:00Z
+	UpdatedAt time.Time `json:"updated_at" example:"2025-07-01T12:00:00Z"`
+}
+
+// Wants reports whether events of type t are delivered to the webhook.
+func (w *Webhook) Wants(t EventType) bool {
+	return w.Active && slices.Contains(w.Events, t)
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryFailed is final: every attempt failed.
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event sent, or still to be sent, to one webhook.
+// swagger:model WebhookDelivery
+type WebhookDelivery struct {
+	// example: 9d2e4f6a-8b0c-4d1e-a3f5-b7c9d1e3f5a7
+	ID uuid.UUID `json:"id" example:"9d2e4f6a-8b0c-4d1e-a3f5-b7c9d1e3f5a7"`
+
+	OrgID uuid.UUID `json:"-"`
+
+	// example: 5a0c8d3e-2f4b-4c6d-8e9f-0a1b2c3d4e5f
+	WebhookID uuid.UUID `json:"webhook_id" example:"5a0c8d3e-2f4b-4c6d-8e9f-0a1b2c3d4e5f"`
+
+	// example: 42
+	EventID int64 `json:"event_id" example:"42"`
+
+	// example: subscription.created
+	EventType EventType `json:"event_type" swaggertype:"string" example:"subscription.created"`
+
+	// Request body, the same on every attempt
+	Payload json.RawMessage `json:"payload" swaggertype:"object"`
+
+	// pending, succeeded or failed
+	// example: succeeded
+	Status DeliveryStatus `json:"status" swaggertype:"string" example:"succeeded"`
+
+	// example: 1
+	Attempts int `json:"attempts" example:"1"`
+
+	// When the next attempt is due, while pending
+	// example: 2025-07-01T12:00:10Z
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" example:"2025-07-01T12:00:10Z"`
+
+	// example: 2025-07-01T12:00:00Z
+	LastAttemptAt *time.Time `json:"last_attempt_at,omitempty" example:"2025-07-01T12:00:00Z"`
+
+	// HTTP status of the last response
+	// example: 200
+	ResponseStatus *int `json:"response_status,omitempty" example:"200"`
+
+	// Start of the last response body
+	// example: ok
+	ResponseBody string `json:"response_body,omitempty" example:"ok"`
+
+	// Why the last attempt failed without a response
+	// example: context deadline exceeded
+	Error string `json:"error,omitempty" example:"context deadline exceeded"`
+
+	// example: 2025-07-01T12:00:00Z
+	CreatedAt time.Time `json:"created_at" example:"2025-07-01T12:00:00Z"`
+}
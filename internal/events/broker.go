@@ -0,0 +1,256 @@
+// Package events streams the subscription events recorded in the outbox to
+// long-lived clients. A Broker polls the log once for all of them and fans
+// new events out; a client resuming after an event id first replays what it
+// missed from the log, so a stream is only as far back as the outbox
+// retention reaches.
+package events
+
+import (
+	"context"
+	"errors"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"subcalc/internal/tenant"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// pageSize bounds the events read from the log at once.
+const pageSize = 100
+
+// ErrClosed is returned by Subscribe once the broker has stopped.
+var ErrClosed = errors.New("event broker closed")
+
+type Options struct {
+	// Interval is the pause between polls of the log.
+	Interval time.Duration
+	// GapTimeout is how long a missing event id holds back the ones after
+	// it. Ids are taken when a transaction writes its events, so a later
+	// id may commit first; a rolled back transaction leaves a hole for
+	// good.
+	GapTimeout time.Duration
+	// Buffer is how many events a stream may fall behind before it is
+	// closed. Its client can resume from the last event it got.
+	Buffer int
+}
+
+// Broker publishes the events of the log to the streams of their tenant.
+// Streams share the events they receive and must not modify them.
+type Broker struct {
+	events repository.EventRepository
+	opts   Options
+	log    *zap.SugaredLogger
+	now    func() time.Time
+
+	// started and ready are set once Run has read where the log ends
+	started bool
+	ready   chan struct{}
+
+	mu       sync.Mutex
+	closed   bool
+	last     int64
+	gapSince time.Time
+	streams  map[*stream]struct{}
+}
+
+type stream struct {
+	orgID uuid.UUID
+	ch    chan *domain.Event
+}
+
+func NewBroker(events repository.EventRepository, opts Options, log *zap.SugaredLogger) *Broker {
+	return &Broker{
+		events:  events,
+		opts:    opts,
+		log:     log,
+		now:     func() time.Time { return time.Now().UTC() },
+		ready:   make(chan struct{}),
+		streams: map[*stream]struct{}{},
+	}
+}
+
+// Run polls the log until ctx is done, then closes every stream.
+func (b *Broker) Run(ctx context.Context) {
+	defer b.close()
+	ticker := time.NewTicker(b.opts.Interval)
+	defer ticker.Stop()
+	for {
+		if err := b.poll(ctx); err != nil && ctx.Err() == nil {
+			b.log.Errorf("events: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Subscribe streams the events of the tenant of ctx that match: first those
+// after afterID still in the log, then new ones as they are recorded. An
+// afterID of 0 skips the replay. The channel is closed when ctx is done,
+// the broker stops or the stream falls behind.
+func (b *Broker) Subscribe(ctx context.Context, afterID int64, match func(*domain.Event) bool) (<-chan *domain.Event, error) {
+	select {
+	case <-b.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	s := &stream{orgID: tenant.OrgID(ctx), ch: make(chan *domain.Event, b.opts.Buffer)}
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, ErrClosed
+	}
+	// events up to live are replayed from the log, later ones arrive on
+	// s.ch
+	live := b.last
+	b.streams[s] = struct{}{}
+	b.mu.Unlock()
+
+	out := make(chan *domain.Event)
+	go func() {
+		defer close(out)
+		defer b.remove(s)
+		send := func(e *domain.Event) bool {
+			if !match(e) {
+				return true
+			}
+			select {
+			case out <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		if afterID > 0 && !b.replay(ctx, afterID, live, send) {
+			return
+		}
+		for {
+			select {
+			case e, ok := <-s.ch:
+				if !ok || !send(e) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// replay sends the events of the log in (afterID, live], reporting whether
+// the stream may go on.
+func (b *Broker) replay(ctx context.Context, afterID, live int64, send func(*domain.Event) bool) bool {
+	for cursor := afterID; cursor < live; {
+		page, err := b.events.After(ctx, cursor, pageSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				b.log.Errorf("events: replay: %v", err)
+			}
+			return false
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, e := range page {
+			if e.ID > live {
+				return true
+			}
+			if !send(e) {
+				return false
+			}
+			cursor = e.ID
+		}
+	}
+	return true
+}
+
+// poll publishes the events recorded since the last poll, in id order.
+func (b *Broker) poll(ctx context.Context) error {
+	if !b.started {
+		last, err := b.events.LastID(ctx)
+		if err != nil {
+			return err
+		}
+		b.mu.Lock()
+		b.last = last
+		b.mu.Unlock()
+		b.started = true
+		close(b.ready)
+	}
+	for {
+		b.mu.Lock()
+		last := b.last
+		b.mu.Unlock()
+		page, err := b.events.AllAfter(ctx, last, pageSize)
+		if err != nil {
+			return err
+		}
+		if !b.publish(page) || len(page) < pageSize {
+			return nil
+		}
+	}
+}
+
+// publish hands the events to their streams until it meets a gap that is
+// not yet GapTimeout old, reporting whether it got through them all.
+func (b *Broker) publish(page []*domain.Event) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	for _, e := range page {
+		if e.ID != b.last+1 {
+			if b.gapSince.IsZero() {
+				b.gapSince = now
+			}
+			if now.Sub(b.gapSince) < b.opts.GapTimeout {
+				return false
+			}
+		}
+		b.gapSince = time.Time{}
+		b.last = e.ID
+		for s := range b.streams {
+			if s.orgID != e.OrgID {
+				continue
+			}
+			select {
+			case s.ch <- e:
+			default:
+				b.log.Warnf("events: dropping a stream %d events behind", len(s.ch))
+				delete(b.streams, s)
+				close(s.ch)
+			}
+		}
+	}
+	return true
+}
+
+func (b *Broker) remove(s *stream) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.streams[s]; ok {
+		delete(b.streams, s)
+		close(s.ch)
+	}
+}
+
+func (b *Broker) close() {
+	if !b.started {
+		b.started = true
+		close(b.ready)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for s := range b.streams {
+		delete(b.streams, s)
+		close(s.ch)
+	}
+}
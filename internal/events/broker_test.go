@@ -0,0 +1,219 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	memrepo "subcalc/internal/repository/memory"
+	"subcalc/internal/tenant"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func all(*domain.Event) bool { return true }
+
+func newMemoryBroker(t *testing.T, opts Options) (*Broker, repository.SubscriptionRepository) {
+	t.Helper()
+	store := memrepo.NewStore()
+	b := NewBroker(memrepo.NewMemoryEventRepo(store), opts, zap.NewNop().Sugar())
+	if err := b.poll(context.Background()); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	return b, memrepo.NewMemorySubscriptionRepo(store)
+}
+
+func createSub(t *testing.T, ctx context.Context, repo repository.SubscriptionRepository, name string) *domain.Subscription {
+	t.Helper()
+	sub := &domain.Subscription{ServiceName: name, Price: 100, UserID: uuid.New(), StartDate: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)}
+	if err := repo.Create(ctx, sub); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return sub
+}
+
+func recv(t *testing.T, ch <-chan *domain.Event) *domain.Event {
+	t.Helper()
+	select {
+	case e, ok := <-ch:
+		if !ok {
+			t.Fatalf("stream closed")
+		}
+		return e
+	case <-time.After(time.Second):
+		t.Fatalf("no event")
+	}
+	return nil
+}
+
+func TestBroker_StreamsNewEventsOfTheTenant(t *testing.T) {
+	b, repo := newMemoryBroker(t, Options{Buffer: 10})
+	ctx, cancel := context.WithCancel(context.Background())
+	other := tenant.WithOrganization(ctx, &domain.Organization{ID: uuid.New()})
+
+	createSub(t, ctx, repo, "before")
+	if err := b.poll(ctx); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	ch, err := b.Subscribe(ctx, 0, all)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	createSub(t, other, repo, "theirs")
+	ours := createSub(t, ctx, repo, "ours")
+	if err := b.poll(ctx); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+
+	if e := recv(t, ch); e.Type != domain.EventSubscriptionCreated || e.Data.Subscription.ID != ours.ID {
+		t.Fatalf("want the tenant's new event only, got %+v", e)
+	}
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Fatalf("stream must close with its context")
+	}
+}
+
+func TestBroker_ReplaysFromTheLog(t *testing.T) {
+	b, repo := newMemoryBroker(t, Options{Buffer: 10})
+	ctx := context.Background()
+
+	first := createSub(t, ctx, repo, "first")
+	second := createSub(t, ctx, repo, "second")
+	if err := b.poll(ctx); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+
+	ch, err := b.Subscribe(ctx, 1, func(e *domain.Event) bool { return e.Data.Subscription.ID != first.ID })
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if e := recv(t, ch); e.ID != 2 || e.Data.Subscription.ID != second.ID {
+		t.Fatalf("want event 2 replayed, got %+v", e)
+	}
+	third := createSub(t, ctx, repo, "third")
+	if err := b.poll(ctx); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if e := recv(t, ch); e.ID != 3 || e.Data.Subscription.ID != third.ID {
+		t.Fatalf("want event 3 live after the replay, got %+v", e)
+	}
+}
+
+func TestBroker_ClosesLaggingStreams(t *testing.T) {
+	b, repo := newMemoryBroker(t, Options{Buffer: 1})
+	ctx := context.Background()
+
+	ch, err := b.Subscribe(ctx, 0, all)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		createSub(t, ctx, repo, name)
+	}
+	if err := b.poll(ctx); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	// what was handed over before the stream fell behind still arrives
+	var ids []int64
+	for e := range ch {
+		ids = append(ids, e.ID)
+	}
+	if len(ids) == 0 || len(ids) > 2 || ids[0] != 1 {
+		t.Fatalf("want the first events, then the end of the stream, got %v", ids)
+	}
+}
+
+type fakeLog struct {
+	events []*domain.Event
+}
+
+func (f *fakeLog) After(ctx context.Context, afterID int64, limit int) ([]*domain.Event, error) {
+	return f.AllAfter(ctx, afterID, limit)
+}
+func (f *fakeLog) AllAfter(ctx context.Context, afterID int64, limit int) ([]*domain.Event, error) {
+	var out []*domain.Event
+	for _, e := range f.events {
+		if e.ID > afterID && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+func (f *fakeLog) LastID(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func TestBroker_WaitsForMissingIDs(t *testing.T) {
+	sub := &domain.Subscription{ID: uuid.New()}
+	event := func(id int64) *domain.Event {
+		return &domain.Event{ID: id, OrgID: tenant.DefaultOrgID, Type: domain.EventSubscriptionUpdated, Data: domain.EventData{Subscription: sub}}
+	}
+	log := &fakeLog{}
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBroker(log, Options{GapTimeout: 5 * time.Second, Buffer: 10}, zap.NewNop().Sugar())
+	b.now = func() time.Time { return now }
+	ctx := context.Background()
+	_ = b.poll(ctx)
+
+	ch, err := b.Subscribe(ctx, 0, all)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	log.events = []*domain.Event{event(1), event(3)}
+	_ = b.poll(ctx)
+	if e := recv(t, ch); e.ID != 1 {
+		t.Fatalf("want event 1, got %d", e.ID)
+	}
+
+	// event 2 commits late
+	now = now.Add(time.Second)
+	log.events = []*domain.Event{event(1), event(2), event(3)}
+	_ = b.poll(ctx)
+	if e := recv(t, ch); e.ID != 2 {
+		t.Fatalf("want event 2 before 3, got %d", e.ID)
+	}
+	if e := recv(t, ch); e.ID != 3 {
+		t.Fatalf("want event 3, got %d", e.ID)
+	}
+
+	// event 5 is held back until 4 is given up on
+	log.events = append(log.events, event(5))
+	_ = b.poll(ctx)
+	select {
+	case e := <-ch:
+		t.Fatalf("event %d published across a fresh gap", e.ID)
+	case <-time.After(10 * time.Millisecond):
+	}
+	now = now.Add(5 * time.Second)
+	_ = b.poll(ctx)
+	if e := recv(t, ch); e.ID != 5 {
+		t.Fatalf("want event 5 after the gap timeout, got %d", e.ID)
+	}
+}
+
+func TestBroker_StopsWithRun(t *testing.T) {
+	b := NewBroker(&fakeLog{}, Options{Interval: time.Millisecond, Buffer: 10}, zap.NewNop().Sugar())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		b.Run(ctx)
+		close(done)
+	}()
+
+	ch, err := b.Subscribe(context.Background(), 0, all)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	cancel()
+	<-done
+	if _, ok := <-ch; ok {
+		t.Fatalf("stream must close when the broker stops")
+	}
+	if _, err := b.Subscribe(context.Background(), 0, all); !errors.Is(err, ErrClosed) {
+		t.Fatalf("want ErrClosed, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check returns nil when the dependency is usable.
+type Check func(ctx context.Context) error
+
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Checker runs readiness checks. Once shutdown has started it reports not
+// ready so load balancers drain the instance before it stops serving.
+type Checker struct {
+	timeout      time.Duration
+	mu           sync.RWMutex
+	checks       map[string]Check
+	shuttingDown atomic.Bool
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout, checks: make(map[string]Check)}
+}
+
+func (h *Checker) Add(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[name] = check
+}
+
+func (h *Checker) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Ready runs all checks concurrently, each bounded by the checker timeout.
+func (h *Checker) Ready(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := make(map[string]Check, len(h.checks))
+	for name, c := range h.checks {
+		checks[name] = c
+	}
+	h.mu.RUnlock()
+
+	rep := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks)+1)}
+	if h.shuttingDown.Load() {
+		rep.Status = StatusFail
+		rep.Checks["shutdown"] = CheckResult{Status: StatusFail, Error: "shutting down"}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cctx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check(cctx)
+			res := CheckResult{Status: StatusOK, LatencyMs: float64(time.Since(start).Microseconds()) / 1000}
+			if err != nil {
+				res.Status = StatusFail
+				res.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			rep.Checks[name] = res
+			if err != nil {
+				rep.Status = StatusFail
+			}
+		}()
+	}
+	wg.Wait()
+	return rep
+}
@@ -0,0 +1,40 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestChecker_ReportsPerCheckStatus(t *testing.T) {
+	h := NewChecker(50 * time.Millisecond)
+	h.Add("db", func(ctx context.Context) error { return nil })
+	h.Add("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	rep := h.Ready(context.Background())
+	if rep.Status != StatusFail {
+		t.Fatalf("expected fail, got %s", rep.Status)
+	}
+	if rep.Checks["db"].Status != StatusOK {
+		t.Fatalf("db check should pass: %+v", rep.Checks["db"])
+	}
+	if slow := rep.Checks["slow"]; slow.Status != StatusFail || slow.Error != context.DeadlineExceeded.Error() {
+		t.Fatalf("slow check should time out: %+v", slow)
+	}
+}
+
+func TestChecker_FailsDuringShutdown(t *testing.T) {
+	h := NewChecker(time.Second)
+	h.Add("db", func(ctx context.Context) error { return nil })
+
+	if rep := h.Ready(context.Background()); rep.Status != StatusOK {
+		t.Fatalf("expected ok before shutdown, got %+v", rep)
+	}
+	h.SetShuttingDown()
+	if rep := h.Ready(context.Background()); rep.Status != StatusFail {
+		t.Fatalf("expected fail during shutdown, got %+v", rep)
+	}
+}
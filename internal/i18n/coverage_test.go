@@ -0,0 +1,129 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// verb matches the formatting verbs of the messages built with fmt.
+var verb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z]`)
+
+// TestCatalogCoversMessages finds the messages and field problems the API
+// is written to return and checks that the Russian catalog knows each of
+// them, so that rewording one does not silently drop its translation.
+func TestCatalogCoversMessages(t *testing.T) {
+	fset := token.NewFileSet()
+	found := 0
+	check := func(pos token.Pos, e ast.Expr) {
+		msg, ok := literalMessage(e)
+		if !ok {
+			return
+		}
+		found++
+		if _, ok := catalogs[Russian].translate(msg); !ok {
+			t.Errorf("%s: %q has no translation", fset.Position(pos), msg)
+		}
+	}
+	checkFields := func(e ast.Expr) {
+		if lit, ok := e.(*ast.CompositeLit); ok {
+			for _, elt := range lit.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					check(kv.Value.Pos(), kv.Value)
+				}
+			}
+		}
+	}
+
+	root := filepath.Join("..", "..", "internal")
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.CallExpr:
+				switch name := funcName(n.Fun); {
+				case name == "RespondError" && len(n.Args) == 5:
+					check(n.Args[3].Pos(), n.Args[3])
+					checkFields(n.Args[4])
+				case name == "fail" && len(n.Args) == 2:
+					check(n.Args[1].Pos(), n.Args[1])
+				case name == "NewValidationError" && len(n.Args) == 2:
+					check(n.Args[0].Pos(), n.Args[0])
+					checkFields(n.Args[1])
+				case name == "Errorf" && len(n.Args) >= 2 && funcName(n.Args[1]) == "ErrForbidden":
+					// the detail of a forbidden error is its message
+					if lit, ok := n.Args[0].(*ast.BasicLit); ok {
+						format, _ := strconv.Unquote(lit.Value)
+						if rest, ok := strings.CutPrefix(format, "%w: "); ok {
+							check(lit.Pos(), &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(rest)})
+						}
+					}
+				}
+			case *ast.AssignStmt:
+				// fields["name"] = "problem"
+				for i, lhs := range n.Lhs {
+					if ix, ok := lhs.(*ast.IndexExpr); ok && funcName(ix.X) == "fields" && i < len(n.Rhs) {
+						check(n.Rhs[i].Pos(), n.Rhs[i])
+					}
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found < 50 {
+		t.Fatalf("found only %d messages; did the call sites move?", found)
+	}
+}
+
+func funcName(e ast.Expr) string {
+	switch e := e.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	}
+	return ""
+}
+
+// literalMessage is the message e builds, with a sample value for each
+// part filled in at run time. Messages made up entirely at run time, such
+// as the text of a wrapped error, are not the catalog's to translate.
+func literalMessage(e ast.Expr) (string, bool) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		return s, err == nil
+	case *ast.BinaryExpr:
+		// "invalid scope " + s
+		if s, ok := literalMessage(e.X); ok && e.Op == token.ADD {
+			return s + "x", true
+		}
+	case *ast.CallExpr:
+		// fmt.Sprintf("unknown event type %q", e)
+		if funcName(e.Fun) == "Sprintf" && len(e.Args) > 0 {
+			if s, ok := literalMessage(e.Args[0]); ok {
+				return verb.ReplaceAllString(s, "1"), true
+			}
+		}
+	}
+	return "", false
+}
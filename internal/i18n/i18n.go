@@ -0,0 +1,132 @@
+// Package i18n translates the messages of the API. English is the language
+// the messages are written in; the catalog maps them to the other languages
+// and, for messages it lacks, falls back to a message per error code.
+package i18n
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Lang string
+
+const (
+	English Lang = "en"
+	Russian Lang = "ru"
+)
+
+// Default is the language of clients that ask for none we support.
+const Default = English
+
+// Negotiate picks the supported language an Accept-Language header prefers,
+// such as "ru-RU,ru;q=0.9,en;q=0.8". Regions are ignored.
+func Negotiate(acceptLanguage string) Lang {
+	best, bestQ := Default, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = f
+		}
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		lang := Lang(primary)
+		if primary == "*" {
+			lang = Default
+		} else if _, ok := catalogs[lang]; !ok && lang != English {
+			continue
+		}
+		if q > bestQ {
+			best, bestQ = lang, q
+		}
+	}
+	return best
+}
+
+// Message translates msg, the message of an error with code. Messages the
+// catalog does not know are replaced by the message for code, so that
+// clients never get a mix of languages in one message.
+func Message(lang Lang, code, msg string) string {
+	c, ok := catalogs[lang]
+	if !ok {
+		return msg
+	}
+	if s, ok := c.translate(msg); ok {
+		return s
+	}
+	if s, ok := c.codes[code]; ok {
+		return s
+	}
+	return msg
+}
+
+// Title is the summary of the error code, or def when lang has none.
+func Title(lang Lang, code, def string) string {
+	if c, ok := catalogs[lang]; ok {
+		if s, ok := c.codes[code]; ok {
+			return s
+		}
+	}
+	return def
+}
+
+// Reason translates a per-field problem such as "expected MM-YYYY",
+// keeping it as is when the catalog does not know it.
+func Reason(lang Lang, reason string) string {
+	if s, ok := catalogs[lang].translate(reason); ok {
+		return s
+	}
+	return reason
+}
+
+// catalog is the translations into one language.
+type catalog struct {
+	// codes are the messages of error codes
+	codes map[string]string
+	// phrases translate the English messages and field problems; "{}"
+	// stands for a value that is carried over. A test checks that every
+	// message written at a call site has one.
+	phrases []phrase
+}
+
+type phrase struct {
+	en *regexp.Regexp
+	to []string
+}
+
+func newCatalog(codes map[string]string, phrases [][2]string) *catalog {
+	c := &catalog{codes: codes}
+	for _, p := range phrases {
+		re := strings.ReplaceAll(regexp.QuoteMeta(p[0]), `\{\}`, `(.+?)`)
+		c.phrases = append(c.phrases, phrase{
+			en: regexp.MustCompile("^" + re + "$"),
+			to: strings.Split(p[1], "{}"),
+		})
+	}
+	return c
+}
+
+func (c *catalog) translate(s string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	for _, p := range c.phrases {
+		m := p.en.FindStringSubmatch(s)
+		if m == nil || len(m)-1 != len(p.to)-1 {
+			continue
+		}
+		var b strings.Builder
+		for i, lit := range p.to {
+			b.WriteString(lit)
+			if i+1 < len(p.to) {
+				b.WriteString(m[i+1])
+			}
+		}
+		return b.String(), true
+	}
+	return "", false
+}
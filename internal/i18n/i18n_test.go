@@ -0,0 +1,62 @@
+package i18n
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]Lang{
+		"":                        English,
+		"ru":                      Russian,
+		"ru-RU,ru;q=0.9,en;q=0.8": Russian,
+		"en-US,en;q=0.9,ru;q=0.8": English,
+		"de-DE,ru;q=0.5":          Russian,
+		"de":                      English,
+		"*":                       English,
+		"ru;q=0,en;q=0.1":         English,
+		"RU":                      Russian,
+		"ru;q=oops,en":            English,
+	}
+	for header, want := range cases {
+		if got := Negotiate(header); got != want {
+			t.Errorf("Negotiate(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	cases := []struct {
+		lang      Lang
+		code, msg string
+		want      string
+	}{
+		{English, "not_found", "not found", "not found"},
+		{Russian, "not_found", "not found", "не найдено"},
+		{Russian, "forbidden", "requires the apikeys:manage permission", "требуется право apikeys:manage"},
+		// unknown messages fall back to the message of their code
+		{Russian, "internal_error", "purge failed", "внутренняя ошибка сервера"},
+		{Russian, "teapot", "short and stout", "short and stout"},
+	}
+	for _, c := range cases {
+		if got := Message(c.lang, c.code, c.msg); got != c.want {
+			t.Errorf("Message(%s, %s, %q) = %q, want %q", c.lang, c.code, c.msg, got, c.want)
+		}
+	}
+}
+
+func TestReason(t *testing.T) {
+	cases := []struct {
+		lang         Lang
+		reason, want string
+	}{
+		{Russian, "expected MM-YYYY", "ожидается ММ-ГГГГ"},
+		{Russian, "expected an integer from 1 to 1000", "ожидается целое число от 1 до 1000"},
+		{Russian, `invalid uuid "x"`, `некорректный UUID "x"`},
+		{Russian, "invalid uuid: x", "некорректный UUID: x"},
+		{Russian, "Key: 'Price' Error:Field validation", "Key: 'Price' Error:Field validation"},
+		{English, "expected MM-YYYY", "expected MM-YYYY"},
+	}
+	for _, c := range cases {
+		if got := Reason(c.lang, c.reason); got != c.want {
+			t.Errorf("Reason(%s, %q) = %q, want %q", c.lang, c.reason, got, c.want)
+		}
+	}
+}
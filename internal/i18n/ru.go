@@ -0,0 +1,81 @@
+package i18n
+
+var catalogs = map[Lang]*catalog{
+	Russian: newCatalog(map[string]string{
+		"invalid_payload":        "некорректное тело запроса",
+		"invalid_field":          "некорректное значение поля",
+		"invalid_query":          "некорректные параметры запроса",
+		"unauthorized":           "требуется аутентификация",
+		"forbidden":              "недостаточно прав",
+		"not_found":              "не найдено",
+		"conflict":               "конфликт с существующим ресурсом",
+		"rate_limited":           "слишком много запросов",
+		"unsupported_media_type": "неподдерживаемый тип содержимого",
+		"internal_error":         "внутренняя ошибка сервера",
+	}, [][2]string{
+		// messages
+		{"invalid request body", "некорректное тело запроса"},
+		{"invalid query parameters", "некорректные параметры запроса"},
+		{"invalid id", "некорректный идентификатор"},
+		{"user_id must be a UUID", "user_id должен быть UUID"},
+		{"ids must be UUIDs", "ids должны быть UUID"},
+		{"X-Org-Id must be a UUID", "X-Org-Id должен быть UUID"},
+		{"start_date must be in format MM-YYYY", "start_date должна быть в формате ММ-ГГГГ"},
+		{"end_date must be in format MM-YYYY", "end_date должна быть в формате ММ-ГГГГ"},
+		{"end_date must be equal or after start_date", "end_date должна быть не раньше start_date"},
+		{"service_name is required and must be <=255 chars", "service_name обязателен и должен быть не длиннее 255 символов"},
+		{"price must be >= 0", "price должна быть >= 0"},
+		{"invalid organization settings", "некорректные настройки организации"},
+		{"at least one scope required", "нужна хотя бы одна область доступа"},
+		{"invalid scope {}", "некорректная область доступа {}"},
+		{"not found", "не найдено"},
+		{"organization not found", "организация не найдена"},
+		{"conflicts with an existing resource", "конфликт с существующим ресурсом"},
+		{"insufficient permissions", "недостаточно прав"},
+		{"requires the {} permission", "требуется право {}"},
+		{"cannot create subscriptions for another user", "нельзя создавать подписки другого пользователя"},
+		{"cannot move subscriptions to another user", "нельзя передавать подписки другому пользователю"},
+		{"credentials are not valid for this organization", "учётные данные недействительны для этой организации"},
+		{"missing bearer token", "отсутствует bearer-токен"},
+		{"invalid token", "недействительный токен"},
+		{"invalid api key", "недействительный API-ключ"},
+		{"authentication failed", "ошибка аутентификации"},
+		{"resolve organization failed", "не удалось определить организацию"},
+		{"too many requests", "слишком много запросов"},
+		{"expected a merge patch or a JSON patch", "ожидается merge patch или JSON patch"},
+		{"invalid patch", "некорректный патч"},
+		{"test operation failed", "операция test не выполнена"},
+		{"read-only fields cannot be changed", "поля только для чтения нельзя изменить"},
+		{"invalid webhook", "некорректный вебхук"},
+		{"invalid Last-Event-ID", "некорректный Last-Event-ID"},
+
+		// field problems
+		{"required", "обязательное поле"},
+		{"required, max 255 chars", "обязательное поле, не длиннее 255 символов"},
+		{"invalid", "некорректное значение"},
+		{"invalid uuid", "некорректный UUID"},
+		{"invalid uuid: {}", "некорректный UUID: {}"},
+		{"invalid uuid {}", "некорректный UUID {}"},
+		{"expected MM-YYYY", "ожидается ММ-ГГГГ"},
+		{"must be >= start_date", "должна быть не раньше start_date"},
+		{"must be >= 0", "должно быть >= 0"},
+		{"must be <= to", "должна быть не позже to"},
+		{"must be <= price_max", "должна быть <= price_max"},
+		{"expected a 3-letter ISO 4217 code", "ожидается трёхбуквенный код ISO 4217"},
+		{"unknown scope {}", "неизвестная область доступа {}"},
+		{"unknown sort key {}", "неизвестный ключ сортировки {}"},
+		{"not allowed with cursor", "нельзя указывать вместе с cursor"},
+		{"not allowed with cursor, which pages in the default order", "нельзя указывать вместе с cursor, который листает в порядке по умолчанию"},
+		{"at most 100 characters", "не длиннее 100 символов"},
+		{"expected an integer >= {}", "ожидается целое число >= {}"},
+		{"expected an integer from {} to {}", "ожидается целое число от {} до {}"},
+		{"expected true or false", "ожидается true или false"},
+		{"expected an RFC 3339 timestamp", "ожидается время в формате RFC 3339"},
+		{"unknown parameter", "неизвестный параметр"},
+		{"read-only", "только для чтения"},
+		{"expected an absolute http or https URL", "ожидается абсолютный URL http или https"},
+		{"must not point to a local or private address", "не должен указывать на локальный или частный адрес"},
+		{"at least one event type required", "нужен хотя бы один тип событий"},
+		{"unknown event type {}", "неизвестный тип событий {}"},
+	}),
+}
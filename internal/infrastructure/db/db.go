@@ -0,0 +1,39 @@
+package db
+
+import (
+	"fmt"
+	"subcalc/internal/config"
+	"subcalc/internal/migrate"
+	"subcalc/migrations"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Open connects to the database selected by cfg.DBDriver.
+func Open(cfg *config.Config, logger *zap.SugaredLogger) (*gorm.DB, error) {
+	switch cfg.DBDriver {
+	case config.DriverSQLite:
+		return NewSQLite(cfg, logger)
+	case config.DriverPostgres:
+		return NewPostgres(cfg, logger)
+	}
+	return nil, fmt.Errorf("unknown db driver %q", cfg.DBDriver)
+}
+
+// NewMigrator returns a migrator with the migrations written for the
+// database's driver.
+func NewMigrator(db *gorm.DB, logger *zap.SugaredLogger) (*migrate.Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if IsSQLite(db) {
+		return migrate.New(sqlDB, migrate.SQLite, migrations.SQLite, logger)
+	}
+	return migrate.New(sqlDB, migrate.Postgres, migrations.FS, logger)
+}
+
+func IsSQLite(db *gorm.DB) bool {
+	return db.Dialector.Name() == "sqlite"
+}
@@ -3,7 +3,6 @@ package db
 import (
 	"fmt"
 	"subcalc/internal/config"
-	"subcalc/internal/domain"
 	"time"
 
 	"go.uber.org/zap"
@@ -30,7 +29,3 @@ func NewPostgres(cfg *config.Config, logger *zap.SugaredLogger) (*gorm.DB, error
 	logger.Infof("connected to postgres: %s:%d/%s", cfg.DBHost, cfg.DBPort, cfg.DBName)
 	return db, nil
 }
-
-func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(&domain.Subscription{})
-}
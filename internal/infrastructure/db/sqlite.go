@@ -0,0 +1,28 @@
+package db
+
+import (
+	"subcalc/internal/config"
+
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// NewSQLite opens the database file at cfg.DBPath with a pure-Go driver, so
+// CGO_ENABLED=0 builds keep working.
+func NewSQLite(cfg *config.Config, logger *zap.SugaredLogger) (*gorm.DB, error) {
+	dsn := cfg.DBPath + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows one writer at a time; a single connection queues writes
+	// in the pool instead of failing them with SQLITE_BUSY
+	sqlDB.SetMaxOpenConns(1)
+	logger.Infof("opened sqlite database %s", cfg.DBPath)
+	return db, nil
+}
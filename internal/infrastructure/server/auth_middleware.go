@@ -0,0 +1,72 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"subcalc/internal/auth"
+	"subcalc/internal/delivery/handlers"
+	"subcalc/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// APIKeyAuth authenticates requests carrying an X-API-Key header. Requests
+// without the header are left to the next auth middleware.
+func APIKeyAuth(keys usecase.APIKeyUsecase, log *zap.SugaredLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := c.GetHeader("X-API-Key")
+		if raw == "" {
+			c.Next()
+			return
+		}
+
+		p, err := keys.Authenticate(c.Request.Context(), raw)
+		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidAPIKey) {
+				handlers.RespondError(c, http.StatusUnauthorized, "unauthorized", "invalid api key", nil)
+			} else {
+				log.Errorf("api key lookup failed: %v", err)
+				handlers.RespondError(c, http.StatusInternalServerError, "internal_error", "authentication failed", nil)
+			}
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+		c.Next()
+	}
+}
+
+// JWTAuth requires a valid "Authorization: Bearer <token>" header and stores
+// the resulting principal in the request context. Requests already
+// authenticated by an earlier middleware are passed through.
+func JWTAuth(secret string, log *zap.SugaredLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := auth.FromContext(c.Request.Context()); ok {
+			c.Next()
+			return
+		}
+
+		header := c.GetHeader("Authorization")
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || token == "" {
+			handlers.RespondError(c, http.StatusUnauthorized, "unauthorized", "missing bearer token", nil)
+			c.Abort()
+			return
+		}
+
+		p, err := auth.ParseToken(secret, token)
+		if err != nil {
+			log.Warnf("rejected token: %v", err)
+			handlers.RespondError(c, http.StatusUnauthorized, "unauthorized", "invalid token", nil)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+		c.Next()
+	}
+}
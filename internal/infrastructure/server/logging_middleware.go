@@ -7,10 +7,15 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
-func ZapRequestLogger(logger *zap.Logger) gin.HandlerFunc {
+// RequestObserver receives what ZapRequestLogger measured for a finished
+// request. route is the gin FullPath, empty when no route matched.
+type RequestObserver func(method, route string, status int, latency time.Duration)
+
+func ZapRequestLogger(logger *zap.Logger, observers ...RequestObserver) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 
@@ -25,12 +30,19 @@ func ZapRequestLogger(logger *zap.Logger) gin.HandlerFunc {
 			fullPath = c.Request.URL.Path
 		}
 
-		reqLogger := logger.With(
+		fields := []zap.Field{
 			zap.String("request_id", reqID),
 			zap.String("http_method", c.Request.Method),
 			zap.String("http_path", fullPath),
 			zap.String("remote_addr", c.ClientIP()),
-		)
+		}
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			fields = append(fields,
+				zap.String("trace_id", sc.TraceID().String()),
+				zap.String("span_id", sc.SpanID().String()),
+			)
+		}
+		reqLogger := logger.With(fields...)
 
 		c.Set("logger", reqLogger)
 		c.Request = c.Request.WithContext(loggerpkg.WithLogger(c.Request.Context(), reqLogger))
@@ -44,6 +56,10 @@ func ZapRequestLogger(logger *zap.Logger) gin.HandlerFunc {
 			size = 0
 		}
 
+		for _, observe := range observers {
+			observe(c.Request.Method, c.FullPath(), status, latency)
+		}
+
 		reqLogger.Info("request finished",
 			zap.Int("status", status),
 			zap.Duration("latency", latency),
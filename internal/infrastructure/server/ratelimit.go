@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"subcalc/internal/auth"
+	"subcalc/internal/delivery/handlers"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Limit describes a token bucket: Burst tokens at most, refilled at Rate
+// tokens per second.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitGroup applies Limit to every route whose path starts with
+// PathPrefix. Each group has its own buckets; groups of the same Name share
+// them.
+type RateLimitGroup struct {
+	Name       string
+	PathPrefix string
+	Limit      Limit
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+}
+
+// RateLimitStore keeps bucket state. The in-memory store is per process;
+// a shared implementation (e.g. Redis) can replace it for multi-replica
+// deployments.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit Limit) (RateLimitResult, error)
+}
+
+// RateLimit rejects requests over their group's quota with 429 and reports
+// quota state in RateLimit-* headers. Groups are matched in order, requests
+// matching none use def.
+//
+// It runs after authentication, so that callers are told apart by API key
+// or user rather than by address. Requests that authentication rejects are
+// therefore not limited; only requests let through without a principal,
+// when authentication is disabled, are keyed by client IP.
+func RateLimit(store RateLimitStore, def Limit, groups []RateLimitGroup, log *zap.SugaredLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.FullPath()
+		if path == "" {
+			path = c.Request.URL.Path
+		}
+		group, limit := "default", def
+		for _, g := range groups {
+			if strings.HasPrefix(path, g.PathPrefix) {
+				group, limit = g.Name, g.Limit
+				break
+			}
+		}
+
+		res, err := store.Take(c.Request.Context(), group+"|"+rateLimitKey(c), limit)
+		if err != nil {
+			// fail open: a broken limiter must not take the API down
+			log.Errorf("rate limit store failed: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+		if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			handlers.RespondError(c, http.StatusTooManyRequests, "rate_limited", "too many requests", nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitKey identifies the caller: API key first, then the authenticated
+// user, then the client IP.
+func rateLimitKey(c *gin.Context) string {
+	if p, ok := auth.FromContext(c.Request.Context()); ok {
+		if p.APIKeyID != nil {
+			return "key:" + p.APIKeyID.String()
+		}
+		return "user:" + p.UserID.String()
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+func (b *bucket) refill(now time.Time) {
+	b.tokens = math.Min(float64(b.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate)
+	b.last = now
+}
+
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// sweepInterval bounds how often idle buckets are dropped.
+const sweepInterval = time.Minute
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, limit Limit) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	burst := float64(limit.Burst)
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now, limit: limit}
+		s.buckets[key] = b
+	} else {
+		b.limit = limit
+		b.refill(now)
+	}
+
+	res := RateLimitResult{}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = secondsToDuration((1 - b.tokens) / limit.Rate)
+	}
+	res.Remaining = int(math.Floor(b.tokens))
+	res.Reset = secondsToDuration((burst - b.tokens) / limit.Rate)
+	return res, nil
+}
+
+// sweep drops buckets that have refilled completely; recreating them later
+// yields the same state.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	for k, b := range s.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(s.buckets, k)
+		}
+	}
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
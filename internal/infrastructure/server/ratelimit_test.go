@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestMemoryRateLimitStore_RefillsOverTime(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryRateLimitStore()
+	s.now = func() time.Time { return now }
+	limit := Limit{Rate: 1, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		if res, _ := s.Take(context.Background(), "k", limit); !res.Allowed {
+			t.Fatalf("request %d should be allowed", i)
+		}
+	}
+	res, _ := s.Take(context.Background(), "k", limit)
+	if res.Allowed {
+		t.Fatalf("third request should be limited")
+	}
+	if res.RetryAfter != time.Second {
+		t.Fatalf("expected retry after 1s, got %s", res.RetryAfter)
+	}
+
+	now = now.Add(time.Second)
+	if res, _ := s.Take(context.Background(), "k", limit); !res.Allowed {
+		t.Fatalf("bucket should have refilled one token")
+	}
+	if res, _ := s.Take(context.Background(), "other", limit); !res.Allowed {
+		t.Fatalf("keys must not share buckets")
+	}
+}
+
+func TestRateLimit_GroupsAndHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	groups := []RateLimitGroup{
+		{Name: "aggregate", PathPrefix: "/api/subscriptions/sum", Limit: Limit{Rate: 1, Burst: 1}},
+		{Name: "aggregate", PathPrefix: "/api/subscriptions/forecast", Limit: Limit{Rate: 1, Burst: 1}},
+	}
+	r.Use(RateLimit(NewMemoryRateLimitStore(), Limit{Rate: 10, Burst: 10}, groups, zap.NewNop().Sugar()))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/api/subscriptions/sum", ok)
+	r.GET("/api/subscriptions/forecast", ok)
+	r.GET("/api/subscriptions/:id", ok)
+
+	do := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	if w := do("/api/subscriptions/sum"); w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "1" {
+		t.Fatalf("unexpected first sum response: %d %v", w.Code, w.Header())
+	}
+	w := do("/api/subscriptions/sum")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "1" {
+		t.Fatalf("expected Retry-After 1, got %q", w.Header().Get("Retry-After"))
+	}
+	if w := do("/api/subscriptions/forecast"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("groups of one name must share buckets, got %d", w.Code)
+	}
+	if w := do("/api/subscriptions/42"); w.Code != http.StatusOK || w.Header().Get("RateLimit-Remaining") != "9" {
+		t.Fatalf("default group must be independent: %d %v", w.Code, w.Header())
+	}
+}
@@ -9,8 +9,15 @@ import (
 	"os/signal"
 	"subcalc/internal/config"
 	"subcalc/internal/delivery/handlers"
-	gormrepo "subcalc/internal/repository/gorm"
+	"subcalc/internal/events"
+	"subcalc/internal/health"
+	"subcalc/internal/infrastructure/db"
+	"subcalc/internal/metrics"
+	"subcalc/internal/reminder"
+	"subcalc/internal/tracing"
 	"subcalc/internal/usecase"
+	"subcalc/internal/webhook"
+	"sync"
 	"syscall"
 	"time"
 
@@ -22,18 +29,22 @@ import (
 )
 
 type Server struct {
-	cfg  *config.Config
+	cfg   *config.Config
+	repos Repositories
+	// db is nil with memory storage; the database checks and metrics are
+	// skipped then.
 	db   *gorm.DB
 	log  *zap.SugaredLogger
 	addr string
 }
 
-func NewServer(cfg *config.Config, db *gorm.DB, logger *zap.SugaredLogger) *Server {
+func NewServer(cfg *config.Config, repos Repositories, db *gorm.DB, logger *zap.SugaredLogger) *Server {
 	return &Server{
-		cfg:  cfg,
-		db:   db,
-		log:  logger,
-		addr: fmt.Sprintf(":%s", cfg.AppPort),
+		cfg:   cfg,
+		repos: repos,
+		db:    db,
+		log:   logger,
+		addr:  fmt.Sprintf(":%s", cfg.AppPort),
 	}
 }
 
@@ -46,31 +57,142 @@ func (s *Server) Run() error {
 
 	r := gin.New()
 
+	var m *metrics.Metrics
+	var observers []RequestObserver
+	if s.cfg.MetricsEnabled {
+		m = metrics.New()
+		observers = append(observers, m.ObserveHTTP)
+	}
+
 	rawLogger := s.log.Desugar()
-	r.Use(ZapRequestLogger(rawLogger))
+	r.Use(Tracing())
+	r.Use(ZapRequestLogger(rawLogger, observers...))
 	r.Use(gin.Recovery())
 
-	repo := gormrepo.NewGormSubscriptionRepo(s.db)
-	uc := usecase.NewSubscriptionUsecase(repo)
+	repo := tracing.TraceSubscriptionRepo(s.repos.Subscriptions)
+	if m != nil {
+		repo = metrics.InstrumentSubscriptionRepo(repo, m)
+		if err := s.registerDBMetrics(m); err != nil {
+			return err
+		}
+		m.RegisterActiveSubscriptions(s.repos.Stats.ActiveByOrg)
+		r.GET("/metrics", gin.WrapH(m.Handler()))
+	}
+	uc := tracing.TraceSubscriptionUsecase(usecase.NewSubscriptionUsecase(repo))
 	h := handlers.NewHandler(uc, s.log)
 
-	h.RegisterRoutes(r)
+	keyUC := usecase.NewAPIKeyUsecase(s.repos.APIKeys)
+	kh := handlers.NewAPIKeyHandler(keyUC, s.log)
+
+	orgRepo := s.repos.Organizations
+	oh := handlers.NewOrgHandler(usecase.NewOrganizationUsecase(orgRepo), s.log)
+
+	wh := handlers.NewWebhookHandler(usecase.NewWebhookUsecase(s.repos.Webhooks), s.log)
+
+	broker := events.NewBroker(s.repos.Events, events.Options{
+		Interval:   s.cfg.EventsPollInterval,
+		GapTimeout: 5 * time.Second,
+		Buffer:     256,
+	}, s.log)
+	eh := handlers.NewEventHandler(usecase.NewEventUsecase(broker), s.cfg.EventsHeartbeatInterval, s.log)
+
+	apiMiddleware := []gin.HandlerFunc{APIKeyAuth(keyUC, s.log)}
+	if s.cfg.AuthJWTSecret != "" {
+		apiMiddleware = append(apiMiddleware, JWTAuth(s.cfg.AuthJWTSecret, s.log))
+	} else {
+		s.log.Warn("AUTH_JWT_SECRET is empty, authentication disabled")
+	}
+	if s.cfg.RateLimitEnabled {
+		def := Limit{Rate: s.cfg.RateLimitRPS, Burst: s.cfg.RateLimitBurst}
+		// the aggregates scan every matching row and share one budget
+		aggregate := Limit{Rate: s.cfg.RateLimitSumRPS, Burst: s.cfg.RateLimitSumBurst}
+		groups := []RateLimitGroup{
+			{Name: "aggregate", PathPrefix: "/api/subscriptions/sum", Limit: aggregate},
+			{Name: "aggregate", PathPrefix: "/api/subscriptions/breakdown", Limit: aggregate},
+			{Name: "aggregate", PathPrefix: "/api/subscriptions/forecast", Limit: aggregate},
+		}
+		apiMiddleware = append(apiMiddleware, RateLimit(NewMemoryRateLimitStore(), def, groups, s.log))
+	}
+	if s.cfg.StrictQueryParams {
+		apiMiddleware = append(apiMiddleware, handlers.StrictQuery())
+	}
+	apiMiddleware = append(apiMiddleware, ResolveTenant(orgRepo, s.log))
+	h.RegisterRoutes(r, apiMiddleware...)
+	kh.RegisterRoutes(r, apiMiddleware...)
+	oh.RegisterRoutes(r, apiMiddleware...)
+	wh.RegisterRoutes(r, apiMiddleware...)
+	eh.RegisterRoutes(r, apiMiddleware...)
 
 	r.StaticFile("/swagger/doc.json", "/docs/swagger.json")
 
 	url := ginSwagger.URL("/swagger/doc.json")
 	r.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
 
-	r.GET("/health", func(c *gin.Context) {
+	checker, err := s.newReadinessChecker()
+	if err != nil {
+		return err
+	}
+	live := func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+	r.GET("/health", live)
+	r.GET("/livez", live)
+	r.GET("/readyz", func(c *gin.Context) {
+		rep := checker.Ready(c.Request.Context())
+		status := http.StatusOK
+		if rep.Status != health.StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, rep)
 	})
 
+	// background work stops before Run returns, so a memory snapshot
+	// saved afterwards sees its last writes
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	var background sync.WaitGroup
+	defer background.Wait()
+	defer stopBackground()
+
+	worker := webhook.NewWorker(s.repos.Outbox, webhook.Options{
+		Interval:    s.cfg.WebhookPollInterval,
+		BatchSize:   100,
+		MaxAttempts: s.cfg.WebhookMaxAttempts,
+		BackoffBase: s.cfg.WebhookBackoffBase,
+		BackoffMax:  s.cfg.WebhookBackoffMax,
+		Timeout:     s.cfg.WebhookTimeout,
+		Retention:   s.cfg.WebhookRetention,
+	}, s.log)
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		worker.Run(bgCtx)
+	}()
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		broker.Run(bgCtx)
+	}()
+	if notifiers := s.reminderNotifiers(); len(notifiers) > 0 {
+		scheduler := reminder.NewScheduler(s.repos.Reminders, s.repos.Locks, notifiers, reminder.Options{
+			Window: s.cfg.ReminderWindow,
+			RunAt:  s.cfg.ReminderRunAt,
+		}, s.log)
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			scheduler.Run(bgCtx)
+		}()
+	}
+
 	s.log.Infof("listening on %s", s.addr)
 
 	srv := &http.Server{
 		Addr:    s.addr,
 		Handler: r,
 	}
+	// event streams never go idle; stopping the broker ends them so that
+	// Shutdown does not wait them out
+	srv.RegisterOnShutdown(stopBackground)
 
 	errCh := make(chan error, 1)
 	go func() {
@@ -91,6 +213,14 @@ func (s *Server) Run() error {
 		}
 	}
 
+	// Fail readiness first and keep serving for a while so load balancers
+	// stop routing new traffic before connections are closed.
+	checker.SetShuttingDown()
+	if s.cfg.ShutdownDrainDelay > 0 {
+		s.log.Infof("draining for %s", s.cfg.ShutdownDrainDelay)
+		time.Sleep(s.cfg.ShutdownDrainDelay)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
@@ -101,3 +231,58 @@ func (s *Server) Run() error {
 	s.log.Info("server gracefully stopped")
 	return nil
 }
+
+// reminderNotifiers builds the notifiers REMINDER_NOTIFIERS names.
+func (s *Server) reminderNotifiers() []reminder.Notifier {
+	var out []reminder.Notifier
+	for _, name := range s.cfg.ReminderNotifiers {
+		switch name {
+		case reminder.ChannelLog:
+			out = append(out, reminder.NewLogNotifier(s.log))
+		case reminder.ChannelWebhook:
+			out = append(out, reminder.NewWebhookNotifier(s.repos.Outbox))
+		case reminder.ChannelSMTP:
+			out = append(out, reminder.NewSMTPNotifier(reminder.SMTPOptions{
+				Host:     s.cfg.ReminderSMTPHost,
+				Port:     s.cfg.ReminderSMTPPort,
+				Username: s.cfg.ReminderSMTPUsername,
+				Password: s.cfg.ReminderSMTPPassword,
+				From:     s.cfg.ReminderSMTPFrom,
+				To:       s.cfg.ReminderSMTPTo,
+			}))
+		}
+	}
+	return out
+}
+
+func (s *Server) registerDBMetrics(m *metrics.Metrics) error {
+	if s.db == nil {
+		return nil
+	}
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	m.RegisterDBStats(sqlDB, s.cfg.DBName)
+	return nil
+}
+
+func (s *Server) newReadinessChecker() (*health.Checker, error) {
+	checker := health.NewChecker(s.cfg.ReadinessTimeout)
+	if s.db == nil {
+		return checker, nil
+	}
+	checker.Add("database", func(ctx context.Context) error {
+		sqlDB, err := s.db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+	m, err := db.NewMigrator(s.db, s.log)
+	if err != nil {
+		return nil, err
+	}
+	checker.Add("migrations", m.Check)
+	return checker, nil
+}
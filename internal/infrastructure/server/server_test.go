@@ -0,0 +1,23 @@
+package server
+
+import (
+	"slices"
+	"subcalc/internal/config"
+	memrepo "subcalc/internal/repository/memory"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestReminderNotifiers(t *testing.T) {
+	for _, names := range [][]string{nil, {"webhook"}, {"log", "webhook", "smtp"}} {
+		s := NewServer(&config.Config{ReminderNotifiers: names}, MemoryRepositories(memrepo.NewStore()), nil, zap.NewNop().Sugar())
+		var channels []string
+		for _, n := range s.reminderNotifiers() {
+			channels = append(channels, n.Channel())
+		}
+		if !slices.Equal(channels, names) {
+			t.Errorf("REMINDER_NOTIFIERS=%v: got the notifiers %v", names, channels)
+		}
+	}
+}
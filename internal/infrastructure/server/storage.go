@@ -0,0 +1,58 @@
+package server
+
+import (
+	"subcalc/internal/infrastructure/db"
+	"subcalc/internal/repository"
+	gormrepo "subcalc/internal/repository/gorm"
+	memrepo "subcalc/internal/repository/memory"
+
+	"gorm.io/gorm"
+)
+
+// Repositories is the storage the API runs on.
+type Repositories struct {
+	Subscriptions repository.SubscriptionRepository
+	APIKeys       repository.APIKeyRepository
+	Organizations repository.OrganizationRepository
+	Stats         repository.StatsRepository
+	Webhooks      repository.WebhookRepository
+	// Outbox holds the events Subscriptions records with its changes.
+	Outbox repository.OutboxRepository
+	// Events reads the outbox as the log event streams resume from.
+	Events repository.EventRepository
+	// Reminders and Locks serve the reminder scheduler.
+	Reminders repository.ReminderRepository
+	Locks     repository.LockRepository
+}
+
+func GormRepositories(gdb *gorm.DB) Repositories {
+	subs := gormrepo.NewGormSubscriptionRepo(gdb)
+	if db.IsSQLite(gdb) {
+		subs = gormrepo.NewSQLiteSubscriptionRepo(gdb)
+	}
+	return Repositories{
+		Subscriptions: subs,
+		APIKeys:       gormrepo.NewGormAPIKeyRepo(gdb),
+		Organizations: gormrepo.NewGormOrganizationRepo(gdb),
+		Stats:         gormrepo.NewGormStatsRepo(gdb),
+		Webhooks:      gormrepo.NewGormWebhookRepo(gdb),
+		Outbox:        gormrepo.NewGormOutboxRepo(gdb),
+		Events:        gormrepo.NewGormEventRepo(gdb),
+		Reminders:     gormrepo.NewGormReminderRepo(gdb),
+		Locks:         gormrepo.NewGormLockRepo(gdb),
+	}
+}
+
+func MemoryRepositories(store *memrepo.Store) Repositories {
+	return Repositories{
+		Subscriptions: memrepo.NewMemorySubscriptionRepo(store),
+		APIKeys:       memrepo.NewMemoryAPIKeyRepo(store),
+		Organizations: memrepo.NewMemoryOrganizationRepo(store),
+		Stats:         memrepo.NewMemoryStatsRepo(store),
+		Webhooks:      memrepo.NewMemoryWebhookRepo(store),
+		Outbox:        memrepo.NewMemoryOutboxRepo(store),
+		Events:        memrepo.NewMemoryEventRepo(store),
+		Reminders:     memrepo.NewMemoryReminderRepo(store),
+		Locks:         memrepo.NewMemoryLockRepo(store),
+	}
+}
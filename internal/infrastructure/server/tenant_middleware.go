@@ -0,0 +1,67 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"subcalc/internal/auth"
+	"subcalc/internal/delivery/handlers"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"subcalc/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ResolveTenant stores the request's organization in the context. An
+// authenticated caller is bound to the organization of its credentials and
+// may only repeat it in X-Org-Id; without authentication the header picks
+// the organization. The default organization is used otherwise.
+func ResolveTenant(orgs repository.OrganizationRepository, log *zap.SugaredLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		header := c.GetHeader("X-Org-Id")
+
+		orgID := tenant.DefaultOrgID
+		if header != "" {
+			id, err := uuid.Parse(header)
+			if err != nil {
+				handlers.RespondError(c, http.StatusBadRequest, "invalid_field", "X-Org-Id must be a UUID", map[string]string{"X-Org-Id": "invalid uuid"})
+				c.Abort()
+				return
+			}
+			orgID = id
+		}
+
+		if p, ok := auth.FromContext(ctx); ok {
+			bound := tenant.DefaultOrgID
+			if p.OrgID != nil {
+				bound = *p.OrgID
+			}
+			if header != "" && orgID != bound {
+				handlers.RespondError(c, http.StatusForbidden, "forbidden", "credentials are not valid for this organization", nil)
+				c.Abort()
+				return
+			}
+			orgID = bound
+		}
+
+		org, err := orgs.GetByID(ctx, orgID)
+		if errors.Is(err, domain.ErrNotFound) {
+			handlers.RespondError(c, http.StatusNotFound, "not_found", "organization not found", nil)
+			c.Abort()
+			return
+		}
+		if err != nil {
+			log.Errorf("resolve organization failed: %v", err)
+			handlers.RespondError(c, http.StatusInternalServerError, "internal_error", "resolve organization failed", nil)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(tenant.WithOrganization(ctx, org))
+		c.Next()
+	}
+}
@@ -13,3 +13,12 @@ var loggerCtxKey = ctxKeyType{}
 func WithLogger(ctx context.Context, l *zap.Logger) context.Context {
 	return context.WithValue(ctx, loggerCtxKey, l)
 }
+
+// FromContext returns the request-scoped logger stored by WithLogger, or the
+// global zap logger when there is none.
+func FromContext(ctx context.Context) *zap.Logger {
+	if l, ok := ctx.Value(loggerCtxKey).(*zap.Logger); ok && l != nil {
+		return l
+	}
+	return zap.L()
+}
@@ -10,6 +10,28 @@ import (
 )
 
 func New(levelStr string, useFileRotation bool) (*zap.Logger, error) {
+	var ws zapcore.WriteSyncer
+	if useFileRotation {
+		l := &lumberjack.Logger{
+			Filename:   "/var/log/subscriptions/app.log",
+			MaxSize:    100,
+			MaxBackups: 7,
+			MaxAge:     14,
+			Compress:   true,
+		}
+		ws = zapcore.AddSync(l)
+	} else {
+		ws = zapcore.Lock(os.Stdout)
+	}
+	return newLogger(levelStr, ws), nil
+}
+
+// NewStderr logs to stderr, keeping stdout free for command output.
+func NewStderr(levelStr string) (*zap.Logger, error) {
+	return newLogger(levelStr, zapcore.Lock(os.Stderr)), nil
+}
+
+func newLogger(levelStr string, ws zapcore.WriteSyncer) *zap.Logger {
 	var level zapcore.Level
 	switch levelStr {
 	case "debug":
@@ -29,20 +51,6 @@ func New(levelStr string, useFileRotation bool) (*zap.Logger, error) {
 	encCfg.TimeKey = "ts"
 	enc := zapcore.NewJSONEncoder(encCfg)
 
-	var ws zapcore.WriteSyncer
-	if useFileRotation {
-		l := &lumberjack.Logger{
-			Filename:   "/var/log/subscriptions/app.log",
-			MaxSize:    100,
-			MaxBackups: 7,
-			MaxAge:     14,
-			Compress:   true,
-		}
-		ws = zapcore.AddSync(l)
-	} else {
-		ws = zapcore.Lock(os.Stdout)
-	}
-
 	core := zapcore.NewCore(enc, ws, zap.NewAtomicLevelAt(level))
 
 	logger := zap.New(core,
@@ -56,5 +64,5 @@ func New(levelStr string, useFileRotation bool) (*zap.Logger, error) {
 		zap.Time("start_time", time.Now().UTC()),
 	)
 
-	return logger, nil
+	return logger
 }
@@ -0,0 +1,142 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"subcalc/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "subcalc"
+
+// unmatchedRoute labels requests that hit no route, so arbitrary URLs
+// cannot blow up label cardinality.
+const unmatchedRoute = "unmatched"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	repoDuration *prometheus.HistogramVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		repoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "repository_call_duration_seconds",
+			Help:      "Repository call latency by method and outcome.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"repository", "method", "outcome"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.repoDuration,
+	)
+	return m
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveHTTP records one finished request. route is the gin FullPath,
+// empty for unmatched requests.
+func (m *Metrics) ObserveHTTP(method, route string, status int, latency time.Duration) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+	s := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, s).Inc()
+	m.httpDuration.WithLabelValues(method, route, s).Observe(latency.Seconds())
+}
+
+func (m *Metrics) ObserveRepo(repository, method string, err error, latency time.Duration) {
+	outcome := "ok"
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		outcome = "not_found"
+	case err != nil:
+		outcome = "error"
+	}
+	m.repoDuration.WithLabelValues(repository, method, outcome).Observe(latency.Seconds())
+}
+
+// RegisterDBStats exports sql.DB.Stats() of the connection pool.
+func (m *Metrics) RegisterDBStats(db *sql.DB, dbName string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// ActiveCounter returns the number of active subscriptions per organization.
+type ActiveCounter func(ctx context.Context, at time.Time) (map[uuid.UUID]int64, error)
+
+// RegisterActiveSubscriptions exports the active subscriptions gauge,
+// computed on every scrape.
+func (m *Metrics) RegisterActiveSubscriptions(count ActiveCounter) {
+	m.registry.MustRegister(&activeCollector{
+		count: count,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "active_subscriptions"),
+			"Subscriptions active in the current month, by organization.",
+			[]string{"org_id"}, nil,
+		),
+		errDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "active_subscriptions_scrape_error"),
+			"1 if counting active subscriptions failed on this scrape.",
+			nil, nil,
+		),
+	})
+}
+
+// scrapeTimeout keeps a slow database from stalling the whole scrape.
+const scrapeTimeout = 5 * time.Second
+
+type activeCollector struct {
+	count   ActiveCounter
+	desc    *prometheus.Desc
+	errDesc *prometheus.Desc
+}
+
+func (c *activeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+	ch <- c.errDesc
+}
+
+func (c *activeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+
+	counts, err := c.count(ctx, time.Now().UTC())
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.errDesc, prometheus.GaugeValue, 1)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.errDesc, prometheus.GaugeValue, 0)
+	for org, n := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(n), org.String())
+	}
+}
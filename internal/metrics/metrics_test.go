@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+	return string(body)
+}
+
+func TestObserveHTTP_LabelsRouteAndStatus(t *testing.T) {
+	m := New()
+	m.ObserveHTTP("GET", "/api/subscriptions/:id", 200, 10*time.Millisecond)
+	m.ObserveHTTP("GET", "", 404, time.Millisecond)
+
+	out := scrape(t, m)
+	for _, want := range []string{
+		`subcalc_http_requests_total{method="GET",route="/api/subscriptions/:id",status="200"} 1`,
+		`subcalc_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s", want)
+		}
+	}
+}
+
+func TestActiveSubscriptions_ReportsScrapeErrors(t *testing.T) {
+	org := uuid.New()
+	fail := false
+	m := New()
+	m.RegisterActiveSubscriptions(func(ctx context.Context, at time.Time) (map[uuid.UUID]int64, error) {
+		if fail {
+			return nil, errors.New("db down")
+		}
+		return map[uuid.UUID]int64{org: 7}, nil
+	})
+
+	if out := scrape(t, m); !strings.Contains(out, `subcalc_active_subscriptions{org_id="`+org.String()+`"} 7`) {
+		t.Fatalf("gauge missing:\n%s", out)
+	}
+	fail = true
+	if out := scrape(t, m); !strings.Contains(out, "subcalc_active_subscriptions_scrape_error 1") {
+		t.Fatalf("scrape error not reported")
+	}
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type instrumentedSubscriptionRepo struct {
+	next repository.SubscriptionRepository
+	m    *Metrics
+}
+
+// InstrumentSubscriptionRepo records the duration of every call to next.
+func InstrumentSubscriptionRepo(next repository.SubscriptionRepository, m *Metrics) repository.SubscriptionRepository {
+	return &instrumentedSubscriptionRepo{next: next, m: m}
+}
+
+func (r *instrumentedSubscriptionRepo) observe(method string, start time.Time, err error) {
+	r.m.ObserveRepo("subscriptions", method, err, time.Since(start))
+}
+
+func (r *instrumentedSubscriptionRepo) Create(ctx context.Context, sub *domain.Subscription) error {
+	start := time.Now()
+	err := r.next.Create(ctx, sub)
+	r.observe("Create", start, err)
+	return err
+}
+
+func (r *instrumentedSubscriptionRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	start := time.Now()
+	sub, err := r.next.GetByID(ctx, id)
+	r.observe("GetByID", start, err)
+	return sub, err
+}
+
+func (r *instrumentedSubscriptionRepo) Update(ctx context.Context, sub *domain.Subscription) error {
+	start := time.Now()
+	err := r.next.Update(ctx, sub)
+	r.observe("Update", start, err)
+	return err
+}
+
+func (r *instrumentedSubscriptionRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	start := time.Now()
+	err := r.next.Delete(ctx, id)
+	r.observe("Delete", start, err)
+	return err
+}
+
+func (r *instrumentedSubscriptionRepo) DeleteMany(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	start := time.Now()
+	n, err := r.next.DeleteMany(ctx, ids)
+	r.observe("DeleteMany", start, err)
+	return n, err
+}
+
+func (r *instrumentedSubscriptionRepo) DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	start := time.Now()
+	n, err := r.next.DeleteByUser(ctx, userID)
+	r.observe("DeleteByUser", start, err)
+	return n, err
+}
+
+func (r *instrumentedSubscriptionRepo) List(ctx context.Context, filter repository.SubscriptionFilter) ([]*domain.Subscription, error) {
+	start := time.Now()
+	subs, err := r.next.List(ctx, filter)
+	r.observe("List", start, err)
+	return subs, err
+}
+
+func (r *instrumentedSubscriptionRepo) FindForPeriod(ctx context.Context, filter repository.SubscriptionFilter) ([]*domain.Subscription, error) {
+	start := time.Now()
+	subs, err := r.next.FindForPeriod(ctx, filter)
+	r.observe("FindForPeriod", start, err)
+	return subs, err
+}
+
+func (r *instrumentedSubscriptionRepo) SumForPeriod(ctx context.Context, filter repository.SubscriptionFilter) (int64, error) {
+	start := time.Now()
+	total, err := r.next.SumForPeriod(ctx, filter)
+	r.observe("SumForPeriod", start, err)
+	return total, err
+}
+
+func (r *instrumentedSubscriptionRepo) Count(ctx context.Context, filter repository.SubscriptionFilter) (int64, error) {
+	start := time.Now()
+	n, err := r.next.Count(ctx, filter)
+	r.observe("Count", start, err)
+	return n, err
+}
+
+func (r *instrumentedSubscriptionRepo) Search(ctx context.Context, query string, filter repository.SubscriptionFilter) ([]repository.SearchHit, error) {
+	start := time.Now()
+	hits, err := r.next.Search(ctx, query, filter)
+	r.observe("Search", start, err)
+	return hits, err
+}
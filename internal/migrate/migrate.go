@@ -0,0 +1,362 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+// lockKey identifies the advisory lock held while migrating, so replicas
+// starting together apply migrations one at a time.
+const lockKey int64 = 0x5ab_ca1c
+
+var fileRe = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+var ErrDirty = errors.New("database is dirty, fix the failed migration and reset schema_migrations manually")
+
+// Dialect holds the database-specific statements of the runner.
+type Dialect struct {
+	// lock and unlock serialize runners started together; empty when the
+	// database needs no lock
+	lock, unlock string
+	// tableExists reports whether schema_migrations exists, without DDL
+	tableExists string
+}
+
+var (
+	Postgres = Dialect{
+		lock:        "SELECT pg_advisory_lock($1)",
+		unlock:      "SELECT pg_advisory_unlock($1)",
+		tableExists: "SELECT to_regclass('schema_migrations') IS NOT NULL",
+	}
+	// SQLite takes no lock: writes to a database file are serialized by
+	// SQLite itself, and each step runs in a transaction.
+	SQLite = Dialect{
+		tableExists: "SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations')",
+	}
+)
+
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version uint   `json:"version"`
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+}
+
+type Status struct {
+	Current    uint              `json:"current"`
+	Latest     uint              `json:"latest"`
+	Dirty      bool              `json:"dirty"`
+	Migrations []MigrationStatus `json:"migrations"`
+}
+
+// Load reads "<version>_<name>.up.sql" / ".down.sql" pairs from fsys,
+// sorted by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[uint]*Migration{}
+	for _, e := range entries {
+		m := fileRe.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		v, err := strconv.ParseUint(m[1], 10, 32)
+		if err != nil || v == 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", e.Name())
+		}
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		mig, ok := byVersion[uint(v)]
+		if !ok {
+			mig = &Migration{Version: uint(v), Name: m[2]}
+			byVersion[uint(v)] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", v, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", mig.Version, mig.Name)
+		}
+		out = append(out, *mig)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// Migrator applies migrations and records the current version in
+// schema_migrations, using the same table layout as golang-migrate so
+// databases migrated by the migrate/migrate tool are picked up as-is.
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+	log        *zap.SugaredLogger
+}
+
+func New(db *sql.DB, dialect Dialect, fsys fs.FS, log *zap.SugaredLogger) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: dialect, migrations: migrations, log: log}, nil
+}
+
+// Latest is the version of the newest known migration.
+func (m *Migrator) Latest() uint {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down reverts the given number of applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		current, dirty, err := readVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return ErrDirty
+		}
+		target := current
+		for i := 0; i < steps && target > 0; i++ {
+			target = m.previous(target)
+		}
+		return m.migrate(ctx, conn, current, target)
+	})
+}
+
+// To migrates up or down to version; 0 reverts everything.
+func (m *Migrator) To(ctx context.Context, version uint) error {
+	if version != 0 && m.index(version) < 0 {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		current, dirty, err := readVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return ErrDirty
+		}
+		return m.migrate(ctx, conn, current, version)
+	})
+}
+
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	defer conn.Close()
+
+	// no DDL here: status is polled by the readiness probe
+	var exists bool
+	if err := conn.QueryRowContext(ctx, m.dialect.tableExists).Scan(&exists); err != nil {
+		return Status{}, err
+	}
+	var current uint
+	var dirty bool
+	if exists {
+		if current, dirty, err = readVersion(ctx, conn); err != nil {
+			return Status{}, err
+		}
+	}
+	st := Status{Current: current, Latest: m.Latest(), Dirty: dirty}
+	for _, mig := range m.migrations {
+		st.Migrations = append(st.Migrations, MigrationStatus{
+			Version: mig.Version,
+			Name:    mig.Name,
+			Applied: mig.Version <= current,
+		})
+	}
+	return st, nil
+}
+
+// Check fails unless the database is at the latest version and clean.
+func (m *Migrator) Check(ctx context.Context) error {
+	st, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if st.Dirty {
+		return fmt.Errorf("schema version %d is dirty", st.Current)
+	}
+	if st.Current != st.Latest {
+		return fmt.Errorf("schema version %d, expected %d", st.Current, st.Latest)
+	}
+	return nil
+}
+
+type step struct {
+	migration Migration
+	up        bool
+	// version recorded after the step
+	after uint
+}
+
+// plan lists the steps leading from current to target.
+func (m *Migrator) plan(current, target uint) ([]step, error) {
+	if current != 0 && m.index(current) < 0 {
+		return nil, fmt.Errorf("database is at version %d which this binary does not know", current)
+	}
+	var steps []step
+	if target >= current {
+		for _, mig := range m.migrations {
+			if mig.Version > current && mig.Version <= target {
+				steps = append(steps, step{migration: mig, up: true, after: mig.Version})
+			}
+		}
+		return steps, nil
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if mig.Version <= current && mig.Version > target {
+			if mig.Down == "" {
+				return nil, fmt.Errorf("migration %d_%s has no down file", mig.Version, mig.Name)
+			}
+			steps = append(steps, step{migration: mig, after: m.previous(mig.Version)})
+		}
+	}
+	return steps, nil
+}
+
+func (m *Migrator) migrate(ctx context.Context, conn *sql.Conn, current, target uint) error {
+	steps, err := m.plan(current, target)
+	if err != nil {
+		return err
+	}
+	for _, s := range steps {
+		direction, body := "down", s.migration.Down
+		if s.up {
+			direction, body = "up", s.migration.Up
+		}
+		m.log.Infof("migration %d_%s %s", s.migration.Version, s.migration.Name, direction)
+		if err := applyStep(ctx, conn, body, s.after); err != nil {
+			return fmt.Errorf("migration %d_%s %s: %w", s.migration.Version, s.migration.Name, direction, err)
+		}
+	}
+	return nil
+}
+
+// applyStep runs one migration file and records the new version in the
+// same transaction, so a failure leaves the database where it was.
+func applyStep(ctx context.Context, conn *sql.Conn, body string, after uint) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return err
+	}
+	if after > 0 {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)", after); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	// advisory locks belong to a session, so lock, migrate and unlock on
+	// one pinned connection
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.dialect.lock != "" {
+		if _, err := conn.ExecContext(ctx, m.dialect.lock, lockKey); err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		defer func() {
+			if _, err := conn.ExecContext(context.Background(), m.dialect.unlock, lockKey); err != nil {
+				m.log.Errorf("release migration lock: %v", err)
+			}
+		}()
+	}
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version bigint NOT NULL PRIMARY KEY,
+    dirty boolean NOT NULL
+)`)
+	return err
+}
+
+func readVersion(ctx context.Context, conn *sql.Conn) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := conn.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return uint(version), dirty, nil
+}
+
+func (m *Migrator) index(version uint) int {
+	for i, mig := range m.migrations {
+		if mig.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// previous returns the version applied before version, 0 for the first one.
+func (m *Migrator) previous(version uint) uint {
+	prev := uint(0)
+	for _, mig := range m.migrations {
+		if mig.Version >= version {
+			break
+		}
+		prev = mig.Version
+	}
+	return prev
+}
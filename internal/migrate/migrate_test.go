@@ -0,0 +1,118 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"subcalc/migrations"
+)
+
+func testMigrator(t *testing.T) *Migrator {
+	t.Helper()
+	fsys := fstest.MapFS{
+		"0001_init.up.sql":     {Data: []byte("CREATE TABLE a ();")},
+		"0001_init.down.sql":   {Data: []byte("DROP TABLE a;")},
+		"0002_more.up.sql":     {Data: []byte("CREATE TABLE b ();")},
+		"0002_more.down.sql":   {Data: []byte("DROP TABLE b;")},
+		"0005_gap.up.sql":      {Data: []byte("CREATE TABLE c ();")},
+		"0005_gap.down.sql":    {Data: []byte("DROP TABLE c;")},
+		"README.md":            {Data: []byte("ignored")},
+		"embed.go":             {Data: []byte("package migrations")},
+		"0003_noup.down.sql.x": {Data: []byte("ignored")},
+	}
+	m, err := New(nil, Postgres, fsys, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return m
+}
+
+func versions(steps []step) []uint {
+	out := make([]uint, 0, len(steps))
+	for _, s := range steps {
+		out = append(out, s.migration.Version)
+	}
+	return out
+}
+
+func TestLoad_EmbeddedMigrations(t *testing.T) {
+	ms, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, m := range ms {
+		if m.Version != uint(i+1) {
+			t.Fatalf("expected contiguous versions, got %d at %d", m.Version, i)
+		}
+		if m.Down == "" {
+			t.Fatalf("migration %d_%s has no down file", m.Version, m.Name)
+		}
+	}
+}
+
+func TestLoad_SQLiteInStepWithPostgres(t *testing.T) {
+	pg, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lite, err := Load(migrations.SQLite)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pg) != len(lite) {
+		t.Fatalf("postgres has %d migrations, sqlite %d", len(pg), len(lite))
+	}
+	for i := range pg {
+		if pg[i].Version != lite[i].Version || pg[i].Name != lite[i].Name {
+			t.Fatalf("migration %d_%s has no sqlite counterpart", pg[i].Version, pg[i].Name)
+		}
+	}
+}
+
+func TestLoad_RejectsMissingUp(t *testing.T) {
+	_, err := Load(fstest.MapFS{"0001_x.down.sql": {Data: []byte("x")}})
+	if err == nil {
+		t.Fatalf("expected error for migration without up file")
+	}
+}
+
+func TestPlan(t *testing.T) {
+	m := testMigrator(t)
+	if m.Latest() != 5 {
+		t.Fatalf("expected latest 5, got %d", m.Latest())
+	}
+
+	cases := []struct {
+		name            string
+		current, target uint
+		want            []uint
+		wantAfter       []uint
+	}{
+		{"fresh up", 0, 5, []uint{1, 2, 5}, []uint{1, 2, 5}},
+		{"partial up", 1, 2, []uint{2}, []uint{2}},
+		{"noop", 5, 5, []uint{}, []uint{}},
+		{"down one", 5, 2, []uint{5}, []uint{2}},
+		{"down all", 5, 0, []uint{5, 2, 1}, []uint{2, 1, 0}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			steps, err := m.plan(tc.current, tc.target)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := versions(steps)
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] || steps[i].after != tc.wantAfter[i] {
+					t.Fatalf("step %d: expected %d->%d, got %d->%d", i, tc.want[i], tc.wantAfter[i], got[i], steps[i].after)
+				}
+			}
+		})
+	}
+
+	if _, err := m.plan(4, 5); err == nil {
+		t.Fatalf("expected error for unknown current version")
+	}
+}
@@ -0,0 +1,66 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Channels of the notifiers, as stored with their reminders.
+const (
+	ChannelLog     = "log"
+	ChannelWebhook = "webhook"
+	ChannelSMTP    = "smtp"
+)
+
+// summary describes a reminder in one line.
+func summary(r *domain.Reminder, sub *domain.Subscription) string {
+	if r.Kind == domain.ReminderEnding {
+		return fmt.Sprintf("%s ends after %s", sub.ServiceName, domain.FormatMonthYear(*sub.EndDate))
+	}
+	return fmt.Sprintf("%s renews on %s for %d", sub.ServiceName, r.DueOn.Format(time.DateOnly), sub.Price)
+}
+
+// LogNotifier writes reminders to the log.
+type LogNotifier struct {
+	log *zap.SugaredLogger
+}
+
+func NewLogNotifier(log *zap.SugaredLogger) *LogNotifier {
+	return &LogNotifier{log: log}
+}
+
+func (n *LogNotifier) Channel() string { return ChannelLog }
+
+func (n *LogNotifier) Notify(ctx context.Context, r *domain.Reminder, sub *domain.Subscription) error {
+	n.log.Infof("reminder: %s (subscription %s, user %s, organization %s)", summary(r, sub), sub.ID, sub.UserID, sub.OrgID)
+	return nil
+}
+
+// WebhookNotifier raises reminders as subscription.renewing and
+// subscription.ending_soon events, which the webhook worker delivers to the
+// webhooks of the subscription's organization that want them.
+type WebhookNotifier struct {
+	outbox repository.OutboxRepository
+}
+
+func NewWebhookNotifier(outbox repository.OutboxRepository) *WebhookNotifier {
+	return &WebhookNotifier{outbox: outbox}
+}
+
+func (n *WebhookNotifier) Channel() string { return ChannelWebhook }
+
+func (n *WebhookNotifier) Notify(ctx context.Context, r *domain.Reminder, sub *domain.Subscription) error {
+	t := domain.EventSubscriptionRenewing
+	if r.Kind == domain.ReminderEnding {
+		t = domain.EventSubscriptionEndingSoon
+	}
+	e := domain.SubscriptionEvent(t, sub)
+	due := r.DueOn
+	e.Data.DueOn = &due
+	return n.outbox.Append(ctx, e)
+}
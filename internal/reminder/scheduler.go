@@ -0,0 +1,166 @@
+// Package reminder notifies of the subscriptions that renew or end soon. A
+// Scheduler looks ahead once a day; a database lease lets one replica at a
+// time do so, and the reminders table keeps each reminder from being sent
+// twice through the same channel.
+package reminder
+
+import (
+	"context"
+	"subcalc/internal/billing"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	lockName = "reminders"
+	// lockTTL outlasts a run; a replica that dies holding the lease only
+	// blocks the others that long.
+	lockTTL = time.Hour
+	// pageSize bounds the subscriptions read at once.
+	pageSize = 500
+)
+
+// Notifier sends reminders through one channel.
+type Notifier interface {
+	// Channel names the notifier in the reminders table; renaming it
+	// sends every reminder again.
+	Channel() string
+	Notify(ctx context.Context, r *domain.Reminder, sub *domain.Subscription) error
+}
+
+type Options struct {
+	// Window is how far ahead renewals and ends are reminded of.
+	Window time.Duration
+	// RunAt is the time of day, from UTC midnight, of the daily run.
+	RunAt time.Duration
+}
+
+type Scheduler struct {
+	reminders repository.ReminderRepository
+	locks     repository.LockRepository
+	notifiers []Notifier
+	opts      Options
+	log       *zap.SugaredLogger
+	// holder tells the lease of this scheduler from other replicas'
+	holder string
+	now    func() time.Time
+}
+
+func NewScheduler(reminders repository.ReminderRepository, locks repository.LockRepository, notifiers []Notifier, opts Options, log *zap.SugaredLogger) *Scheduler {
+	return &Scheduler{
+		reminders: reminders,
+		locks:     locks,
+		notifiers: notifiers,
+		opts:      opts,
+		log:       log,
+		holder:    uuid.NewString(),
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run runs once right away, catching up on a day missed while stopped,
+// then daily at RunAt until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		if err := s.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			s.log.Errorf("reminders: %v", err)
+		}
+		timer := time.NewTimer(s.next(s.now()).Sub(s.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// next is the first daily run after now.
+func (s *Scheduler) next(now time.Time) time.Time {
+	t := now.Truncate(24 * time.Hour).Add(s.opts.RunAt)
+	if !t.After(now) {
+		t = t.Add(24 * time.Hour)
+	}
+	return t
+}
+
+// RunOnce sends the reminders due within Window that are not sent yet,
+// unless another replica holds the lease. A notifier that fails leaves its
+// reminder to the next run.
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	now := s.now()
+	ok, err := s.locks.TryLock(ctx, lockName, s.holder, now, lockTTL)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		s.log.Debug("reminders: another replica is running them")
+		return nil
+	}
+	defer func() {
+		if err := s.locks.Unlock(context.WithoutCancel(ctx), lockName, s.holder); err != nil {
+			s.log.Errorf("reminders: unlock: %v", err)
+		}
+	}()
+
+	// subscriptions renew and end at the start of a month
+	end := now.Add(s.opts.Window)
+	for month := billing.Month(now).AddDate(0, 1, 0); !month.After(end); month = month.AddDate(0, 1, 0) {
+		if err := s.remindAt(ctx, month); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) remindAt(ctx context.Context, month time.Time) error {
+	for after := uuid.Nil; ; {
+		subs, err := s.reminders.Due(ctx, month, after, pageSize)
+		if err != nil {
+			return err
+		}
+		for _, sub := range subs {
+			kind := domain.ReminderRenewal
+			if sub.EndDate != nil && sub.EndDate.Before(month) {
+				kind = domain.ReminderEnding
+			}
+			for _, n := range s.notifiers {
+				if err := s.remind(ctx, n, sub, kind, month); err != nil {
+					return err
+				}
+			}
+		}
+		if len(subs) < pageSize {
+			return nil
+		}
+		after = subs[len(subs)-1].ID
+	}
+}
+
+// remind sends one reminder through n unless it was sent already. Only
+// storage errors are returned.
+func (s *Scheduler) remind(ctx context.Context, n Notifier, sub *domain.Subscription, kind domain.ReminderKind, month time.Time) error {
+	r := &domain.Reminder{OrgID: sub.OrgID, SubscriptionID: sub.ID, Kind: kind, DueOn: month, Channel: n.Channel()}
+	pending, err := s.reminders.Track(ctx, r)
+	if err != nil || !pending {
+		return err
+	}
+
+	r.Attempts++
+	if err := n.Notify(ctx, r, sub); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.log.Warnf("reminders: %s reminder of subscription %s via %s: %v", kind, sub.ID, r.Channel, err)
+		r.Error = err.Error()
+	} else {
+		sent := s.now()
+		r.SentAt = &sent
+		r.Error = ""
+	}
+	return s.reminders.SaveAttempt(ctx, r)
+}
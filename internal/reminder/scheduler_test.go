@@ -0,0 +1,209 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	memrepo "subcalc/internal/repository/memory"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type recorder struct {
+	channel string
+	err     error
+	sent    []domain.Reminder
+}
+
+func (n *recorder) Channel() string { return n.channel }
+
+func (n *recorder) Notify(ctx context.Context, r *domain.Reminder, sub *domain.Subscription) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, *r)
+	return nil
+}
+
+func month(y int, m time.Month) time.Time {
+	return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+type fixture struct {
+	store *memrepo.Store
+	subs  repository.SubscriptionRepository
+	locks repository.LockRepository
+}
+
+func newFixture() *fixture {
+	store := memrepo.NewStore()
+	return &fixture{store: store, subs: memrepo.NewMemorySubscriptionRepo(store), locks: memrepo.NewMemoryLockRepo(store)}
+}
+
+func (f *fixture) scheduler(now time.Time, notifiers ...Notifier) *Scheduler {
+	s := NewScheduler(memrepo.NewMemoryReminderRepo(f.store), f.locks, notifiers, Options{Window: 72 * time.Hour}, zap.NewNop().Sugar())
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func (f *fixture) create(t *testing.T, name string, start time.Time, end *time.Time) *domain.Subscription {
+	t.Helper()
+	sub := &domain.Subscription{ServiceName: name, Price: 400, UserID: uuid.New(), StartDate: start, EndDate: end}
+	if err := f.subs.Create(context.Background(), sub); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return sub
+}
+
+func TestScheduler_RemindsOfRenewalsAndEndsWithinTheWindow(t *testing.T) {
+	f := newFixture()
+	july := month(2025, 7)
+	renewing := f.create(t, "renewing", month(2025, 1), nil)
+	ending := f.create(t, "ending", month(2025, 1), &july)
+	f.create(t, "starting", month(2025, 8), nil)
+	f.create(t, "ended", month(2025, 1), ptr(month(2025, 6)))
+
+	n := &recorder{channel: "test"}
+	s := f.scheduler(time.Date(2025, 7, 29, 9, 0, 0, 0, time.UTC), n)
+	if err := s.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(n.sent) != 2 {
+		t.Fatalf("want 2 reminders, got %+v", n.sent)
+	}
+	kinds := map[uuid.UUID]domain.ReminderKind{}
+	for _, r := range n.sent {
+		if !r.DueOn.Equal(month(2025, 8)) || r.Channel != "test" || r.Attempts != 1 {
+			t.Fatalf("unexpected reminder %+v", r)
+		}
+		kinds[r.SubscriptionID] = r.Kind
+	}
+	if kinds[renewing.ID] != domain.ReminderRenewal || kinds[ending.ID] != domain.ReminderEnding {
+		t.Fatalf("want a renewal and an ending reminder, got %v", kinds)
+	}
+
+	// nothing is due this early in the month
+	early := &recorder{channel: "test"}
+	if err := f.scheduler(time.Date(2025, 7, 10, 9, 0, 0, 0, time.UTC), early).RunOnce(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(early.sent) != 0 {
+		t.Fatalf("want no reminders outside the window, got %+v", early.sent)
+	}
+}
+
+func TestScheduler_SendsEachReminderOnce(t *testing.T) {
+	f := newFixture()
+	f.create(t, "renewing", month(2025, 1), nil)
+	now := time.Date(2025, 7, 30, 9, 0, 0, 0, time.UTC)
+
+	n := &recorder{channel: "test"}
+	for _, at := range []time.Time{now, now.Add(24 * time.Hour)} {
+		if err := f.scheduler(at, n).RunOnce(context.Background()); err != nil {
+			t.Fatalf("run: %v", err)
+		}
+	}
+	if len(n.sent) != 1 {
+		t.Fatalf("want the reminder sent once, got %d", len(n.sent))
+	}
+
+	// another channel has reminders of its own
+	other := &recorder{channel: "other"}
+	if err := f.scheduler(now, n, other).RunOnce(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(n.sent) != 1 || len(other.sent) != 1 {
+		t.Fatalf("want one reminder per channel, got %d and %d", len(n.sent), len(other.sent))
+	}
+}
+
+func TestScheduler_RetriesFailedReminders(t *testing.T) {
+	f := newFixture()
+	sub := f.create(t, "renewing", month(2025, 1), nil)
+	now := time.Date(2025, 7, 30, 9, 0, 0, 0, time.UTC)
+
+	n := &recorder{channel: "test", err: errors.New("mail server down")}
+	if err := f.scheduler(now, n).RunOnce(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	n.err = nil
+	if err := f.scheduler(now.Add(24*time.Hour), n).RunOnce(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(n.sent) != 1 || n.sent[0].SubscriptionID != sub.ID || n.sent[0].Attempts != 2 {
+		t.Fatalf("want the reminder sent on the second attempt, got %+v", n.sent)
+	}
+}
+
+func TestScheduler_SkipsWhileAnotherReplicaHoldsTheLock(t *testing.T) {
+	f := newFixture()
+	f.create(t, "renewing", month(2025, 1), nil)
+	now := time.Date(2025, 7, 30, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	if ok, err := f.locks.TryLock(ctx, lockName, "other", now, time.Hour); err != nil || !ok {
+		t.Fatalf("lock: %v %v", ok, err)
+	}
+
+	n := &recorder{channel: "test"}
+	if err := f.scheduler(now, n).RunOnce(ctx); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(n.sent) != 0 {
+		t.Fatalf("want no reminders while the lock is held, got %+v", n.sent)
+	}
+
+	// the lease of a replica that died runs out
+	s := f.scheduler(now.Add(time.Hour), n)
+	if err := s.RunOnce(ctx); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(n.sent) != 1 {
+		t.Fatalf("want the reminder once the lease expired, got %+v", n.sent)
+	}
+	if ok, err := f.locks.TryLock(ctx, lockName, "other", now.Add(time.Hour), time.Hour); err != nil || !ok {
+		t.Fatalf("want the lock released after the run, got %v %v", ok, err)
+	}
+}
+
+func TestScheduler_Next(t *testing.T) {
+	s := NewScheduler(nil, nil, nil, Options{RunAt: 9 * time.Hour}, zap.NewNop().Sugar())
+	for _, tc := range []struct{ now, want time.Time }{
+		{time.Date(2025, 7, 30, 8, 0, 0, 0, time.UTC), time.Date(2025, 7, 30, 9, 0, 0, 0, time.UTC)},
+		{time.Date(2025, 7, 30, 9, 0, 0, 0, time.UTC), time.Date(2025, 7, 31, 9, 0, 0, 0, time.UTC)},
+		{time.Date(2025, 7, 31, 23, 0, 0, 0, time.UTC), time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)},
+	} {
+		if got := s.next(tc.now); !got.Equal(tc.want) {
+			t.Errorf("next(%v) = %v, want %v", tc.now, got, tc.want)
+		}
+	}
+}
+
+func TestWebhookNotifier_AppendsAnEvent(t *testing.T) {
+	f := newFixture()
+	july := month(2025, 7)
+	sub := f.create(t, "ending", month(2025, 1), &july)
+	ctx := context.Background()
+	events := memrepo.NewMemoryEventRepo(f.store)
+	last, err := events.LastID(ctx)
+	if err != nil {
+		t.Fatalf("last id: %v", err)
+	}
+
+	r := &domain.Reminder{ID: uuid.New(), SubscriptionID: sub.ID, Kind: domain.ReminderEnding, DueOn: month(2025, 8), Channel: ChannelWebhook}
+	if err := NewWebhookNotifier(memrepo.NewMemoryOutboxRepo(f.store)).Notify(ctx, r, sub); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	got, err := events.After(ctx, last, 10)
+	if err != nil {
+		t.Fatalf("after: %v", err)
+	}
+	if len(got) != 1 || got[0].Type != domain.EventSubscriptionEndingSoon || got[0].Data.DueOn == nil || !got[0].Data.DueOn.Equal(r.DueOn) {
+		t.Fatalf("want a subscription.ending_soon event due in August, got %+v", got)
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,123 @@
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"subcalc/internal/domain"
+	"time"
+)
+
+// smtpTimeout bounds a whole mail transaction.
+const smtpTimeout = 30 * time.Second
+
+type SMTPOptions struct {
+	Host string
+	Port int
+	// Username and Password authenticate with PLAIN when set; leave them
+	// empty for a local mail catcher.
+	Username string
+	Password string
+	From     string
+	// To receives the reminders of every organization.
+	To []string
+}
+
+// SMTPNotifier mails reminders. STARTTLS is used when the server offers
+// it.
+type SMTPNotifier struct {
+	opts SMTPOptions
+}
+
+func NewSMTPNotifier(opts SMTPOptions) *SMTPNotifier {
+	return &SMTPNotifier{opts: opts}
+}
+
+func (n *SMTPNotifier) Channel() string { return ChannelSMTP }
+
+func (n *SMTPNotifier) Notify(ctx context.Context, r *domain.Reminder, sub *domain.Subscription) error {
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(n.opts.Host, strconv.Itoa(n.opts.Port)))
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	c, err := smtp.NewClient(conn, n.opts.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.opts.Host}); err != nil {
+			return err
+		}
+	}
+	if n.opts.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", n.opts.Username, n.opts.Password, n.opts.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(n.opts.From); err != nil {
+		return err
+	}
+	for _, to := range n.opts.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(n.message(r, sub)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message is the mail of a reminder. Its Message-ID derives from the
+// reminder, so a mail sent again after a lost reply can be told apart as
+// a duplicate.
+func (n *SMTPNotifier) message(r *domain.Reminder, sub *domain.Subscription) []byte {
+	var b bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&b, "%s: %s\r\n", k, v) }
+	header("From", n.opts.From)
+	header("To", strings.Join(n.opts.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", summary(r, sub)))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s@subcalc>", r.ID))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "8bit")
+	b.WriteString("\r\n")
+
+	fmt.Fprintf(&b, "%s.\r\n\r\n", summary(r, sub))
+	fmt.Fprintf(&b, "Service:      %s\r\n", sub.ServiceName)
+	fmt.Fprintf(&b, "Price:        %d\r\n", sub.Price)
+	fmt.Fprintf(&b, "Started:      %s\r\n", domain.FormatMonthYear(sub.StartDate))
+	if sub.EndDate != nil {
+		fmt.Fprintf(&b, "Last month:   %s\r\n", domain.FormatMonthYear(*sub.EndDate))
+	}
+	fmt.Fprintf(&b, "Subscription: %s\r\n", sub.ID)
+	fmt.Fprintf(&b, "User:         %s\r\n", sub.UserID)
+	fmt.Fprintf(&b, "Organization: %s\r\n", sub.OrgID)
+	return b.Bytes()
+}
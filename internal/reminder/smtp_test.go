@@ -0,0 +1,104 @@
+package reminder
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"subcalc/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// mailCatcher accepts one plain SMTP session and returns what it was told.
+func mailCatcher(t *testing.T) (port int, got <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	ch := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var b strings.Builder
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 catcher")
+		data := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			b.WriteString(line)
+			switch {
+			case data:
+				if line == ".\r\n" {
+					data = false
+					reply("250 queued")
+				}
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 catcher")
+			case strings.HasPrefix(line, "DATA"):
+				data = true
+				reply("354 go ahead")
+			case strings.HasPrefix(line, "QUIT"):
+				reply("221 bye")
+				ch <- b.String()
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port, ch
+}
+
+func TestSMTPNotifier_SendsTheReminder(t *testing.T) {
+	port, got := mailCatcher(t)
+	n := NewSMTPNotifier(SMTPOptions{Host: "127.0.0.1", Port: port, From: "reminders@subcalc.local", To: []string{"billing@example.com"}})
+	sub := &domain.Subscription{ID: uuid.New(), ServiceName: "Yandex Plus", Price: 400, UserID: uuid.New(), StartDate: month(2025, 1)}
+	r := &domain.Reminder{ID: uuid.New(), SubscriptionID: sub.ID, Kind: domain.ReminderRenewal, DueOn: month(2025, 8), Channel: ChannelSMTP}
+
+	if err := n.Notify(context.Background(), r, sub); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	var session string
+	select {
+	case session = <-got:
+	case <-time.After(time.Second):
+		t.Fatalf("no mail")
+	}
+	for _, want := range []string{
+		"MAIL FROM:<reminders@subcalc.local>",
+		"RCPT TO:<billing@example.com>",
+		"Subject: Yandex Plus renews on 2025-08-01 for 400",
+		"Message-ID: <" + r.ID.String() + "@subcalc>",
+	} {
+		if !strings.Contains(session, want) {
+			t.Errorf("session lacks %q:\n%s", want, session)
+		}
+	}
+}
+
+func TestSMTPNotifier_FailsWithoutAServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	n := NewSMTPNotifier(SMTPOptions{Host: "127.0.0.1", Port: port, From: "a@b.c", To: []string{"d@e.f"}})
+	sub := &domain.Subscription{ID: uuid.New(), ServiceName: "x", StartDate: month(2025, 1)}
+	if err := n.Notify(context.Background(), &domain.Reminder{Kind: domain.ReminderRenewal}, sub); err == nil {
+		t.Fatalf("want an error without a server")
+	}
+}
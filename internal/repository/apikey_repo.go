@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"subcalc/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+	GetByHash(ctx context.Context, hash string) (*domain.APIKey, error)
+	List(ctx context.Context) ([]*domain.APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID, at time.Time) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"subcalc/internal/domain"
+)
+
+// EventRepository reads the outbox as a log of subscription events, in id
+// order. Events stay readable until the outbox is pruned.
+type EventRepository interface {
+	// After returns up to limit events of the tenant with ids greater than
+	// afterID, oldest first.
+	After(ctx context.Context, afterID int64, limit int) ([]*domain.Event, error)
+	// AllAfter is After across every tenant.
+	AllAfter(ctx context.Context, afterID int64, limit int) ([]*domain.Event, error)
+	// LastID is the greatest event id, 0 when the log is empty.
+	LastID(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,125 @@
+package gormrepo
+
+import (
+	"context"
+	"strings"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"subcalc/internal/tenant"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GormAPIKey struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	OrgID      uuid.UUID  `gorm:"type:uuid;index;not null"`
+	Name       string     `gorm:"type:text;not null"`
+	Prefix     string     `gorm:"type:text;not null"`
+	Hash       string     `gorm:"type:text;not null;uniqueIndex:idx_api_keys_hash"`
+	Scopes     string     `gorm:"type:text;not null;default:''"`
+	CreatedAt  time.Time  `gorm:"not null"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at"`
+}
+
+func (g *GormAPIKey) TableName() string {
+	return "api_keys"
+}
+
+// Scopes are stored space-separated, like an OAuth scope string.
+func (g *GormAPIKey) ToDomain() *domain.APIKey {
+	return &domain.APIKey{
+		ID:         g.ID,
+		OrgID:      g.OrgID,
+		Name:       g.Name,
+		Prefix:     g.Prefix,
+		Hash:       g.Hash,
+		Scopes:     strings.Fields(g.Scopes),
+		CreatedAt:  g.CreatedAt,
+		LastUsedAt: g.LastUsedAt,
+		RevokedAt:  g.RevokedAt,
+	}
+}
+
+func APIKeyFromDomain(d *domain.APIKey) *GormAPIKey {
+	return &GormAPIKey{
+		ID:         d.ID,
+		OrgID:      d.OrgID,
+		Name:       d.Name,
+		Prefix:     d.Prefix,
+		Hash:       d.Hash,
+		Scopes:     strings.Join(d.Scopes, " "),
+		CreatedAt:  d.CreatedAt,
+		LastUsedAt: d.LastUsedAt,
+		RevokedAt:  d.RevokedAt,
+	}
+}
+
+type apiKeyRepo struct {
+	db *gorm.DB
+}
+
+func NewGormAPIKeyRepo(db *gorm.DB) repository.APIKeyRepository {
+	return &apiKeyRepo{db: db}
+}
+
+func (r *apiKeyRepo) scoped(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Where("org_id = ?", tenant.OrgID(ctx))
+}
+
+func (r *apiKeyRepo) Create(ctx context.Context, key *domain.APIKey) error {
+	if key.ID == uuid.Nil {
+		key.ID = uuid.New()
+	}
+	key.OrgID = tenant.OrgID(ctx)
+	g := APIKeyFromDomain(key)
+	if err := r.db.WithContext(ctx).Create(g).Error; err != nil {
+		return translateError(r.db, err)
+	}
+	key.CreatedAt = g.CreatedAt
+	return nil
+}
+
+func (r *apiKeyRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	return firstAPIKey(r.scoped(ctx).Where("id = ?", id))
+}
+
+// GetByHash is not tenant-scoped: keys are looked up before the tenant is
+// known, and the key itself determines it.
+func (r *apiKeyRepo) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	return firstAPIKey(r.db.WithContext(ctx).Where("hash = ?", hash))
+}
+
+func firstAPIKey(q *gorm.DB) (*domain.APIKey, error) {
+	var g GormAPIKey
+	if err := q.First(&g).Error; err != nil {
+		return nil, translateError(q, err)
+	}
+	return g.ToDomain(), nil
+}
+
+func (r *apiKeyRepo) List(ctx context.Context) ([]*domain.APIKey, error) {
+	var gs []GormAPIKey
+	if err := r.scoped(ctx).Order("created_at DESC").Find(&gs).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*domain.APIKey, 0, len(gs))
+	for _, g := range gs {
+		out = append(out, g.ToDomain())
+	}
+	return out, nil
+}
+
+func (r *apiKeyRepo) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.scoped(ctx).Model(&GormAPIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+func (r *apiKeyRepo) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&GormAPIKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+}
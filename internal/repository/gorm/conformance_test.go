@@ -0,0 +1,124 @@
+package gormrepo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"subcalc/internal/infrastructure/db"
+	"subcalc/internal/repository/repotest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// defaultTestDSN is tried when TEST_POSTGRES_DSN is not set, matching the
+// docker-compose database published on localhost.
+const defaultTestDSN = "host=localhost port=5432 user=postgres password=postgres dbname=subscriptions sslmode=disable"
+
+func TestConformance_SQLite(t *testing.T) {
+	repotest.RunSubscriptionRepository(t, func(t *testing.T) repotest.Harness {
+		gdb := newSQLite(t)
+		return repotest.Harness{
+			Repo:      NewSQLiteSubscriptionRepo(gdb),
+			Webhooks:  NewGormWebhookRepo(gdb),
+			Outbox:    NewGormOutboxRepo(gdb),
+			Events:    NewGormEventRepo(gdb),
+			Reminders: NewGormReminderRepo(gdb),
+			Locks:     NewGormLockRepo(gdb),
+			OtherOrg:  createOrg(t, gdb),
+		}
+	})
+}
+
+// TestConformance_Postgres runs against the database at TEST_POSTGRES_DSN,
+// or a local one, in a throwaway schema. It is skipped when no database
+// answers.
+func TestConformance_Postgres(t *testing.T) {
+	gdb := openTestPostgres(t)
+	otherOrg := createOrg(t, gdb)
+
+	repotest.RunSubscriptionRepository(t, func(t *testing.T) repotest.Harness {
+		if err := gdb.Exec("TRUNCATE subscriptions, outbox_events, webhooks, webhook_deliveries, reminders, scheduler_locks").Error; err != nil {
+			t.Fatalf("truncate: %v", err)
+		}
+		return repotest.Harness{
+			Repo:      NewGormSubscriptionRepo(gdb),
+			Webhooks:  NewGormWebhookRepo(gdb),
+			Outbox:    NewGormOutboxRepo(gdb),
+			Events:    NewGormEventRepo(gdb),
+			Reminders: NewGormReminderRepo(gdb),
+			Locks:     NewGormLockRepo(gdb),
+			OtherOrg:  otherOrg,
+		}
+	})
+}
+
+func openTestPostgres(t *testing.T) *gorm.DB {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping Postgres tests in short mode")
+	}
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		dsn = defaultTestDSN
+	}
+	quiet := &gorm.Config{Logger: logger.Discard}
+
+	admin, err := gorm.Open(postgres.Open(dsn+" connect_timeout=2"), quiet)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		sqlDB, _ := admin.DB()
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		t.Skipf("no Postgres available (set TEST_POSTGRES_DSN): %v", err)
+	}
+
+	suffix := make([]byte, 6)
+	_, _ = rand.Read(suffix)
+	schema := "conformance_" + hex.EncodeToString(suffix)
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	gdb, err := gorm.Open(postgres.Open(fmt.Sprintf("%s search_path=%s", dsn, schema)), quiet)
+	if err != nil {
+		t.Fatalf("open schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := gdb.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	m, err := db.NewMigrator(gdb, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatalf("migrator: %v", err)
+	}
+	if err := m.Up(context.Background()); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	return gdb
+}
+
+func createOrg(t *testing.T, gdb *gorm.DB) uuid.UUID {
+	t.Helper()
+	org := GormOrganization{ID: uuid.New(), Name: "other", DefaultCurrency: "EUR"}
+	if err := gdb.Create(&org).Error; err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	return org.ID
+}
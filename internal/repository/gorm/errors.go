@@ -0,0 +1,24 @@
+package gormrepo
+
+import (
+	"errors"
+	"subcalc/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// translateError maps the errors of db onto the typed errors of the domain,
+// letting the dialect recognise its own constraint violations so that
+// callers need not enable gorm's TranslateError.
+func translateError(db *gorm.DB, err error) error {
+	if t, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		err = t.Translate(err)
+	}
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return domain.ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return domain.ErrConflict
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+package gormrepo
+
+import (
+	"context"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type eventRepo struct {
+	db *gorm.DB
+}
+
+func NewGormEventRepo(db *gorm.DB) repository.EventRepository {
+	return &eventRepo{db: db}
+}
+
+func (r *eventRepo) After(ctx context.Context, afterID int64, limit int) ([]*domain.Event, error) {
+	return findEvents(tenantScope(ctx, r.db.WithContext(ctx)), afterID, limit)
+}
+
+func (r *eventRepo) AllAfter(ctx context.Context, afterID int64, limit int) ([]*domain.Event, error) {
+	return findEvents(r.db.WithContext(ctx), afterID, limit)
+}
+
+func findEvents(q *gorm.DB, afterID int64, limit int) ([]*domain.Event, error) {
+	var gs []GormEvent
+	if err := q.Where("id > ?", afterID).Order("id").Limit(limit).Find(&gs).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*domain.Event, 0, len(gs))
+	for _, g := range gs {
+		e, err := g.ToDomain()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func (r *eventRepo) LastID(ctx context.Context) (int64, error) {
+	var id int64
+	err := r.db.WithContext(ctx).Model(&GormEvent{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
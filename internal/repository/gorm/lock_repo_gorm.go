@@ -0,0 +1,46 @@
+package gormrepo
+
+import (
+	"context"
+	"subcalc/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormLock struct {
+	Name      string    `gorm:"type:text;primaryKey"`
+	Holder    string    `gorm:"type:text;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+}
+
+func (g *GormLock) TableName() string {
+	return "scheduler_locks"
+}
+
+type lockRepo struct {
+	db *gorm.DB
+}
+
+func NewGormLockRepo(db *gorm.DB) repository.LockRepository {
+	return &lockRepo{db: db}
+}
+
+// TryLock is a single upsert whose update only applies to a lease that is
+// expired or the holder's own, so of several replicas racing for a lease
+// exactly one affects a row.
+func (r *lockRepo) TryLock(ctx context.Context, name, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"holder", "expires_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			gorm.Expr("scheduler_locks.expires_at <= ? OR scheduler_locks.holder = ?", now, holder),
+		}},
+	}).Create(&GormLock{Name: name, Holder: holder, ExpiresAt: now.Add(ttl)})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *lockRepo) Unlock(ctx context.Context, name, holder string) error {
+	return r.db.WithContext(ctx).Where("name = ? AND holder = ?", name, holder).Delete(&GormLock{}).Error
+}
@@ -0,0 +1,63 @@
+package gormrepo
+
+import (
+	"context"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GormOrganization struct {
+	ID              uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name            string    `gorm:"type:text;not null"`
+	DefaultCurrency string    `gorm:"type:char(3);not null;default:'RUB'"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (g *GormOrganization) TableName() string {
+	return "organizations"
+}
+
+func (g *GormOrganization) ToDomain() *domain.Organization {
+	return &domain.Organization{
+		ID:              g.ID,
+		Name:            g.Name,
+		DefaultCurrency: g.DefaultCurrency,
+		CreatedAt:       g.CreatedAt,
+		UpdatedAt:       g.UpdatedAt,
+	}
+}
+
+type orgRepo struct {
+	db *gorm.DB
+}
+
+func NewGormOrganizationRepo(db *gorm.DB) repository.OrganizationRepository {
+	return &orgRepo{db: db}
+}
+
+func (r *orgRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Organization, error) {
+	var g GormOrganization
+	if err := r.db.WithContext(ctx).First(&g, "id = ?", id).Error; err != nil {
+		return nil, translateError(r.db, err)
+	}
+	return g.ToDomain(), nil
+}
+
+func (r *orgRepo) Update(ctx context.Context, org *domain.Organization) error {
+	now := time.Now().UTC()
+	updates := map[string]interface{}{
+		"name":             org.Name,
+		"default_currency": org.DefaultCurrency,
+		"updated_at":       now,
+	}
+	if err := r.db.WithContext(ctx).Model(&GormOrganization{}).Where("id = ?", org.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+	org.UpdatedAt = now
+	return nil
+}
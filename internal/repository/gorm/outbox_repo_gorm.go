@@ -0,0 +1,213 @@
+package gormrepo
+
+import (
+	"context"
+	"encoding/json"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// outboxBatchSize bounds the rows written by one statement.
+const outboxBatchSize = 500
+
+type GormEvent struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement"`
+	OrgID        uuid.UUID `gorm:"type:uuid;not null"`
+	Type         string    `gorm:"type:text;not null"`
+	Data         string    `gorm:"type:text;not null"`
+	CreatedAt    time.Time `gorm:"not null"`
+	DispatchedAt *time.Time
+}
+
+func (g *GormEvent) TableName() string {
+	return "outbox_events"
+}
+
+func (g *GormEvent) ToDomain() (*domain.Event, error) {
+	e := &domain.Event{ID: g.ID, OrgID: g.OrgID, Type: domain.EventType(g.Type), CreatedAt: g.CreatedAt}
+	if err := json.Unmarshal([]byte(g.Data), &e.Data); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// appendEvents records events in the outbox within tx, the transaction of
+// the change they describe, and sets their ids and times.
+func appendEvents(tx *gorm.DB, events ...*domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	gs := make([]GormEvent, 0, len(events))
+	for _, e := range events {
+		data, err := json.Marshal(e.Data)
+		if err != nil {
+			return err
+		}
+		gs = append(gs, GormEvent{OrgID: e.OrgID, Type: string(e.Type), Data: string(data), CreatedAt: now})
+	}
+	if err := tx.CreateInBatches(&gs, outboxBatchSize).Error; err != nil {
+		return err
+	}
+	for i, e := range events {
+		e.ID = gs[i].ID
+		e.CreatedAt = now
+	}
+	return nil
+}
+
+type outboxRepo struct {
+	db *gorm.DB
+}
+
+func NewGormOutboxRepo(db *gorm.DB) repository.OutboxRepository {
+	return &outboxRepo{db: db}
+}
+
+func (r *outboxRepo) Append(ctx context.Context, events ...*domain.Event) error {
+	return appendEvents(r.db.WithContext(ctx), events...)
+}
+
+// DispatchEvents marks each event dispatched in its own transaction, with a
+// conditional update that only one of several concurrent workers wins; the
+// loser skips the event.
+func (r *outboxRepo) DispatchEvents(ctx context.Context, limit int) (int, error) {
+	var events []GormEvent
+	if err := r.db.WithContext(ctx).Where("dispatched_at IS NULL").Order("id").Limit(limit).Find(&events).Error; err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, g := range events {
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			now := time.Now().UTC()
+			res := tx.Model(&GormEvent{}).Where("id = ? AND dispatched_at IS NULL", g.ID).Update("dispatched_at", now)
+			if res.Error != nil || res.RowsAffected == 0 {
+				return res.Error
+			}
+			e, err := g.ToDomain()
+			if err != nil {
+				return err
+			}
+			payload, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+
+			var hooks []GormWebhook
+			if err := tx.Where("org_id = ? AND active = ?", g.OrgID, true).Find(&hooks).Error; err != nil {
+				return err
+			}
+			var deliveries []GormWebhookDelivery
+			for _, h := range hooks {
+				if !h.ToDomain().Wants(e.Type) {
+					continue
+				}
+				deliveries = append(deliveries, GormWebhookDelivery{
+					ID:            uuid.New(),
+					OrgID:         g.OrgID,
+					WebhookID:     h.ID,
+					EventID:       g.ID,
+					EventType:     g.Type,
+					Payload:       string(payload),
+					Status:        string(domain.DeliveryPending),
+					NextAttemptAt: &now,
+					CreatedAt:     now,
+				})
+			}
+			n++
+			if len(deliveries) == 0 {
+				return nil
+			}
+			return tx.Create(&deliveries).Error
+		})
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// ClaimDeliveries leases each delivery with a conditional update as well:
+// once one worker has moved next_attempt_at past now, the condition fails
+// for the others.
+func (r *outboxRepo) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]repository.DueDelivery, error) {
+	now = now.UTC()
+	var gs []GormWebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", string(domain.DeliveryPending), now).
+		Where("webhook_id IN (?)", r.db.Model(&GormWebhook{}).Select("id").Where("active = ?", true)).
+		Order("next_attempt_at").Limit(limit).Find(&gs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	leased := now.Add(lease)
+	var claimed []*domain.WebhookDelivery
+	hookIDs := map[uuid.UUID]bool{}
+	for _, g := range gs {
+		res := r.db.WithContext(ctx).Model(&GormWebhookDelivery{}).
+			Where("id = ? AND status = ? AND next_attempt_at <= ?", g.ID, string(domain.DeliveryPending), now).
+			Update("next_attempt_at", leased)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+		d := g.ToDomain()
+		d.NextAttemptAt = &leased
+		claimed = append(claimed, d)
+		hookIDs[d.WebhookID] = true
+	}
+	if len(claimed) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(hookIDs))
+	for id := range hookIDs {
+		ids = append(ids, id)
+	}
+	var hooks []GormWebhook
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&hooks).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*domain.Webhook, len(hooks))
+	for _, h := range hooks {
+		byID[h.ID] = h.ToDomain()
+	}
+	out := make([]repository.DueDelivery, 0, len(claimed))
+	for _, d := range claimed {
+		// a webhook deleted meanwhile took the delivery with it
+		if h, ok := byID[d.WebhookID]; ok {
+			out = append(out, repository.DueDelivery{Delivery: d, Webhook: h})
+		}
+	}
+	return out, nil
+}
+
+func (r *outboxRepo) SaveAttempt(ctx context.Context, d *domain.WebhookDelivery) error {
+	updates := map[string]interface{}{
+		"status":          string(d.Status),
+		"attempts":        d.Attempts,
+		"next_attempt_at": d.NextAttemptAt,
+		"last_attempt_at": d.LastAttemptAt,
+		"response_status": d.ResponseStatus,
+		"response_body":   d.ResponseBody,
+		"error":           d.Error,
+	}
+	return r.db.WithContext(ctx).Model(&GormWebhookDelivery{}).Where("id = ?", d.ID).Updates(updates).Error
+}
+
+func (r *outboxRepo) Prune(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Delete(&GormEvent{}, "dispatched_at IS NOT NULL AND created_at < ?", before)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	n := res.RowsAffected
+	res = r.db.WithContext(ctx).Delete(&GormWebhookDelivery{}, "status <> ? AND created_at < ?", string(domain.DeliveryPending), before)
+	return n + res.RowsAffected, res.Error
+}
@@ -0,0 +1,108 @@
+package gormrepo
+
+import (
+	"context"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormReminder struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey"`
+	OrgID          uuid.UUID `gorm:"type:uuid;not null"`
+	SubscriptionID uuid.UUID `gorm:"type:uuid;not null"`
+	Kind           string    `gorm:"type:text;not null"`
+	DueOn          time.Time `gorm:"type:date;not null"`
+	Channel        string    `gorm:"type:text;not null"`
+	Attempts       int       `gorm:"not null"`
+	SentAt         *time.Time
+	Error          string    `gorm:"type:text;not null"`
+	CreatedAt      time.Time `gorm:"not null"`
+	UpdatedAt      time.Time `gorm:"not null"`
+}
+
+func (g *GormReminder) TableName() string {
+	return "reminders"
+}
+
+func (g *GormReminder) ToDomain() *domain.Reminder {
+	return &domain.Reminder{
+		ID:             g.ID,
+		OrgID:          g.OrgID,
+		SubscriptionID: g.SubscriptionID,
+		Kind:           domain.ReminderKind(g.Kind),
+		DueOn:          g.DueOn.UTC(),
+		Channel:        g.Channel,
+		Attempts:       g.Attempts,
+		SentAt:         g.SentAt,
+		Error:          g.Error,
+		CreatedAt:      g.CreatedAt,
+		UpdatedAt:      g.UpdatedAt,
+	}
+}
+
+type reminderRepo struct {
+	db *gorm.DB
+}
+
+func NewGormReminderRepo(db *gorm.DB) repository.ReminderRepository {
+	return &reminderRepo{db: db}
+}
+
+func (r *reminderRepo) Due(ctx context.Context, month time.Time, afterID uuid.UUID, limit int) ([]*domain.Subscription, error) {
+	var gs []GormSubscription
+	err := r.db.WithContext(ctx).
+		Where("start_date < ? AND (end_date IS NULL OR end_date >= ?)", month, month.AddDate(0, -1, 0)).
+		Where("id > ?", afterID).
+		Order("id").Limit(limit).Find(&gs).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*domain.Subscription, 0, len(gs))
+	for i := range gs {
+		out = append(out, gs[i].ToDomain())
+	}
+	return out, nil
+}
+
+// Track inserts the reminder if the unique key lets it and reads back
+// whichever row holds the key.
+func (r *reminderRepo) Track(ctx context.Context, rem *domain.Reminder) (bool, error) {
+	now := time.Now().UTC()
+	g := GormReminder{
+		ID:             uuid.New(),
+		OrgID:          rem.OrgID,
+		SubscriptionID: rem.SubscriptionID,
+		Kind:           string(rem.Kind),
+		DueOn:          rem.DueOn,
+		Channel:        rem.Channel,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	db := r.db.WithContext(ctx)
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&g).Error; err != nil {
+		return false, err
+	}
+	var stored GormReminder
+	err := db.Where("subscription_id = ? AND kind = ? AND due_on = ? AND channel = ?", g.SubscriptionID, g.Kind, g.DueOn, g.Channel).
+		Take(&stored).Error
+	if err != nil {
+		return false, err
+	}
+	*rem = *stored.ToDomain()
+	return rem.SentAt == nil, nil
+}
+
+func (r *reminderRepo) SaveAttempt(ctx context.Context, rem *domain.Reminder) error {
+	rem.UpdatedAt = time.Now().UTC()
+	return r.db.WithContext(ctx).Model(&GormReminder{}).Where("id = ?", rem.ID).Updates(map[string]any{
+		"attempts":   rem.Attempts,
+		"sent_at":    rem.SentAt,
+		"error":      rem.Error,
+		"updated_at": rem.UpdatedAt,
+	}).Error
+}
@@ -0,0 +1,39 @@
+package gormrepo
+
+import (
+	"context"
+	"subcalc/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type statsRepo struct {
+	db *gorm.DB
+}
+
+func NewGormStatsRepo(db *gorm.DB) repository.StatsRepository {
+	return &statsRepo{db: db}
+}
+
+func (r *statsRepo) ActiveByOrg(ctx context.Context, at time.Time) (map[uuid.UUID]int64, error) {
+	month := dateTruncMonth(at)
+	var rows []struct {
+		OrgID uuid.UUID
+		N     int64
+	}
+	err := r.db.WithContext(ctx).Model(&GormSubscription{}).
+		Select("org_id, COUNT(*) AS n").
+		Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", month, month).
+		Group("org_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		out[row.OrgID] = row.N
+	}
+	return out, nil
+}
@@ -2,13 +2,21 @@ package gormrepo
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"subcalc/internal/domain"
 	"subcalc/internal/repository"
+	"subcalc/internal/search"
+	"subcalc/internal/tenant"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GormSubscription struct {
@@ -16,6 +24,7 @@ type GormSubscription struct {
 	ServiceName string     `json:"service_name" gorm:"type:text;not null"`
 	Price       int        `json:"price" gorm:"type:int;not null"`
 	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;index;not null"`
+	OrgID       uuid.UUID  `json:"org_id" gorm:"type:uuid;index;not null"`
 	StartDate   time.Time  `json:"start_date" gorm:"type:date;not null"`
 	EndDate     *time.Time `json:"end_date" gorm:"type:date"`
 	CreatedAt   time.Time  `json:"created_at"`
@@ -32,6 +41,7 @@ func (g *GormSubscription) ToDomain() *domain.Subscription {
 		ServiceName: g.ServiceName,
 		Price:       g.Price,
 		UserID:      g.UserID,
+		OrgID:       g.OrgID,
 		StartDate:   g.StartDate,
 		EndDate:     g.EndDate,
 		CreatedAt:   g.CreatedAt,
@@ -45,6 +55,7 @@ func FromDomain(d *domain.Subscription) *GormSubscription {
 		ServiceName: d.ServiceName,
 		Price:       d.Price,
 		UserID:      d.UserID,
+		OrgID:       d.OrgID,
 		StartDate:   d.StartDate,
 		EndDate:     d.EndDate,
 		CreatedAt:   d.CreatedAt,
@@ -52,6 +63,11 @@ func FromDomain(d *domain.Subscription) *GormSubscription {
 	}
 }
 
+// repo stores subscriptions with GORM. Every write records its events in
+// the outbox in the same transaction, so a change is never committed
+// without them, nor they without it. Rows are read for the events with FOR
+// UPDATE, which SQLite, holding a database lock for the whole transaction,
+// does without.
 type repo struct {
 	db *gorm.DB
 }
@@ -60,13 +76,31 @@ func NewGormSubscriptionRepo(db *gorm.DB) repository.SubscriptionRepository {
 	return &repo{db: db}
 }
 
+// scoped starts a query limited to the tenant of ctx. Every read and write
+// goes through it, or through tenantScope within a transaction, so no query
+// can leak rows across organizations.
+func (r *repo) scoped(ctx context.Context) *gorm.DB {
+	return tenantScope(ctx, r.db.WithContext(ctx))
+}
+
+func tenantScope(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return db.Where("org_id = ?", tenant.OrgID(ctx))
+}
+
 func (r *repo) Create(ctx context.Context, sub *domain.Subscription) error {
 	if sub.ID == uuid.Nil {
 		sub.ID = uuid.New()
 	}
+	sub.OrgID = tenant.OrgID(ctx)
 	g := FromDomain(sub)
-	if err := r.db.WithContext(ctx).Create(g).Error; err != nil {
-		return err
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(g).Error; err != nil {
+			return err
+		}
+		return appendEvents(tx, domain.SubscriptionEvent(domain.EventSubscriptionCreated, g.ToDomain()))
+	})
+	if err != nil {
+		return translateError(r.db, err)
 	}
 	sub.ID = g.ID
 	sub.CreatedAt = g.CreatedAt
@@ -76,11 +110,8 @@ func (r *repo) Create(ctx context.Context, sub *domain.Subscription) error {
 
 func (r *repo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
 	var g GormSubscription
-	if err := r.db.WithContext(ctx).First(&g, "id = ?", id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
-		return nil, err
+	if err := r.scoped(ctx).First(&g, "id = ?", id).Error; err != nil {
+		return nil, translateError(r.db, err)
 	}
 	return g.ToDomain(), nil
 }
@@ -95,41 +126,115 @@ func (r *repo) Update(ctx context.Context, sub *domain.Subscription) error {
 		"end_date":     sub.EndDate,
 		"updated_at":   now,
 	}
-	if err := r.db.WithContext(ctx).Model(&GormSubscription{}).Where("id = ?", sub.ID).Updates(updates).Error; err != nil {
-		return err
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var old []GormSubscription
+		if err := tenantScope(ctx, tx).Clauses(clause.Locking{Strength: "UPDATE"}).Find(&old, "id = ?", sub.ID).Error; err != nil {
+			return err
+		}
+		if len(old) == 0 {
+			return domain.ErrNotFound
+		}
+		if err := tenantScope(ctx, tx).Model(&GormSubscription{}).Where("id = ?", sub.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		updated := old[0]
+		updated.ServiceName = sub.ServiceName
+		updated.Price = sub.Price
+		updated.UserID = sub.UserID
+		updated.StartDate = sub.StartDate
+		updated.EndDate = sub.EndDate
+		updated.UpdatedAt = now
+		events := []*domain.Event{domain.SubscriptionEvent(domain.EventSubscriptionUpdated, updated.ToDomain())}
+		if updated.Price != old[0].Price {
+			e := domain.SubscriptionEvent(domain.EventSubscriptionPriceChanged, updated.ToDomain())
+			e.Data.PreviousPrice = &old[0].Price
+			events = append(events, e)
+		}
+		return appendEvents(tx, events...)
+	})
+	if err != nil {
+		return translateError(r.db, err)
 	}
 	sub.UpdatedAt = now
 	return nil
 }
 
 func (r *repo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&GormSubscription{}, "id = ?", id).Error
-}
-
-func (r *repo) List(ctx context.Context, filter repository.SubscriptionFilter) ([]*domain.Subscription, error) {
-	var gs []GormSubscription
-	q := r.db.WithContext(ctx).Model(&GormSubscription{})
-
-	if filter.ServiceName != nil {
-		q = q.Where("service_name = ?", *filter.ServiceName)
+	n, err := r.deleteWhere(ctx, "id = ?", id)
+	if err != nil {
+		return translateError(r.db, err)
 	}
-	if filter.UserID != nil {
-		q = q.Where("user_id = ?", *filter.UserID)
+	if n == 0 {
+		return domain.ErrNotFound
 	}
+	return nil
+}
 
-	if filter.From != nil && filter.To != nil {
-		q = q.Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", *filter.To, *filter.From)
-	} else if filter.From != nil {
-		q = q.Where("end_date IS NULL OR end_date >= ?", *filter.From)
-	} else if filter.To != nil {
-		q = q.Where("start_date <= ?", *filter.To)
+func (r *repo) DeleteMany(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
 	}
+	return r.deleteWhere(ctx, "id IN ?", ids)
+}
+
+func (r *repo) DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return r.deleteWhere(ctx, "user_id = ?", userID)
+}
+
+// deleteWhere deletes the subscriptions of the tenant matching the
+// condition, recording a deletion event with the last state of each.
+func (r *repo) deleteWhere(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var gs []GormSubscription
+		if err := tenantScope(ctx, tx).Clauses(clause.Locking{Strength: "UPDATE"}).Where(query, args...).Find(&gs).Error; err != nil {
+			return err
+		}
+		if len(gs) == 0 {
+			return nil
+		}
+		ids := make([]uuid.UUID, 0, len(gs))
+		events := make([]*domain.Event, 0, len(gs))
+		for _, g := range gs {
+			ids = append(ids, g.ID)
+			events = append(events, domain.SubscriptionEvent(domain.EventSubscriptionDeleted, g.ToDomain()))
+		}
+		// in batches, to stay under the bind parameter limit of Postgres
+		for batch := range slices.Chunk(ids, outboxBatchSize) {
+			res := tenantScope(ctx, tx).Delete(&GormSubscription{}, "id IN ?", batch)
+			if res.Error != nil {
+				return res.Error
+			}
+			n += res.RowsAffected
+		}
+		return appendEvents(tx, events...)
+	})
+	return n, err
+}
+
+func (r *repo) List(ctx context.Context, filter repository.SubscriptionFilter) ([]*domain.Subscription, error) {
+	var gs []GormSubscription
+	q := applyFilter(r.scoped(ctx).Model(&GormSubscription{}), filter)
 
 	if filter.Limit == 0 {
 		filter.Limit = 100
 	}
-	if filter.Offset > 0 {
-		q = q.Offset(filter.Offset)
+	switch c := filter.Cursor; {
+	case c == nil:
+		for _, o := range filter.Sort {
+			q = q.Order(orderBy(o))
+		}
+		q = q.Order("created_at, id")
+		if filter.Offset > 0 {
+			q = q.Offset(filter.Offset)
+		}
+	case c.Backward:
+		q = q.Where("created_at < ? OR (created_at = ? AND id < ?)", c.CreatedAt, c.CreatedAt, c.ID).
+			Order("created_at DESC, id DESC")
+	default:
+		q = q.Where("created_at > ? OR (created_at = ? AND id > ?)", c.CreatedAt, c.CreatedAt, c.ID).
+			Order("created_at, id")
 	}
 	if err := q.Limit(filter.Limit).Find(&gs).Error; err != nil {
 		return nil, err
@@ -138,25 +243,15 @@ func (r *repo) List(ctx context.Context, filter repository.SubscriptionFilter) (
 	for _, g := range gs {
 		out = append(out, g.ToDomain())
 	}
+	if filter.Cursor != nil && filter.Cursor.Backward {
+		slices.Reverse(out)
+	}
 	return out, nil
 }
 
 func (r *repo) FindForPeriod(ctx context.Context, filter repository.SubscriptionFilter) ([]*domain.Subscription, error) {
 	var gs []GormSubscription
-	q := r.db.WithContext(ctx).Model(&GormSubscription{})
-	if filter.ServiceName != nil {
-		q = q.Where("service_name = ?", *filter.ServiceName)
-	}
-	if filter.UserID != nil {
-		q = q.Where("user_id = ?", *filter.UserID)
-	}
-	if filter.From != nil && filter.To != nil {
-		q = q.Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", *filter.To, *filter.From)
-	} else if filter.From != nil {
-		q = q.Where("end_date IS NULL OR end_date >= ?", *filter.From)
-	} else if filter.To != nil {
-		q = q.Where("start_date <= ?", *filter.To)
-	}
+	q := applyFilter(r.scoped(ctx).Model(&GormSubscription{}), filter)
 	if filter.Limit == 0 {
 		filter.Limit = 1000
 	}
@@ -175,13 +270,42 @@ func (r *repo) FindForPeriod(ctx context.Context, filter repository.Subscription
 
 func (r *repo) Count(ctx context.Context, filter repository.SubscriptionFilter) (int64, error) {
 	var count int64
-	q := r.db.WithContext(ctx).Model(&GormSubscription{})
+	q := applyFilter(r.scoped(ctx).Model(&GormSubscription{}), filter)
+	if err := q.Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// applyFilter adds the conditions of filter to q. It is the WHERE clause of
+// List, FindForPeriod and Count, and the row selection of SumForPeriod.
+func applyFilter(q *gorm.DB, filter repository.SubscriptionFilter) *gorm.DB {
 	if filter.UserID != nil {
 		q = q.Where("user_id = ?", *filter.UserID)
 	}
+	if len(filter.UserIDs) > 0 {
+		q = q.Where("user_id IN ?", filter.UserIDs)
+	}
+
+	name := "service_name"
+	if filter.IgnoreCase {
+		name = "LOWER(service_name)"
+	}
 	if filter.ServiceName != nil {
-		q = q.Where("service_name = ?", *filter.ServiceName)
+		q = q.Where(name+" = ?", foldCase(*filter.ServiceName, filter.IgnoreCase))
 	}
+	// SUBSTR rather than LIKE: SQLite's LIKE ignores case, Postgres' does not
+	if p := filter.ServiceNamePrefix; p != nil && *p != "" {
+		q = q.Where("SUBSTR("+name+", 1, ?) = ?", utf8.RuneCountInString(*p), foldCase(*p, filter.IgnoreCase))
+	}
+
+	if filter.PriceMin != nil {
+		q = q.Where("price >= ?", *filter.PriceMin)
+	}
+	if filter.PriceMax != nil {
+		q = q.Where("price <= ?", *filter.PriceMax)
+	}
+
 	if filter.From != nil && filter.To != nil {
 		q = q.Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", *filter.To, *filter.From)
 	} else if filter.From != nil {
@@ -189,10 +313,97 @@ func (r *repo) Count(ctx context.Context, filter repository.SubscriptionFilter)
 	} else if filter.To != nil {
 		q = q.Where("start_date <= ?", *filter.To)
 	}
-	if err := q.Count(&count).Error; err != nil {
-		return 0, err
+	if filter.ActiveOn != nil {
+		m := dateTruncMonth(*filter.ActiveOn)
+		q = q.Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", m, m)
 	}
-	return count, nil
+	if filter.OpenEnded != nil {
+		if *filter.OpenEnded {
+			q = q.Where("end_date IS NULL")
+		} else {
+			q = q.Where("end_date IS NOT NULL")
+		}
+	}
+
+	if filter.CreatedAfter != nil {
+		q = q.Where("created_at > ?", *filter.CreatedAfter)
+	}
+	if filter.UpdatedAfter != nil {
+		q = q.Where("updated_at > ?", *filter.UpdatedAfter)
+	}
+	return q
+}
+
+// foldCase lowers s to compare with LOWER(column). SQLite only lowers
+// ASCII letters, so on SQLite other letters still compare by case.
+func foldCase(s string, fold bool) string {
+	if fold {
+		return strings.ToLower(s)
+	}
+	return s
+}
+
+// orderBy is the ORDER BY term of o. Both dialects are told where NULL end
+// dates go: Postgres puts them last ascending, SQLite first.
+func orderBy(o repository.Sort) string {
+	switch {
+	case o.Key == repository.SortEndDate && o.Desc:
+		return "end_date DESC NULLS FIRST"
+	case o.Key == repository.SortEndDate:
+		return "end_date ASC NULLS LAST"
+	case o.Desc:
+		return string(o.Key) + " DESC"
+	}
+	return string(o.Key) + " ASC"
+}
+
+// searchRow is a subscription with its search score.
+type searchRow struct {
+	GormSubscription `gorm:"embedded"`
+	Score            float64
+}
+
+// Search ranks with pg_trgm. The % and <% operators are what the trigram
+// index serves; the word similarity threshold they use is lowered to the
+// similarity one for the transaction, so that "yandx" finds "Yandex Plus".
+func (r *repo) Search(ctx context.Context, query string, filter repository.SubscriptionFilter) ([]repository.SearchHit, error) {
+	limit := filter.Limit
+	if limit == 0 {
+		limit = 20
+	}
+	filter.Sort, filter.Offset, filter.Cursor = nil, 0, nil
+
+	var rows []searchRow
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		set := fmt.Sprintf("SET LOCAL pg_trgm.similarity_threshold = %g; SET LOCAL pg_trgm.word_similarity_threshold = %g", search.Threshold, search.Threshold)
+		if err := tx.Exec(set).Error; err != nil {
+			return err
+		}
+		q := applyFilter(tx.Model(&GormSubscription{}).Where("org_id = ?", tenant.OrgID(ctx)), filter).
+			Select("*, GREATEST(similarity(service_name, ?), word_similarity(?, service_name)) AS score", query, query).
+			Where("service_name % ? OR ? <% service_name", query, query).
+			Order("score DESC, created_at, id")
+		if limit > 0 {
+			q = q.Limit(limit)
+		}
+		return q.Scan(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]repository.SearchHit, 0, len(rows))
+	for _, row := range rows {
+		hits = append(hits, repository.SearchHit{Subscription: row.ToDomain(), Score: row.Score})
+	}
+	return hits, nil
+}
+
+// periodRows selects the rows SumForPeriod charges for: those matching
+// filter apart from the period, which the sum queries apply themselves.
+func (r *repo) periodRows(ctx context.Context, filter repository.SubscriptionFilter) *gorm.DB {
+	filter.From, filter.To = nil, nil
+	return applyFilter(r.scoped(ctx).Model(&GormSubscription{}), filter).
+		Select("start_date, end_date, price")
 }
 
 func (r *repo) SumForPeriod(ctx context.Context, filter repository.SubscriptionFilter) (int64, error) {
@@ -203,26 +414,14 @@ func (r *repo) SumForPeriod(ctx context.Context, filter repository.SubscriptionF
 	from := dateTruncMonth(*filter.From)
 	to := dateTruncMonth(*filter.To)
 
-	where := " WHERE start_date <= ?::date AND (end_date IS NULL OR end_date >= ?::date)"
-	whereArgs := []interface{}{to, from}
-
-	if filter.ServiceName != nil {
-		where = where + " AND service_name = ?"
-		whereArgs = append(whereArgs, *filter.ServiceName)
-	}
-	if filter.UserID != nil {
-		where = where + " AND user_id = ?"
-		whereArgs = append(whereArgs, *filter.UserID)
-	}
-
 	base := `
 WITH periods AS (
   SELECT
     GREATEST(start_date, ?::date) AS s,
     LEAST(COALESCE(end_date, ?::date), ?::date) AS e,
     price
-  FROM subscriptions
-  ` + where + `
+  FROM (?) AS subs
+  WHERE start_date <= ?::date AND (end_date IS NULL OR end_date >= ?::date)
 )
 SELECT COALESCE(SUM(price * (
   (DATE_PART('year', AGE(e, s)) * 12) + DATE_PART('month', AGE(e, s)) + 1
@@ -230,9 +429,9 @@ SELECT COALESCE(SUM(price * (
 FROM periods
 WHERE e >= s
 `
-	args := make([]interface{}, 0, 8)
-	args = append(args, from, to, to)
-	args = append(args, whereArgs...)
+	args := []interface{}{from, to, to, r.periodRows(ctx, filter), to, from}
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("db.statement", base))
 
 	var res struct {
 		Total int64 `gorm:"column:total"`
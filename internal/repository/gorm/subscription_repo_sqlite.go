@@ -0,0 +1,82 @@
+package gormrepo
+
+import (
+	"context"
+	"subcalc/internal/repository"
+	"subcalc/internal/search"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// sqliteRepo is the subscription repository on SQLite. SumForPeriod differs,
+// as the Postgres query relies on AGE, DATE_PART, GREATEST and ::date, and
+// so does Search, which relies on pg_trgm.
+type sqliteRepo struct {
+	*repo
+}
+
+func NewSQLiteSubscriptionRepo(db *gorm.DB) repository.SubscriptionRepository {
+	return &sqliteRepo{repo: &repo{db: db}}
+}
+
+// SumForPeriod computes the same total as the Postgres query. Dates are
+// stored as UTC text in one format, so comparing them as strings orders
+// them chronologically and CASE can stand in for GREATEST and LEAST. The
+// month count subtracts one when the end day is before the start day,
+// which is what whole months of AGE amount to.
+func (r *sqliteRepo) SumForPeriod(ctx context.Context, filter repository.SubscriptionFilter) (int64, error) {
+	if filter.From == nil || filter.To == nil {
+		return 0, nil
+	}
+	from := dateTruncMonth(*filter.From)
+	to := dateTruncMonth(*filter.To)
+
+	base := `
+WITH periods AS (
+  SELECT
+    CASE WHEN start_date > ? THEN start_date ELSE ? END AS s,
+    CASE WHEN end_date IS NOT NULL AND end_date < ? THEN end_date ELSE ? END AS e,
+    price
+  FROM (?) AS subs
+  WHERE start_date <= ? AND (end_date IS NULL OR end_date >= ?)
+)
+SELECT COALESCE(SUM(price * (
+  (CAST(strftime('%Y', e) AS INTEGER) - CAST(strftime('%Y', s) AS INTEGER)) * 12
+  + CAST(strftime('%m', e) AS INTEGER) - CAST(strftime('%m', s) AS INTEGER)
+  - (CAST(strftime('%d', e) AS INTEGER) < CAST(strftime('%d', s) AS INTEGER))
+  + 1
+)), 0) AS total
+FROM periods
+WHERE e >= s
+`
+
+	args := []interface{}{from, from, to, to, r.periodRows(ctx, filter), to, from}
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("db.statement", base))
+
+	var res struct {
+		Total int64 `gorm:"column:total"`
+	}
+	if err := r.db.WithContext(ctx).Raw(base, args...).Scan(&res).Error; err != nil {
+		return 0, err
+	}
+	return res.Total, nil
+}
+
+// Search ranks the tenant's matching subscriptions in Go, which reads all
+// of them; SQLite has no trigram index to narrow them down.
+func (r *sqliteRepo) Search(ctx context.Context, query string, filter repository.SubscriptionFilter) ([]repository.SearchHit, error) {
+	limit := filter.Limit
+	if limit == 0 {
+		limit = 20
+	}
+	filter.Sort, filter.Offset, filter.Cursor = nil, 0, nil
+	filter.Limit = -1
+	subs, err := r.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return search.Rank(subs, query, limit), nil
+}
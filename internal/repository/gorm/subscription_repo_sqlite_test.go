@@ -0,0 +1,44 @@
+package gormrepo
+
+import (
+	"context"
+	"path/filepath"
+	"subcalc/internal/config"
+	"subcalc/internal/infrastructure/db"
+	"testing"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func newSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	log := zap.NewNop().Sugar()
+	gdb, err := db.NewSQLite(&config.Config{DBPath: filepath.Join(t.TempDir(), "test.db")}, log)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	m, err := db.NewMigrator(gdb, log)
+	if err != nil {
+		t.Fatalf("migrator: %v", err)
+	}
+	if err := m.Up(context.Background()); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	return gdb
+}
+
+func TestSQLite_MigrationsGoDownAndUp(t *testing.T) {
+	gdb := newSQLite(t)
+	m, _ := db.NewMigrator(gdb, zap.NewNop().Sugar())
+	ctx := context.Background()
+	if err := m.To(ctx, 0); err != nil {
+		t.Fatalf("migrate down: %v", err)
+	}
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("migrate up again: %v", err)
+	}
+	if err := m.Check(ctx); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+}
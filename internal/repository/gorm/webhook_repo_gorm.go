@@ -0,0 +1,199 @@
+package gormrepo
+
+import (
+	"context"
+	"strings"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"subcalc/internal/tenant"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GormWebhook struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	OrgID     uuid.UUID `gorm:"type:uuid;index;not null"`
+	URL       string    `gorm:"type:text;not null"`
+	Secret    string    `gorm:"type:text;not null"`
+	Events    string    `gorm:"type:text;not null"`
+	Active    bool      `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (g *GormWebhook) TableName() string {
+	return "webhooks"
+}
+
+// Events are stored space-separated, like API key scopes.
+func (g *GormWebhook) ToDomain() *domain.Webhook {
+	fields := strings.Fields(g.Events)
+	events := make([]domain.EventType, 0, len(fields))
+	for _, f := range fields {
+		events = append(events, domain.EventType(f))
+	}
+	return &domain.Webhook{
+		ID:        g.ID,
+		OrgID:     g.OrgID,
+		URL:       g.URL,
+		Secret:    g.Secret,
+		Events:    events,
+		Active:    g.Active,
+		CreatedAt: g.CreatedAt,
+		UpdatedAt: g.UpdatedAt,
+	}
+}
+
+func WebhookFromDomain(d *domain.Webhook) *GormWebhook {
+	return &GormWebhook{
+		ID:        d.ID,
+		OrgID:     d.OrgID,
+		URL:       d.URL,
+		Secret:    d.Secret,
+		Events:    joinEvents(d.Events),
+		Active:    d.Active,
+		CreatedAt: d.CreatedAt,
+		UpdatedAt: d.UpdatedAt,
+	}
+}
+
+func joinEvents(events []domain.EventType) string {
+	s := make([]string, 0, len(events))
+	for _, e := range events {
+		s = append(s, string(e))
+	}
+	return strings.Join(s, " ")
+}
+
+type GormWebhookDelivery struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey"`
+	OrgID          uuid.UUID `gorm:"type:uuid;not null"`
+	WebhookID      uuid.UUID `gorm:"type:uuid;not null"`
+	EventID        int64     `gorm:"not null"`
+	EventType      string    `gorm:"type:text;not null"`
+	Payload        string    `gorm:"type:text;not null"`
+	Status         string    `gorm:"type:text;not null"`
+	Attempts       int       `gorm:"not null"`
+	NextAttemptAt  *time.Time
+	LastAttemptAt  *time.Time
+	ResponseStatus *int
+	ResponseBody   string `gorm:"type:text;not null"`
+	Error          string `gorm:"type:text;not null"`
+	CreatedAt      time.Time
+}
+
+func (g *GormWebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+func (g *GormWebhookDelivery) ToDomain() *domain.WebhookDelivery {
+	return &domain.WebhookDelivery{
+		ID:             g.ID,
+		OrgID:          g.OrgID,
+		WebhookID:      g.WebhookID,
+		EventID:        g.EventID,
+		EventType:      domain.EventType(g.EventType),
+		Payload:        []byte(g.Payload),
+		Status:         domain.DeliveryStatus(g.Status),
+		Attempts:       g.Attempts,
+		NextAttemptAt:  g.NextAttemptAt,
+		LastAttemptAt:  g.LastAttemptAt,
+		ResponseStatus: g.ResponseStatus,
+		ResponseBody:   g.ResponseBody,
+		Error:          g.Error,
+		CreatedAt:      g.CreatedAt,
+	}
+}
+
+type webhookRepo struct {
+	db *gorm.DB
+}
+
+func NewGormWebhookRepo(db *gorm.DB) repository.WebhookRepository {
+	return &webhookRepo{db: db}
+}
+
+func (r *webhookRepo) scoped(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Where("org_id = ?", tenant.OrgID(ctx))
+}
+
+func (r *webhookRepo) Create(ctx context.Context, hook *domain.Webhook) error {
+	if hook.ID == uuid.Nil {
+		hook.ID = uuid.New()
+	}
+	hook.OrgID = tenant.OrgID(ctx)
+	g := WebhookFromDomain(hook)
+	if err := r.db.WithContext(ctx).Create(g).Error; err != nil {
+		return translateError(r.db, err)
+	}
+	hook.CreatedAt = g.CreatedAt
+	hook.UpdatedAt = g.UpdatedAt
+	return nil
+}
+
+func (r *webhookRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	var g GormWebhook
+	if err := r.scoped(ctx).First(&g, "id = ?", id).Error; err != nil {
+		return nil, translateError(r.db, err)
+	}
+	return g.ToDomain(), nil
+}
+
+func (r *webhookRepo) List(ctx context.Context) ([]*domain.Webhook, error) {
+	var gs []GormWebhook
+	if err := r.scoped(ctx).Order("created_at, id").Find(&gs).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*domain.Webhook, 0, len(gs))
+	for _, g := range gs {
+		out = append(out, g.ToDomain())
+	}
+	return out, nil
+}
+
+func (r *webhookRepo) Update(ctx context.Context, hook *domain.Webhook) error {
+	now := time.Now().UTC()
+	updates := map[string]interface{}{
+		"url":        hook.URL,
+		"events":     joinEvents(hook.Events),
+		"active":     hook.Active,
+		"updated_at": now,
+	}
+	res := r.scoped(ctx).Model(&GormWebhook{}).Where("id = ?", hook.ID).Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	hook.UpdatedAt = now
+	return nil
+}
+
+// Delete relies on ON DELETE CASCADE to remove the deliveries.
+func (r *webhookRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	res := r.scoped(ctx).Delete(&GormWebhook{}, "id = ?", id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepo) ListDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]*domain.WebhookDelivery, error) {
+	var gs []GormWebhookDelivery
+	err := r.scoped(ctx).Where("webhook_id = ?", webhookID).
+		Order("created_at DESC, event_id DESC").Limit(limit).Find(&gs).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*domain.WebhookDelivery, 0, len(gs))
+	for _, g := range gs {
+		out = append(out, g.ToDomain())
+	}
+	return out, nil
+}
@@ -0,0 +1,108 @@
+package memrepo
+
+import (
+	"context"
+	"slices"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"subcalc/internal/tenant"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type apiKeyRepo struct {
+	s *Store
+}
+
+func NewMemoryAPIKeyRepo(s *Store) repository.APIKeyRepository {
+	return &apiKeyRepo{s: s}
+}
+
+func (r *apiKeyRepo) Create(ctx context.Context, key *domain.APIKey) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if key.ID == uuid.Nil {
+		key.ID = uuid.New()
+	}
+	if _, ok := r.s.apiKeys[key.ID]; ok {
+		return ErrDuplicateID
+	}
+	for _, k := range r.s.apiKeys {
+		if k.Hash == key.Hash {
+			return ErrDuplicateID
+		}
+	}
+	key.OrgID = tenant.OrgID(ctx)
+	key.CreatedAt = r.s.now()
+	c := *key
+	c.Scopes = slices.Clone(key.Scopes)
+	r.s.apiKeys[key.ID] = &c
+	return nil
+}
+
+func (r *apiKeyRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	key, ok := r.s.apiKeys[id]
+	if !ok || key.OrgID != tenant.OrgID(ctx) {
+		return nil, domain.ErrNotFound
+	}
+	return copyKey(key), nil
+}
+
+// GetByHash is not tenant-scoped, see the GORM implementation.
+func (r *apiKeyRepo) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, key := range r.s.apiKeys {
+		if key.Hash == hash {
+			return copyKey(key), nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *apiKeyRepo) List(ctx context.Context) ([]*domain.APIKey, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	orgID := tenant.OrgID(ctx)
+	out := []*domain.APIKey{}
+	for _, key := range r.s.apiKeys {
+		if key.OrgID == orgID {
+			out = append(out, copyKey(key))
+		}
+	}
+	slices.SortFunc(out, func(a, b *domain.APIKey) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	return out, nil
+}
+
+func (r *apiKeyRepo) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if key, ok := r.s.apiKeys[id]; ok && key.OrgID == tenant.OrgID(ctx) && key.RevokedAt == nil {
+		key.RevokedAt = &at
+	}
+	return nil
+}
+
+func (r *apiKeyRepo) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if key, ok := r.s.apiKeys[id]; ok {
+		key.LastUsedAt = &at
+	}
+	return nil
+}
+
+func copyKey(k *domain.APIKey) *domain.APIKey {
+	c := *k
+	c.Scopes = slices.Clone(k.Scopes)
+	return &c
+}
@@ -0,0 +1,24 @@
+package memrepo
+
+import (
+	"subcalc/internal/repository/repotest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.RunSubscriptionRepository(t, func(t *testing.T) repotest.Harness {
+		store := NewStore()
+		// the memory store does not check that organizations exist
+		return repotest.Harness{
+			Repo:      NewMemorySubscriptionRepo(store),
+			Webhooks:  NewMemoryWebhookRepo(store),
+			Outbox:    NewMemoryOutboxRepo(store),
+			Events:    NewMemoryEventRepo(store),
+			Reminders: NewMemoryReminderRepo(store),
+			Locks:     NewMemoryLockRepo(store),
+			OtherOrg:  uuid.New(),
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package memrepo
+
+import (
+	"context"
+	"sort"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"subcalc/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+type eventRepo struct {
+	s *Store
+}
+
+func NewMemoryEventRepo(s *Store) repository.EventRepository {
+	return &eventRepo{s: s}
+}
+
+func (r *eventRepo) After(ctx context.Context, afterID int64, limit int) ([]*domain.Event, error) {
+	orgID := tenant.OrgID(ctx)
+	return r.after(afterID, limit, &orgID), nil
+}
+
+func (r *eventRepo) AllAfter(ctx context.Context, afterID int64, limit int) ([]*domain.Event, error) {
+	return r.after(afterID, limit, nil), nil
+}
+
+// after reads the outbox, which is kept in id order, from the first event
+// past afterID.
+func (r *eventRepo) after(afterID int64, limit int, orgID *uuid.UUID) []*domain.Event {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	events := r.s.events
+	i := sort.Search(len(events), func(i int) bool { return events[i].event.ID > afterID })
+	out := []*domain.Event{}
+	for _, oe := range events[i:] {
+		if len(out) == limit {
+			break
+		}
+		if orgID == nil || oe.event.OrgID == *orgID {
+			out = append(out, copyEvent(oe.event))
+		}
+	}
+	return out
+}
+
+func (r *eventRepo) LastID(ctx context.Context) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.s.lastEventID, nil
+}
+
+func copyEvent(e *domain.Event) *domain.Event {
+	c := *e
+	if e.Data.Subscription != nil {
+		sub := *e.Data.Subscription
+		c.Data.Subscription = &sub
+	}
+	return &c
+}
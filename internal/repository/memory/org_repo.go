@@ -0,0 +1,43 @@
+package memrepo
+
+import (
+	"context"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type orgRepo struct {
+	s *Store
+}
+
+func NewMemoryOrganizationRepo(s *Store) repository.OrganizationRepository {
+	return &orgRepo{s: s}
+}
+
+func (r *orgRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Organization, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	org, ok := r.s.organizations[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	c := *org
+	return &c, nil
+}
+
+func (r *orgRepo) Update(ctx context.Context, org *domain.Organization) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	if cur, ok := r.s.organizations[org.ID]; ok {
+		cur.Name = org.Name
+		cur.DefaultCurrency = org.DefaultCurrency
+		cur.UpdatedAt = now
+	}
+	org.UpdatedAt = now
+	return nil
+}
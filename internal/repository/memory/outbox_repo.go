@@ -0,0 +1,158 @@
+package memrepo
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type outboxEvent struct {
+	event        *domain.Event
+	dispatchedAt *time.Time
+}
+
+// appendEvents records events in the outbox. Callers hold the write lock
+// while changing the subscriptions the events describe, which makes the
+// two one atomic change as a database transaction would.
+func (s *Store) appendEvents(events ...*domain.Event) {
+	now := s.now()
+	for _, e := range events {
+		s.lastEventID++
+		e.ID = s.lastEventID
+		e.CreatedAt = now
+		s.events = append(s.events, &outboxEvent{event: e})
+	}
+}
+
+type outboxRepo struct {
+	s *Store
+}
+
+func NewMemoryOutboxRepo(s *Store) repository.OutboxRepository {
+	return &outboxRepo{s: s}
+}
+
+func (r *outboxRepo) Append(ctx context.Context, events ...*domain.Event) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.appendEvents(events...)
+	return nil
+}
+
+func (r *outboxRepo) DispatchEvents(ctx context.Context, limit int) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	n := 0
+	for _, oe := range r.s.events {
+		if n == limit {
+			break
+		}
+		if oe.dispatchedAt != nil {
+			continue
+		}
+		payload, err := json.Marshal(oe.event)
+		if err != nil {
+			return n, err
+		}
+		for _, hook := range r.s.webhooks {
+			if hook.OrgID != oe.event.OrgID || !hook.Wants(oe.event.Type) {
+				continue
+			}
+			next := now
+			d := &domain.WebhookDelivery{
+				ID:            uuid.New(),
+				OrgID:         hook.OrgID,
+				WebhookID:     hook.ID,
+				EventID:       oe.event.ID,
+				EventType:     oe.event.Type,
+				Payload:       payload,
+				Status:        domain.DeliveryPending,
+				NextAttemptAt: &next,
+				CreatedAt:     now,
+			}
+			r.s.deliveries[d.ID] = d
+		}
+		oe.dispatchedAt = &now
+		n++
+	}
+	return n, nil
+}
+
+func (r *outboxRepo) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]repository.DueDelivery, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var due []*domain.WebhookDelivery
+	for _, d := range r.s.deliveries {
+		hook, ok := r.s.webhooks[d.WebhookID]
+		if ok && hook.Active && d.Status == domain.DeliveryPending && d.NextAttemptAt != nil && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	slices.SortFunc(due, func(a, b *domain.WebhookDelivery) int { return a.NextAttemptAt.Compare(*b.NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	leased := now.Add(lease)
+	out := make([]repository.DueDelivery, 0, len(due))
+	for _, d := range due {
+		d.NextAttemptAt = &leased
+		hook := *r.s.webhooks[d.WebhookID]
+		hook.Events = slices.Clone(hook.Events)
+		out = append(out, repository.DueDelivery{Delivery: copyDelivery(d), Webhook: &hook})
+	}
+	return out, nil
+}
+
+func (r *outboxRepo) SaveAttempt(ctx context.Context, d *domain.WebhookDelivery) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	cur, ok := r.s.deliveries[d.ID]
+	if !ok {
+		return nil
+	}
+	cur.Status = d.Status
+	cur.Attempts = d.Attempts
+	cur.NextAttemptAt = d.NextAttemptAt
+	cur.LastAttemptAt = d.LastAttemptAt
+	cur.ResponseStatus = d.ResponseStatus
+	cur.ResponseBody = d.ResponseBody
+	cur.Error = d.Error
+	return nil
+}
+
+func (r *outboxRepo) Prune(ctx context.Context, before time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var n int64
+	r.s.events = slices.DeleteFunc(r.s.events, func(oe *outboxEvent) bool {
+		if oe.dispatchedAt != nil && oe.event.CreatedAt.Before(before) {
+			n++
+			return true
+		}
+		return false
+	})
+	for id, d := range r.s.deliveries {
+		if d.Status != domain.DeliveryPending && d.CreatedAt.Before(before) {
+			delete(r.s.deliveries, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func copyDelivery(d *domain.WebhookDelivery) *domain.WebhookDelivery {
+	c := *d
+	c.Payload = slices.Clone(d.Payload)
+	return &c
+}
@@ -0,0 +1,122 @@
+package memrepo
+
+import (
+	"context"
+	"slices"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type reminderRepo struct {
+	s *Store
+}
+
+func NewMemoryReminderRepo(s *Store) repository.ReminderRepository {
+	return &reminderRepo{s: s}
+}
+
+func (r *reminderRepo) Due(ctx context.Context, month time.Time, afterID uuid.UUID, limit int) ([]*domain.Subscription, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	prev := month.AddDate(0, -1, 0)
+	var out []*domain.Subscription
+	for _, sub := range r.s.subscriptions {
+		if slices.Compare(sub.ID[:], afterID[:]) <= 0 || !sub.StartDate.Before(month) {
+			continue
+		}
+		if sub.EndDate == nil || !sub.EndDate.Before(prev) {
+			out = append(out, sub)
+		}
+	}
+	slices.SortFunc(out, func(a, b *domain.Subscription) int { return slices.Compare(a.ID[:], b.ID[:]) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return copySubs(out), nil
+}
+
+func (r *reminderRepo) Track(ctx context.Context, rem *domain.Reminder) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	rems := r.s.reminders[rem.SubscriptionID]
+	i := slices.IndexFunc(rems, func(o *domain.Reminder) bool {
+		return o.Kind == rem.Kind && o.DueOn.Equal(rem.DueOn) && o.Channel == rem.Channel
+	})
+	if i < 0 {
+		now := r.s.now()
+		c := *rem
+		c.ID = uuid.New()
+		c.Attempts, c.SentAt, c.Error = 0, nil, ""
+		c.CreatedAt, c.UpdatedAt = now, now
+		r.s.reminders[rem.SubscriptionID] = append(rems, &c)
+		*rem = c
+		return true, nil
+	}
+	*rem = copyReminder(rems[i])
+	return rem.SentAt == nil, nil
+}
+
+func (r *reminderRepo) SaveAttempt(ctx context.Context, rem *domain.Reminder) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, stored := range r.s.reminders[rem.SubscriptionID] {
+		if stored.ID == rem.ID {
+			rem.UpdatedAt = r.s.now()
+			stored.Attempts = rem.Attempts
+			stored.SentAt = rem.SentAt
+			stored.Error = rem.Error
+			stored.UpdatedAt = rem.UpdatedAt
+			return nil
+		}
+	}
+	return nil
+}
+
+func copyReminder(rem *domain.Reminder) domain.Reminder {
+	c := *rem
+	if rem.SentAt != nil {
+		t := *rem.SentAt
+		c.SentAt = &t
+	}
+	return c
+}
+
+type lease struct {
+	holder    string
+	expiresAt time.Time
+}
+
+type lockRepo struct {
+	s *Store
+}
+
+func NewMemoryLockRepo(s *Store) repository.LockRepository {
+	return &lockRepo{s: s}
+}
+
+func (r *lockRepo) TryLock(ctx context.Context, name, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if l, ok := r.s.locks[name]; ok && l.holder != holder && now.Before(l.expiresAt) {
+		return false, nil
+	}
+	r.s.locks[name] = lease{holder: holder, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (r *lockRepo) Unlock(ctx context.Context, name, holder string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if l, ok := r.s.locks[name]; ok && l.holder == holder {
+		delete(r.s.locks, name)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package memrepo
+
+import (
+	"context"
+	"subcalc/internal/billing"
+	"subcalc/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type statsRepo struct {
+	s *Store
+}
+
+func NewMemoryStatsRepo(s *Store) repository.StatsRepository {
+	return &statsRepo{s: s}
+}
+
+func (r *statsRepo) ActiveByOrg(ctx context.Context, at time.Time) (map[uuid.UUID]int64, error) {
+	month := billing.Month(at)
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	out := map[uuid.UUID]int64{}
+	for _, sub := range r.s.subscriptions {
+		if !sub.StartDate.After(month) && (sub.EndDate == nil || !sub.EndDate.Before(month)) {
+			out[sub.OrgID]++
+		}
+	}
+	return out, nil
+}
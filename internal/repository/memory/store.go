@@ -0,0 +1,189 @@
+// Package memrepo keeps the repositories in process memory, for tests and
+// local demos without a database. The state can be saved to and loaded from
+// a JSON snapshot file.
+package memrepo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"subcalc/internal/domain"
+	"subcalc/internal/tenant"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store holds the data of every memory repository built on it.
+type Store struct {
+	mu            sync.RWMutex
+	subscriptions map[uuid.UUID]*domain.Subscription
+	organizations map[uuid.UUID]*domain.Organization
+	apiKeys       map[uuid.UUID]*domain.APIKey
+	webhooks      map[uuid.UUID]*domain.Webhook
+	deliveries    map[uuid.UUID]*domain.WebhookDelivery
+	// events is the outbox, in id order. Events and deliveries are not
+	// part of snapshots; they last as long as the process.
+	events      []*outboxEvent
+	lastEventID int64
+	// reminders are grouped by subscription, which takes them along when
+	// it is deleted
+	reminders map[uuid.UUID][]*domain.Reminder
+	locks     map[string]lease
+	now       func() time.Time
+}
+
+// NewStore returns an empty store holding only the default organization,
+// like a freshly migrated database.
+func NewStore() *Store {
+	s := &Store{
+		subscriptions: map[uuid.UUID]*domain.Subscription{},
+		organizations: map[uuid.UUID]*domain.Organization{},
+		apiKeys:       map[uuid.UUID]*domain.APIKey{},
+		webhooks:      map[uuid.UUID]*domain.Webhook{},
+		deliveries:    map[uuid.UUID]*domain.WebhookDelivery{},
+		reminders:     map[uuid.UUID][]*domain.Reminder{},
+		locks:         map[string]lease{},
+		now:           func() time.Time { return time.Now().UTC() },
+	}
+	now := s.now()
+	s.organizations[tenant.DefaultOrgID] = &domain.Organization{
+		ID:              tenant.DefaultOrgID,
+		Name:            "Default",
+		DefaultCurrency: "RUB",
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	return s
+}
+
+// snapshot is the file format. Subscriptions keep full dates rather than the
+// API's MM-YYYY, and API keys keep their hash, so a round trip is lossless.
+type snapshot struct {
+	Subscriptions []subscriptionRecord   `json:"subscriptions"`
+	Organizations []*domain.Organization `json:"organizations"`
+	APIKeys       []apiKeyRecord         `json:"api_keys"`
+	Webhooks      []webhookRecord        `json:"webhooks,omitempty"`
+	Reminders     []*domain.Reminder     `json:"reminders,omitempty"`
+}
+
+type subscriptionRecord struct {
+	ID          uuid.UUID  `json:"id"`
+	ServiceName string     `json:"service_name"`
+	Price       int        `json:"price"`
+	UserID      uuid.UUID  `json:"user_id"`
+	OrgID       uuid.UUID  `json:"org_id"`
+	StartDate   time.Time  `json:"start_date"`
+	EndDate     *time.Time `json:"end_date,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type apiKeyRecord struct {
+	domain.APIKey
+	Hash string `json:"hash"`
+}
+
+type webhookRecord struct {
+	domain.Webhook
+	Secret string `json:"secret"`
+}
+
+// Load replaces the store's contents with the snapshot at path. A missing
+// file leaves the store as it is.
+func (s *Store) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("decode snapshot %s: %w", path, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.subscriptions)
+	for _, r := range snap.Subscriptions {
+		sub := domain.Subscription(r)
+		s.subscriptions[sub.ID] = &sub
+	}
+	if len(snap.Organizations) > 0 {
+		clear(s.organizations)
+		for _, org := range snap.Organizations {
+			s.organizations[org.ID] = org
+		}
+	}
+	clear(s.apiKeys)
+	for _, r := range snap.APIKeys {
+		key := r.APIKey
+		key.Hash = r.Hash
+		s.apiKeys[key.ID] = &key
+	}
+	clear(s.webhooks)
+	for _, r := range snap.Webhooks {
+		hook := r.Webhook
+		hook.Secret = r.Secret
+		s.webhooks[hook.ID] = &hook
+	}
+	clear(s.reminders)
+	for _, rem := range snap.Reminders {
+		s.reminders[rem.SubscriptionID] = append(s.reminders[rem.SubscriptionID], rem)
+	}
+	return nil
+}
+
+// Save writes the store to path, through a temporary file so a crash
+// mid-write keeps the previous snapshot.
+func (s *Store) Save(path string) error {
+	s.mu.RLock()
+	var snap snapshot
+	for _, sub := range s.subscriptions {
+		snap.Subscriptions = append(snap.Subscriptions, subscriptionRecord(*sub))
+	}
+	for _, org := range s.organizations {
+		snap.Organizations = append(snap.Organizations, org)
+	}
+	for _, key := range s.apiKeys {
+		snap.APIKeys = append(snap.APIKeys, apiKeyRecord{APIKey: *key, Hash: key.Hash})
+	}
+	for _, hook := range s.webhooks {
+		snap.Webhooks = append(snap.Webhooks, webhookRecord{Webhook: *hook, Secret: hook.Secret})
+	}
+	for _, rems := range s.reminders {
+		snap.Reminders = append(snap.Reminders, rems...)
+	}
+	// sorted, so unchanged data gives an unchanged file
+	slices.SortFunc(snap.Subscriptions, func(a, b subscriptionRecord) int { return strings.Compare(a.ID.String(), b.ID.String()) })
+	slices.SortFunc(snap.Organizations, func(a, b *domain.Organization) int { return strings.Compare(a.ID.String(), b.ID.String()) })
+	slices.SortFunc(snap.APIKeys, func(a, b apiKeyRecord) int { return strings.Compare(a.ID.String(), b.ID.String()) })
+	slices.SortFunc(snap.Webhooks, func(a, b webhookRecord) int { return strings.Compare(a.ID.String(), b.ID.String()) })
+	slices.SortFunc(snap.Reminders, func(a, b *domain.Reminder) int { return strings.Compare(a.ID.String(), b.ID.String()) })
+	data, err := json.MarshalIndent(snap, "", "  ")
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,363 @@
+package memrepo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"subcalc/internal/billing"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"subcalc/internal/search"
+	"subcalc/internal/tenant"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrDuplicateID is returned when creating a record whose id is taken, where
+// the database would report a primary key violation.
+var ErrDuplicateID = fmt.Errorf("%w: duplicate id", domain.ErrConflict)
+
+type subscriptionRepo struct {
+	s *Store
+}
+
+func NewMemorySubscriptionRepo(s *Store) repository.SubscriptionRepository {
+	return &subscriptionRepo{s: s}
+}
+
+func (r *subscriptionRepo) Create(ctx context.Context, sub *domain.Subscription) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if sub.ID == uuid.Nil {
+		sub.ID = uuid.New()
+	}
+	if _, ok := r.s.subscriptions[sub.ID]; ok {
+		return ErrDuplicateID
+	}
+	sub.OrgID = tenant.OrgID(ctx)
+	now := r.s.now()
+	sub.CreatedAt = now
+	sub.UpdatedAt = now
+	c := *sub
+	c.StartDate, c.EndDate = dateColumns(sub.StartDate, sub.EndDate)
+	r.s.subscriptions[sub.ID] = &c
+	r.s.appendEvents(domain.SubscriptionEvent(domain.EventSubscriptionCreated, &c))
+	return nil
+}
+
+func (r *subscriptionRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	sub, ok := r.s.subscriptions[id]
+	if !ok || sub.OrgID != tenant.OrgID(ctx) {
+		return nil, domain.ErrNotFound
+	}
+	c := *sub
+	return &c, nil
+}
+
+// Update changes the same columns as the SQL implementation and, like it,
+// fails with domain.ErrNotFound for a missing or foreign id.
+func (r *subscriptionRepo) Update(ctx context.Context, sub *domain.Subscription) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	cur, ok := r.s.subscriptions[sub.ID]
+	if !ok || cur.OrgID != tenant.OrgID(ctx) {
+		return domain.ErrNotFound
+	}
+	now := r.s.now()
+	oldPrice := cur.Price
+	cur.ServiceName = sub.ServiceName
+	cur.Price = sub.Price
+	cur.UserID = sub.UserID
+	cur.StartDate, cur.EndDate = dateColumns(sub.StartDate, sub.EndDate)
+	cur.UpdatedAt = now
+	r.s.appendEvents(domain.SubscriptionEvent(domain.EventSubscriptionUpdated, cur))
+	if cur.Price != oldPrice {
+		e := domain.SubscriptionEvent(domain.EventSubscriptionPriceChanged, cur)
+		e.Data.PreviousPrice = &oldPrice
+		r.s.appendEvents(e)
+	}
+	sub.UpdatedAt = now
+	return nil
+}
+
+func (r *subscriptionRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	n, err := r.DeleteMany(ctx, []uuid.UUID{id})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *subscriptionRepo) DeleteMany(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	orgID := tenant.OrgID(ctx)
+	var n int64
+	for _, id := range ids {
+		if sub, ok := r.s.subscriptions[id]; ok && sub.OrgID == orgID {
+			r.s.deleteSubscription(sub)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *subscriptionRepo) DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	orgID := tenant.OrgID(ctx)
+	var n int64
+	for _, sub := range r.s.subscriptions {
+		if sub.OrgID == orgID && sub.UserID == userID {
+			r.s.deleteSubscription(sub)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// deleteSubscription removes sub with its reminders and records the
+// deletion. The caller holds the write lock.
+func (s *Store) deleteSubscription(sub *domain.Subscription) {
+	delete(s.subscriptions, sub.ID)
+	delete(s.reminders, sub.ID)
+	s.appendEvents(domain.SubscriptionEvent(domain.EventSubscriptionDeleted, sub))
+}
+
+func (r *subscriptionRepo) List(ctx context.Context, filter repository.SubscriptionFilter) ([]*domain.Subscription, error) {
+	return r.find(ctx, filter, 100), nil
+}
+
+func (r *subscriptionRepo) FindForPeriod(ctx context.Context, filter repository.SubscriptionFilter) ([]*domain.Subscription, error) {
+	return r.find(ctx, filter, 1000), nil
+}
+
+func (r *subscriptionRepo) Count(ctx context.Context, filter repository.SubscriptionFilter) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return int64(len(r.matching(ctx, filter))), nil
+}
+
+// find pages through the matches in List order, by offset or from a
+// cursor.
+func (r *subscriptionRepo) find(ctx context.Context, filter repository.SubscriptionFilter, defaultLimit int) []*domain.Subscription {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	subs := r.matching(ctx, filter)
+	if filter.Cursor == nil && len(filter.Sort) > 0 {
+		slices.SortFunc(subs, func(a, b *domain.Subscription) int {
+			for _, o := range filter.Sort {
+				c := compareKey(a, b, o.Key)
+				if o.Desc {
+					c = -c
+				}
+				if c != 0 {
+					return c
+				}
+			}
+			return compareListOrder(a, b)
+		})
+	} else {
+		slices.SortFunc(subs, compareListOrder)
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	if c := filter.Cursor; c != nil {
+		i, found := slices.BinarySearchFunc(subs, c, func(sub *domain.Subscription, c *repository.Cursor) int {
+			return compareListOrder(sub, &domain.Subscription{CreatedAt: c.CreatedAt, ID: c.ID})
+		})
+		if c.Backward {
+			subs = subs[:i]
+			if limit > 0 && limit < len(subs) {
+				subs = subs[len(subs)-limit:]
+			}
+			return copySubs(subs)
+		}
+		if found {
+			i++
+		}
+		subs = subs[i:]
+	} else {
+		offset := max(filter.Offset, 0)
+		if offset >= len(subs) {
+			return []*domain.Subscription{}
+		}
+		subs = subs[offset:]
+	}
+	if limit > 0 && limit < len(subs) {
+		subs = subs[:limit]
+	}
+	return copySubs(subs)
+}
+
+func compareListOrder(a, b *domain.Subscription) int {
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return slices.Compare(a.ID[:], b.ID[:])
+}
+
+// compareKey orders by one sort key. A missing end date is later than any.
+func compareKey(a, b *domain.Subscription, k repository.SortKey) int {
+	switch k {
+	case repository.SortServiceName:
+		return strings.Compare(a.ServiceName, b.ServiceName)
+	case repository.SortPrice:
+		return cmp.Compare(a.Price, b.Price)
+	case repository.SortStartDate:
+		return a.StartDate.Compare(b.StartDate)
+	case repository.SortEndDate:
+		switch {
+		case a.EndDate == nil && b.EndDate == nil:
+			return 0
+		case a.EndDate == nil:
+			return 1
+		case b.EndDate == nil:
+			return -1
+		}
+		return a.EndDate.Compare(*b.EndDate)
+	case repository.SortCreatedAt:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case repository.SortUpdatedAt:
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	}
+	return 0
+}
+
+func copySubs(subs []*domain.Subscription) []*domain.Subscription {
+	out := make([]*domain.Subscription, len(subs))
+	for i, sub := range subs {
+		c := *sub
+		out[i] = &c
+	}
+	return out
+}
+
+// matching applies the WHERE clause shared by List, FindForPeriod and
+// Count. The caller holds the read lock.
+func (r *subscriptionRepo) matching(ctx context.Context, filter repository.SubscriptionFilter) []*domain.Subscription {
+	orgID := tenant.OrgID(ctx)
+	var out []*domain.Subscription
+	for _, sub := range r.s.subscriptions {
+		if sub.OrgID == orgID && matches(sub, filter) {
+			out = append(out, sub)
+		}
+	}
+	return out
+}
+
+func matches(sub *domain.Subscription, f repository.SubscriptionFilter) bool {
+	if f.UserID != nil && sub.UserID != *f.UserID {
+		return false
+	}
+	if len(f.UserIDs) > 0 && !slices.Contains(f.UserIDs, sub.UserID) {
+		return false
+	}
+
+	name := sub.ServiceName
+	if f.IgnoreCase {
+		name = strings.ToLower(name)
+	}
+	if f.ServiceName != nil && name != foldCase(*f.ServiceName, f.IgnoreCase) {
+		return false
+	}
+	if f.ServiceNamePrefix != nil && !strings.HasPrefix(name, foldCase(*f.ServiceNamePrefix, f.IgnoreCase)) {
+		return false
+	}
+
+	if f.PriceMin != nil && sub.Price < *f.PriceMin {
+		return false
+	}
+	if f.PriceMax != nil && sub.Price > *f.PriceMax {
+		return false
+	}
+
+	if f.To != nil && sub.StartDate.After(*f.To) {
+		return false
+	}
+	if f.From != nil && sub.EndDate != nil && sub.EndDate.Before(*f.From) {
+		return false
+	}
+	if f.ActiveOn != nil {
+		m := billing.Month(*f.ActiveOn)
+		if sub.StartDate.After(m) || (sub.EndDate != nil && sub.EndDate.Before(m)) {
+			return false
+		}
+	}
+	if f.OpenEnded != nil && *f.OpenEnded != (sub.EndDate == nil) {
+		return false
+	}
+
+	if f.CreatedAfter != nil && !sub.CreatedAt.After(*f.CreatedAfter) {
+		return false
+	}
+	if f.UpdatedAfter != nil && !sub.UpdatedAt.After(*f.UpdatedAfter) {
+		return false
+	}
+	return true
+}
+
+func foldCase(s string, fold bool) string {
+	if fold {
+		return strings.ToLower(s)
+	}
+	return s
+}
+
+// Search ranks in Go what the Postgres repository ranks with pg_trgm.
+func (r *subscriptionRepo) Search(ctx context.Context, query string, filter repository.SubscriptionFilter) ([]repository.SearchHit, error) {
+	limit := filter.Limit
+	if limit == 0 {
+		limit = 20
+	}
+	filter.Sort, filter.Offset, filter.Cursor = nil, 0, nil
+	filter.Limit = -1
+	return search.Rank(r.find(ctx, filter, -1), query, limit), nil
+}
+
+// SumForPeriod leaves the arithmetic to the billing package, which the SQL
+// implementations are tested against.
+func (r *subscriptionRepo) SumForPeriod(ctx context.Context, filter repository.SubscriptionFilter) (int64, error) {
+	if filter.From == nil || filter.To == nil {
+		return 0, nil
+	}
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	// the period is billing's to apply, the other conditions select the rows
+	rows := filter
+	rows.From, rows.To = nil, nil
+	return billing.Total(r.matching(ctx, rows), *filter.From, *filter.To), nil
+}
+
+// dateColumns drops the time of day, as storing into a date column does.
+func dateColumns(start time.Time, end *time.Time) (time.Time, *time.Time) {
+	start = truncDay(start)
+	if end != nil {
+		e := truncDay(*end)
+		end = &e
+	}
+	return start, end
+}
+
+func truncDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,57 @@
+package memrepo
+
+import (
+	"context"
+	"path/filepath"
+	"subcalc/internal/domain"
+	"subcalc/internal/tenant"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func month(y int, m time.Month) time.Time {
+	return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestStore_SnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	store := NewStore()
+	repo := NewMemorySubscriptionRepo(store)
+	ctx := context.Background()
+
+	sub := &domain.Subscription{ServiceName: "Spotify", Price: 299, UserID: uuid.New(), StartDate: month(2025, 7), EndDate: ptr(month(2025, 12))}
+	if err := repo.Create(ctx, sub); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	key := &domain.APIKey{Name: "job", Hash: "abc", Scopes: []string{"reports:read"}}
+	_ = NewMemoryAPIKeyRepo(store).Create(ctx, key)
+	if err := store.Save(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	loaded := NewStore()
+	if err := loaded.Load(path); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	got, _ := NewMemorySubscriptionRepo(loaded).GetByID(ctx, sub.ID)
+	if got == nil || got.Price != 299 || !got.EndDate.Equal(month(2025, 12)) {
+		t.Fatalf("subscription not restored: %+v", got)
+	}
+	if k, _ := NewMemoryAPIKeyRepo(loaded).GetByHash(ctx, "abc"); k == nil || k.ID != key.ID {
+		t.Fatalf("api key not restored: %+v", k)
+	}
+	if org, _ := NewMemoryOrganizationRepo(loaded).GetByID(ctx, tenant.DefaultOrgID); org == nil {
+		t.Fatalf("default organization missing")
+	}
+}
+
+func TestStore_LoadMissingFileKeepsDefaults(t *testing.T) {
+	store := NewStore()
+	if err := store.Load(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package memrepo
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"subcalc/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+type webhookRepo struct {
+	s *Store
+}
+
+func NewMemoryWebhookRepo(s *Store) repository.WebhookRepository {
+	return &webhookRepo{s: s}
+}
+
+func (r *webhookRepo) Create(ctx context.Context, hook *domain.Webhook) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if hook.ID == uuid.Nil {
+		hook.ID = uuid.New()
+	}
+	if _, ok := r.s.webhooks[hook.ID]; ok {
+		return ErrDuplicateID
+	}
+	hook.OrgID = tenant.OrgID(ctx)
+	now := r.s.now()
+	hook.CreatedAt = now
+	hook.UpdatedAt = now
+	r.s.webhooks[hook.ID] = copyWebhook(hook)
+	return nil
+}
+
+func (r *webhookRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	hook, ok := r.s.webhooks[id]
+	if !ok || hook.OrgID != tenant.OrgID(ctx) {
+		return nil, domain.ErrNotFound
+	}
+	return copyWebhook(hook), nil
+}
+
+func (r *webhookRepo) List(ctx context.Context) ([]*domain.Webhook, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	orgID := tenant.OrgID(ctx)
+	out := []*domain.Webhook{}
+	for _, hook := range r.s.webhooks {
+		if hook.OrgID == orgID {
+			out = append(out, copyWebhook(hook))
+		}
+	}
+	slices.SortFunc(out, func(a, b *domain.Webhook) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return slices.Compare(a.ID[:], b.ID[:])
+	})
+	return out, nil
+}
+
+func (r *webhookRepo) Update(ctx context.Context, hook *domain.Webhook) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	cur, ok := r.s.webhooks[hook.ID]
+	if !ok || cur.OrgID != tenant.OrgID(ctx) {
+		return domain.ErrNotFound
+	}
+	cur.URL = hook.URL
+	cur.Events = slices.Clone(hook.Events)
+	cur.Active = hook.Active
+	cur.UpdatedAt = r.s.now()
+	hook.UpdatedAt = cur.UpdatedAt
+	return nil
+}
+
+func (r *webhookRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	hook, ok := r.s.webhooks[id]
+	if !ok || hook.OrgID != tenant.OrgID(ctx) {
+		return domain.ErrNotFound
+	}
+	delete(r.s.webhooks, id)
+	for did, d := range r.s.deliveries {
+		if d.WebhookID == id {
+			delete(r.s.deliveries, did)
+		}
+	}
+	return nil
+}
+
+func (r *webhookRepo) ListDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]*domain.WebhookDelivery, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	orgID := tenant.OrgID(ctx)
+	out := []*domain.WebhookDelivery{}
+	for _, d := range r.s.deliveries {
+		if d.OrgID == orgID && d.WebhookID == webhookID {
+			out = append(out, copyDelivery(d))
+		}
+	}
+	slices.SortFunc(out, func(a, b *domain.WebhookDelivery) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.EventID, a.EventID)
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func copyWebhook(h *domain.Webhook) *domain.Webhook {
+	c := *h
+	c.Events = slices.Clone(h.Events)
+	return &c
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"subcalc/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type OrganizationRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Organization, error)
+	Update(ctx context.Context, org *domain.Organization) error
+}
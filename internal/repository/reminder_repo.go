@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"subcalc/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReminderRepository finds the subscriptions to remind of and tracks the
+// reminders sent. Like the outbox it is not tenant-scoped: one scheduler
+// serves every organization.
+type ReminderRepository interface {
+	// Due returns up to limit subscriptions, by id after afterID, that
+	// started before month and still run in the month before it: those
+	// renewing at month and those whose last month that was.
+	Due(ctx context.Context, month time.Time, afterID uuid.UUID, limit int) ([]*domain.Subscription, error)
+	// Track records r unless the same reminder (subscription, kind, due
+	// date and channel) is recorded already, then fills r with what is
+	// stored. It reports whether r is still to be sent.
+	Track(ctx context.Context, r *domain.Reminder) (bool, error)
+	// SaveAttempt stores the attempts, sent time and error of r.
+	SaveAttempt(ctx context.Context, r *domain.Reminder) error
+}
+
+// LockRepository hands out named leases, so that one of several replicas
+// at a time runs a job.
+type LockRepository interface {
+	// TryLock gives the lease name to holder until now+ttl if it is free,
+	// expired or holder's already, and reports whether holder has it.
+	TryLock(ctx context.Context, name, holder string, now time.Time, ttl time.Duration) (bool, error)
+	// Unlock releases the lease if holder has it.
+	Unlock(ctx context.Context, name, holder string) error
+}
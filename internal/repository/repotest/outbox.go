@@ -0,0 +1,268 @@
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"subcalc/internal/domain"
+	"subcalc/internal/tenant"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func createWebhook(t *testing.T, ctx context.Context, h Harness, hook *domain.Webhook) *domain.Webhook {
+	t.Helper()
+	if err := h.Webhooks.Create(ctx, hook); err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+	return hook
+}
+
+func testWebhooks(t *testing.T, h Harness) {
+	ctx := context.Background()
+	other := tenant.WithOrganization(ctx, &domain.Organization{ID: h.OtherOrg})
+
+	hook := createWebhook(t, ctx, h, &domain.Webhook{
+		URL: "https://example.com/a", Secret: "whsec_a", Events: []domain.EventType{domain.EventSubscriptionCreated}, Active: true,
+	})
+	if hook.ID == uuid.Nil || hook.OrgID != tenant.DefaultOrgID || hook.CreatedAt.IsZero() {
+		t.Fatalf("create must set id, tenant and timestamps: %+v", hook)
+	}
+	inactive := createWebhook(t, ctx, h, &domain.Webhook{URL: "https://example.com/b", Secret: "whsec_b", Active: false})
+	theirs := createWebhook(t, other, h, &domain.Webhook{URL: "https://example.com/c", Secret: "whsec_c", Active: true})
+
+	got, err := h.Webhooks.GetByID(ctx, hook.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.URL != hook.URL || got.Secret != "whsec_a" || !slices.Equal(got.Events, hook.Events) || !got.Active {
+		t.Fatalf("get returned %+v, want %+v", got, hook)
+	}
+	if again, _ := h.Webhooks.GetByID(ctx, inactive.ID); again.Active || len(again.Events) != 0 {
+		t.Fatalf("inactive webhook without events read back as %+v", again)
+	}
+
+	list, err := h.Webhooks.List(ctx)
+	if err != nil || len(list) != 2 || list[0].ID != hook.ID || list[1].ID != inactive.ID {
+		t.Fatalf("list: want the tenant's two webhooks in creation order, got %v, %v", list, err)
+	}
+
+	got.URL = "https://example.com/a2"
+	got.Events = []domain.EventType{domain.EventSubscriptionDeleted, domain.EventSubscriptionUpdated}
+	got.Active = false
+	if err := h.Webhooks.Update(ctx, got); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated, _ := h.Webhooks.GetByID(ctx, hook.ID); updated.URL != got.URL || !slices.Equal(updated.Events, got.Events) || updated.Active || updated.Secret != "whsec_a" {
+		t.Fatalf("update not stored, or changed the secret: %+v", updated)
+	}
+
+	if _, err := h.Webhooks.GetByID(ctx, theirs.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("get across organizations: want ErrNotFound, got %v", err)
+	}
+	if err := h.Webhooks.Update(ctx, theirs); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("update across organizations: want ErrNotFound, got %v", err)
+	}
+	if err := h.Webhooks.Delete(ctx, theirs.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("delete across organizations: want ErrNotFound, got %v", err)
+	}
+
+	if err := h.Webhooks.Delete(ctx, hook.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := h.Webhooks.GetByID(ctx, hook.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("deleted webhook: want ErrNotFound, got %v", err)
+	}
+}
+
+func testOutbox(t *testing.T, h Harness) {
+	ctx := context.Background()
+	other := tenant.WithOrganization(ctx, &domain.Organization{ID: h.OtherOrg})
+	all := domain.EventTypes
+
+	hook := createWebhook(t, ctx, h, &domain.Webhook{
+		URL: "https://example.com/a", Secret: "whsec_a", Active: true,
+		Events: []domain.EventType{domain.EventSubscriptionCreated, domain.EventSubscriptionPriceChanged, domain.EventSubscriptionDeleted},
+	})
+	inactive := createWebhook(t, ctx, h, &domain.Webhook{URL: "https://example.com/b", Secret: "whsec_b", Events: all, Active: false})
+	theirs := createWebhook(t, other, h, &domain.Webhook{URL: "https://example.com/c", Secret: "whsec_c", Events: all, Active: true})
+
+	sub := create(t, ctx, h.Repo, &domain.Subscription{ServiceName: "Netflix", Price: 499, UserID: uuid.New(), StartDate: Month(2025, 7)})
+	sub.Price = 599
+	if err := h.Repo.Update(ctx, sub); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	sub.ServiceName = "Netflix Premium"
+	if err := h.Repo.Update(ctx, sub); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	// a missing id changes nothing and records nothing
+	if err := h.Repo.Update(ctx, &domain.Subscription{ID: uuid.New(), ServiceName: "X", UserID: uuid.New(), StartDate: Month(2025, 7)}); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("update missing: want ErrNotFound, got %v", err)
+	}
+	if err := h.Repo.Delete(ctx, sub.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	// created, updated, price_changed, updated, deleted
+	if n, err := h.Outbox.DispatchEvents(ctx, 100); err != nil || n != 5 {
+		t.Fatalf("dispatch: want 5 events, got %d, %v", n, err)
+	}
+	if n, err := h.Outbox.DispatchEvents(ctx, 100); err != nil || n != 0 {
+		t.Fatalf("dispatch again: want 0 events, got %d, %v", n, err)
+	}
+
+	deliveries, err := h.Webhooks.ListDeliveries(ctx, hook.ID, 10)
+	if err != nil {
+		t.Fatalf("list deliveries: %v", err)
+	}
+	var types []domain.EventType
+	for _, d := range deliveries {
+		types = append(types, d.EventType)
+	}
+	want := []domain.EventType{domain.EventSubscriptionDeleted, domain.EventSubscriptionPriceChanged, domain.EventSubscriptionCreated}
+	if !slices.Equal(types, want) {
+		t.Fatalf("deliveries: want %v newest first, got %v", want, types)
+	}
+	for _, d := range deliveries {
+		if d.Status != domain.DeliveryPending || d.Attempts != 0 || d.NextAttemptAt == nil {
+			t.Fatalf("new delivery not pending: %+v", d)
+		}
+		var e domain.Event
+		if err := json.Unmarshal(d.Payload, &e); err != nil {
+			t.Fatalf("payload %s: %v", d.Payload, err)
+		}
+		if e.ID != d.EventID || e.Type != d.EventType || e.OrgID != tenant.DefaultOrgID || e.Data.Subscription == nil || e.Data.Subscription.ID != sub.ID {
+			t.Fatalf("payload does not describe the event: %s", d.Payload)
+		}
+		if e.Type == domain.EventSubscriptionPriceChanged && (e.Data.PreviousPrice == nil || *e.Data.PreviousPrice != 499 || e.Data.Subscription.Price != 599) {
+			t.Fatalf("price change from 499 to 599 recorded as %s", d.Payload)
+		}
+	}
+	if ds, _ := h.Webhooks.ListDeliveries(ctx, inactive.ID, 10); len(ds) != 0 {
+		t.Fatalf("inactive webhook got %d deliveries", len(ds))
+	}
+	if ds, _ := h.Webhooks.ListDeliveries(other, theirs.ID, 10); len(ds) != 0 {
+		t.Fatalf("another organization's webhook got %d deliveries", len(ds))
+	}
+	if ds, _ := h.Webhooks.ListDeliveries(other, hook.ID, 10); len(ds) != 0 {
+		t.Fatalf("deliveries leaked across organizations")
+	}
+
+	now := time.Now().UTC().Add(time.Second)
+	due, err := h.Outbox.ClaimDeliveries(ctx, now, time.Minute, 10)
+	if err != nil || len(due) != 3 {
+		t.Fatalf("claim: want 3 deliveries, got %d, %v", len(due), err)
+	}
+	for _, d := range due {
+		if d.Webhook == nil || d.Webhook.ID != hook.ID || d.Webhook.Secret != "whsec_a" {
+			t.Fatalf("claimed delivery without its webhook: %+v", d)
+		}
+	}
+	if again, err := h.Outbox.ClaimDeliveries(ctx, now, time.Minute, 10); err != nil || len(again) != 0 {
+		t.Fatalf("claim during the lease: want none, got %d, %v", len(again), err)
+	}
+
+	done := due[0].Delivery
+	status := 200
+	done.Status = domain.DeliverySucceeded
+	done.Attempts = 1
+	done.NextAttemptAt = nil
+	done.LastAttemptAt = &now
+	done.ResponseStatus = &status
+	done.ResponseBody = "ok"
+	if err := h.Outbox.SaveAttempt(ctx, done); err != nil {
+		t.Fatalf("save attempt: %v", err)
+	}
+	if later, err := h.Outbox.ClaimDeliveries(ctx, now.Add(2*time.Minute), time.Minute, 10); err != nil || len(later) != 2 {
+		t.Fatalf("claim after the lease: want the 2 unfinished deliveries, got %d, %v", len(later), err)
+	}
+	deliveries, _ = h.Webhooks.ListDeliveries(ctx, hook.ID, 10)
+	for _, d := range deliveries {
+		if d.ID != done.ID {
+			continue
+		}
+		if d.Status != domain.DeliverySucceeded || d.Attempts != 1 || d.NextAttemptAt != nil || d.LastAttemptAt == nil ||
+			d.ResponseStatus == nil || *d.ResponseStatus != 200 || d.ResponseBody != "ok" {
+			t.Fatalf("attempt not recorded: %+v", d)
+		}
+	}
+	if limited, _ := h.Webhooks.ListDeliveries(ctx, hook.ID, 1); len(limited) != 1 {
+		t.Fatalf("list deliveries ignores the limit: %d", len(limited))
+	}
+
+	if n, err := h.Outbox.Prune(ctx, now.Add(time.Hour)); err != nil || n != 6 {
+		t.Fatalf("prune: want 5 events and 1 finished delivery, got %d, %v", n, err)
+	}
+	if ds, _ := h.Webhooks.ListDeliveries(ctx, hook.ID, 10); len(ds) != 2 {
+		t.Fatalf("prune must keep pending deliveries, %d left", len(ds))
+	}
+
+	if err := h.Webhooks.Delete(ctx, hook.ID); err != nil {
+		t.Fatalf("delete webhook: %v", err)
+	}
+	if ds, _ := h.Webhooks.ListDeliveries(ctx, hook.ID, 10); len(ds) != 0 {
+		t.Fatalf("deleting a webhook must delete its deliveries, %d left", len(ds))
+	}
+	if due, _ := h.Outbox.ClaimDeliveries(ctx, now.Add(time.Hour), time.Minute, 10); len(due) != 0 {
+		t.Fatalf("claimed %d deliveries of a deleted webhook", len(due))
+	}
+}
+
+func testEventLog(t *testing.T, h Harness) {
+	ctx := context.Background()
+	other := tenant.WithOrganization(ctx, &domain.Organization{ID: h.OtherOrg})
+
+	start, err := h.Events.LastID(ctx)
+	if err != nil {
+		t.Fatalf("last id: %v", err)
+	}
+	ours := create(t, ctx, h.Repo, &domain.Subscription{ServiceName: "Netflix", Price: 499, UserID: uuid.New(), StartDate: Month(2025, 7)})
+	create(t, other, h.Repo, &domain.Subscription{ServiceName: "Spotify", Price: 199, UserID: uuid.New(), StartDate: Month(2025, 7)})
+	if err := h.Repo.Delete(ctx, ours.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	all, err := h.Events.AllAfter(ctx, start, 10)
+	if err != nil || len(all) != 3 {
+		t.Fatalf("all after: want 3 events, got %d, %v", len(all), err)
+	}
+	for i, e := range all {
+		if e.ID <= start || (i > 0 && e.ID <= all[i-1].ID) {
+			t.Fatalf("events not in increasing id order after %d: %v", start, all)
+		}
+	}
+	if all[1].OrgID != h.OtherOrg || all[2].Type != domain.EventSubscriptionDeleted || all[2].Data.Subscription.ID != ours.ID {
+		t.Fatalf("unexpected events %+v", all)
+	}
+	if last, err := h.Events.LastID(ctx); err != nil || last != all[2].ID {
+		t.Fatalf("last id: want %d, got %d, %v", all[2].ID, last, err)
+	}
+
+	mine, err := h.Events.After(ctx, start, 10)
+	if err != nil || len(mine) != 2 || mine[0].ID != all[0].ID || mine[1].ID != all[2].ID {
+		t.Fatalf("after: want the tenant's 2 events, got %v, %v", mine, err)
+	}
+	if rest, _ := h.Events.After(ctx, all[0].ID, 10); len(rest) != 1 || rest[0].ID != all[2].ID {
+		t.Fatalf("after %d: want the last event, got %v", all[0].ID, rest)
+	}
+	if limited, _ := h.Events.AllAfter(ctx, start, 1); len(limited) != 1 || limited[0].ID != all[0].ID {
+		t.Fatalf("all after ignores the limit: %v", limited)
+	}
+	if theirs, _ := h.Events.After(other, start, 10); len(theirs) != 1 || theirs[0].ID != all[1].ID {
+		t.Fatalf("events leaked across organizations: %v", theirs)
+	}
+
+	due := Month(2025, 8)
+	reminder := &domain.Event{OrgID: h.OtherOrg, Type: domain.EventSubscriptionRenewing, Data: domain.EventData{Subscription: ours, DueOn: &due}}
+	if err := h.Outbox.Append(ctx, reminder); err != nil || reminder.ID <= all[2].ID {
+		t.Fatalf("append: want an id after %d, got %d, %v", all[2].ID, reminder.ID, err)
+	}
+	if theirs, _ := h.Events.After(other, all[2].ID, 10); len(theirs) != 1 || theirs[0].Type != domain.EventSubscriptionRenewing ||
+		theirs[0].Data.DueOn == nil || !theirs[0].Data.DueOn.Equal(due) {
+		t.Fatalf("appended event not in its organization's log: %v", theirs)
+	}
+}
@@ -0,0 +1,120 @@
+package repotest
+
+import (
+	"context"
+	"slices"
+	"subcalc/internal/domain"
+	"subcalc/internal/tenant"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testReminders(t *testing.T, h Harness) {
+	ctx := context.Background()
+	other := tenant.WithOrganization(ctx, &domain.Organization{ID: h.OtherOrg})
+	aug := Month(2025, 8)
+
+	renewing := create(t, ctx, h.Repo, &domain.Subscription{ServiceName: "Netflix", Price: 499, UserID: uuid.New(), StartDate: Month(2025, 1)})
+	ending := create(t, other, h.Repo, &domain.Subscription{ServiceName: "Spotify", Price: 199, UserID: uuid.New(), StartDate: Month(2025, 1), EndDate: ptr(Month(2025, 7))})
+	continuing := create(t, ctx, h.Repo, &domain.Subscription{ServiceName: "Okko", Price: 299, UserID: uuid.New(), StartDate: Month(2025, 7), EndDate: ptr(Month(2025, 12))})
+	// over before July, or not started by August
+	create(t, ctx, h.Repo, &domain.Subscription{ServiceName: "Ivi", Price: 99, UserID: uuid.New(), StartDate: Month(2025, 1), EndDate: ptr(Month(2025, 6))})
+	create(t, ctx, h.Repo, &domain.Subscription{ServiceName: "Kion", Price: 99, UserID: uuid.New(), StartDate: aug})
+
+	var got []uuid.UUID
+	for after := uuid.Nil; ; {
+		page, err := h.Reminders.Due(ctx, aug, after, 2)
+		if err != nil {
+			t.Fatalf("due: %v", err)
+		}
+		for _, sub := range page {
+			got = append(got, sub.ID)
+		}
+		if len(page) < 2 {
+			break
+		}
+		after = page[len(page)-1].ID
+	}
+	want := []uuid.UUID{renewing.ID, ending.ID, continuing.ID}
+	for _, id := range want {
+		if len(got) != len(want) || !slices.Contains(got, id) {
+			t.Fatalf("due at 08-2025: want %v across organizations, got %v", want, got)
+		}
+	}
+
+	rem := &domain.Reminder{OrgID: tenant.DefaultOrgID, SubscriptionID: renewing.ID, Kind: domain.ReminderRenewal, DueOn: aug, Channel: "log"}
+	pending, err := h.Reminders.Track(ctx, rem)
+	if err != nil || !pending || rem.ID == uuid.Nil || rem.Attempts != 0 || rem.CreatedAt.IsZero() {
+		t.Fatalf("track new: want a pending reminder, got %v %+v, %v", pending, rem, err)
+	}
+	rem.Attempts, rem.Error = 1, "connection refused"
+	if err := h.Reminders.SaveAttempt(ctx, rem); err != nil {
+		t.Fatalf("save attempt: %v", err)
+	}
+	again := &domain.Reminder{OrgID: tenant.DefaultOrgID, SubscriptionID: renewing.ID, Kind: domain.ReminderRenewal, DueOn: aug, Channel: "log"}
+	if pending, err := h.Reminders.Track(ctx, again); err != nil || !pending || again.ID != rem.ID || again.Attempts != 1 || again.Error != "connection refused" {
+		t.Fatalf("track failed: want the same reminder still pending, got %v %+v, %v", pending, again, err)
+	}
+
+	sent := time.Now().UTC()
+	again.Attempts, again.Error, again.SentAt = 2, "", &sent
+	if err := h.Reminders.SaveAttempt(ctx, again); err != nil {
+		t.Fatalf("save attempt: %v", err)
+	}
+	done := &domain.Reminder{OrgID: tenant.DefaultOrgID, SubscriptionID: renewing.ID, Kind: domain.ReminderRenewal, DueOn: aug, Channel: "log"}
+	if pending, err := h.Reminders.Track(ctx, done); err != nil || pending || done.SentAt == nil || done.Attempts != 2 {
+		t.Fatalf("track sent: want the reminder done, got %v %+v, %v", pending, done, err)
+	}
+
+	// another channel, month or kind is another reminder
+	for _, r := range []*domain.Reminder{
+		{OrgID: tenant.DefaultOrgID, SubscriptionID: renewing.ID, Kind: domain.ReminderRenewal, DueOn: aug, Channel: "smtp"},
+		{OrgID: tenant.DefaultOrgID, SubscriptionID: renewing.ID, Kind: domain.ReminderRenewal, DueOn: Month(2025, 9), Channel: "log"},
+		{OrgID: h.OtherOrg, SubscriptionID: ending.ID, Kind: domain.ReminderEnding, DueOn: aug, Channel: "log"},
+	} {
+		if pending, err := h.Reminders.Track(ctx, r); err != nil || !pending || r.ID == rem.ID {
+			t.Fatalf("track %+v: want a new pending reminder, got %v, %v", r, pending, err)
+		}
+	}
+
+}
+
+func testLocks(t *testing.T, h Harness) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	if ok, err := h.Locks.TryLock(ctx, "reminders", "a", now, time.Minute); err != nil || !ok {
+		t.Fatalf("lock a free lease: got %v, %v", ok, err)
+	}
+	if ok, err := h.Locks.TryLock(ctx, "reminders", "b", now.Add(30*time.Second), time.Minute); err != nil || ok {
+		t.Fatalf("lock a held lease: got %v, %v", ok, err)
+	}
+	if ok, err := h.Locks.TryLock(ctx, "other", "b", now, time.Minute); err != nil || !ok {
+		t.Fatalf("lock another lease: got %v, %v", ok, err)
+	}
+	if ok, err := h.Locks.TryLock(ctx, "reminders", "a", now.Add(30*time.Second), time.Minute); err != nil || !ok {
+		t.Fatalf("renew an own lease: got %v, %v", ok, err)
+	}
+	// renewed until now+90s
+	if ok, _ := h.Locks.TryLock(ctx, "reminders", "b", now.Add(80*time.Second), time.Minute); ok {
+		t.Fatalf("lock taken before the renewed lease expired")
+	}
+	if ok, err := h.Locks.TryLock(ctx, "reminders", "b", now.Add(2*time.Minute), time.Minute); err != nil || !ok {
+		t.Fatalf("lock an expired lease: got %v, %v", ok, err)
+	}
+
+	if err := h.Locks.Unlock(ctx, "reminders", "a"); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	if ok, _ := h.Locks.TryLock(ctx, "reminders", "c", now.Add(2*time.Minute), time.Minute); ok {
+		t.Fatalf("unlock by a former holder released the lease")
+	}
+	if err := h.Locks.Unlock(ctx, "reminders", "b"); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	if ok, err := h.Locks.TryLock(ctx, "reminders", "c", now.Add(2*time.Minute), time.Minute); err != nil || !ok {
+		t.Fatalf("lock a released lease: got %v, %v", ok, err)
+	}
+}
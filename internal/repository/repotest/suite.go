@@ -0,0 +1,695 @@
+// Package repotest is a conformance suite for SubscriptionRepository
+// implementations. An implementation's tests call RunSubscriptionRepository
+// with a constructor for empty repositories.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"subcalc/internal/tenant"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Harness is one empty repository under test.
+type Harness struct {
+	Repo repository.SubscriptionRepository
+	// The other repositories share the storage of Repo, whose changes fill
+	// the outbox.
+	Webhooks  repository.WebhookRepository
+	Outbox    repository.OutboxRepository
+	Events    repository.EventRepository
+	Reminders repository.ReminderRepository
+	Locks     repository.LockRepository
+	// OtherOrg is an existing organization besides the default one, used to
+	// check tenant isolation.
+	OtherOrg uuid.UUID
+}
+
+// NewHarness returns a harness over an empty repository. It is called once
+// per subtest.
+type NewHarness func(t *testing.T) Harness
+
+func RunSubscriptionRepository(t *testing.T, newHarness NewHarness) {
+	t.Run("CRUD", func(t *testing.T) { testCRUD(t, newHarness(t)) })
+	t.Run("DeleteManyAndByUser", func(t *testing.T) { testDeleteMany(t, newHarness(t)) })
+	t.Run("TenantIsolation", func(t *testing.T) { testTenantIsolation(t, newHarness(t)) })
+	t.Run("Filters", func(t *testing.T) { testFilters(t, newHarness(t)) })
+	t.Run("MoreFilters", func(t *testing.T) { testMoreFilters(t, newHarness(t)) })
+	t.Run("Sort", func(t *testing.T) { testSort(t, newHarness(t)) })
+	t.Run("Search", func(t *testing.T) { testSearch(t, newHarness(t)) })
+	t.Run("LimitOffset", func(t *testing.T) { testLimitOffset(t, newHarness(t)) })
+	t.Run("Cursor", func(t *testing.T) { testCursor(t, newHarness(t)) })
+	t.Run("SumForPeriod", func(t *testing.T) { testSumForPeriod(t, newHarness) })
+	t.Run("SumForPeriodFilters", func(t *testing.T) { testSumFilters(t, newHarness(t)) })
+	t.Run("Webhooks", func(t *testing.T) { testWebhooks(t, newHarness(t)) })
+	t.Run("Outbox", func(t *testing.T) { testOutbox(t, newHarness(t)) })
+	t.Run("EventLog", func(t *testing.T) { testEventLog(t, newHarness(t)) })
+	t.Run("Reminders", func(t *testing.T) { testReminders(t, newHarness(t)) })
+	t.Run("Locks", func(t *testing.T) { testLocks(t, newHarness(t)) })
+}
+
+func Month(y int, m time.Month) time.Time {
+	return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func create(t *testing.T, ctx context.Context, repo repository.SubscriptionRepository, sub *domain.Subscription) *domain.Subscription {
+	t.Helper()
+	if err := repo.Create(ctx, sub); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return sub
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+func testCRUD(t *testing.T, h Harness) {
+	ctx := context.Background()
+	repo := h.Repo
+
+	sub := create(t, ctx, repo, &domain.Subscription{
+		ServiceName: "Netflix", Price: 499, UserID: uuid.New(), StartDate: Month(2025, 7),
+	})
+	if sub.ID == uuid.Nil {
+		t.Fatalf("create must assign an id")
+	}
+	if sub.OrgID != tenant.DefaultOrgID {
+		t.Fatalf("create must set the tenant, got %s", sub.OrgID)
+	}
+	if sub.CreatedAt.IsZero() || sub.UpdatedAt.IsZero() {
+		t.Fatalf("create must set timestamps")
+	}
+
+	preset := uuid.New()
+	if got := create(t, ctx, repo, &domain.Subscription{ID: preset, ServiceName: "Spotify", Price: 299, UserID: uuid.New(), StartDate: Month(2025, 1)}); got.ID != preset {
+		t.Fatalf("create must keep a preset id")
+	}
+	if err := repo.Create(ctx, &domain.Subscription{ID: preset, ServiceName: "Spotify", Price: 299, UserID: uuid.New(), StartDate: Month(2025, 1)}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("create with a taken id: want ErrConflict, got %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, sub.ID)
+	if err != nil || got == nil {
+		t.Fatalf("get: %v, %v", got, err)
+	}
+	if got.ServiceName != "Netflix" || got.Price != 499 || got.UserID != sub.UserID || got.OrgID != sub.OrgID ||
+		!got.StartDate.Equal(sub.StartDate) || got.EndDate != nil {
+		t.Fatalf("get returned %+v, want %+v", got, sub)
+	}
+
+	if missing, err := repo.GetByID(ctx, uuid.New()); !errors.Is(err, domain.ErrNotFound) || missing != nil {
+		t.Fatalf("get unknown id: want nil, ErrNotFound; got %v, %v", missing, err)
+	}
+
+	got.ServiceName = "Netflix Premium"
+	got.Price = 999
+	got.EndDate = ptr(Month(2025, 12))
+	before := got.UpdatedAt
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got.UpdatedAt.Before(before) {
+		t.Fatalf("update must advance updated_at")
+	}
+	updated, _ := repo.GetByID(ctx, sub.ID)
+	if updated.ServiceName != "Netflix Premium" || updated.Price != 999 || !sameDate(updated.EndDate, ptr(Month(2025, 12))) {
+		t.Fatalf("update not stored: %+v", updated)
+	}
+
+	// clearing the end date makes it open-ended again
+	updated.EndDate = nil
+	if err := repo.Update(ctx, updated); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if cleared, _ := repo.GetByID(ctx, sub.ID); cleared.EndDate != nil {
+		t.Fatalf("end date not cleared: %v", cleared.EndDate)
+	}
+
+	if err := repo.Delete(ctx, sub.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if gone, _ := repo.GetByID(ctx, sub.ID); gone != nil {
+		t.Fatalf("deleted subscription still found")
+	}
+	if err := repo.Delete(ctx, sub.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("delete missing id: want ErrNotFound, got %v", err)
+	}
+	if err := repo.Update(ctx, updated); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("update missing id: want ErrNotFound, got %v", err)
+	}
+}
+
+func testDeleteMany(t *testing.T, h Harness) {
+	ctx := context.Background()
+	repo := h.Repo
+	user := uuid.New()
+
+	a := create(t, ctx, repo, &domain.Subscription{ServiceName: "A", Price: 1, UserID: user, StartDate: Month(2025, 1)})
+	b := create(t, ctx, repo, &domain.Subscription{ServiceName: "B", Price: 1, UserID: user, StartDate: Month(2025, 1)})
+	c := create(t, ctx, repo, &domain.Subscription{ServiceName: "C", Price: 1, UserID: uuid.New(), StartDate: Month(2025, 1)})
+	create(t, ctx, repo, &domain.Subscription{ServiceName: "D", Price: 1, UserID: user, StartDate: Month(2025, 1)})
+
+	if n, err := repo.DeleteMany(ctx, nil); err != nil || n != 0 {
+		t.Fatalf("delete none: %d, %v", n, err)
+	}
+	if n, err := repo.DeleteMany(ctx, []uuid.UUID{a.ID, c.ID, uuid.New()}); err != nil || n != 2 {
+		t.Fatalf("delete many: want 2, got %d, %v", n, err)
+	}
+	if n, err := repo.DeleteByUser(ctx, user); err != nil || n != 2 {
+		t.Fatalf("delete by user: want 2, got %d, %v", n, err)
+	}
+	if got, _ := repo.GetByID(ctx, b.ID); got != nil {
+		t.Fatalf("user's subscription not deleted")
+	}
+	if n, _ := repo.Count(ctx, repository.SubscriptionFilter{}); n != 0 {
+		t.Fatalf("expected empty repository, %d left", n)
+	}
+}
+
+func testTenantIsolation(t *testing.T, h Harness) {
+	ctx := context.Background()
+	other := tenant.WithOrganization(ctx, &domain.Organization{ID: h.OtherOrg})
+	repo := h.Repo
+	user := uuid.New()
+
+	mine := create(t, ctx, repo, &domain.Subscription{ServiceName: "A", Price: 10, UserID: user, StartDate: Month(2025, 1)})
+	theirs := create(t, other, repo, &domain.Subscription{ServiceName: "A", Price: 20, UserID: user, StartDate: Month(2025, 1)})
+	if theirs.OrgID != h.OtherOrg {
+		t.Fatalf("create must take the org from the context")
+	}
+
+	if got, err := repo.GetByID(ctx, theirs.ID); got != nil || !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("get leaked a subscription across organizations")
+	}
+	if n, _ := repo.Count(ctx, repository.SubscriptionFilter{}); n != 1 {
+		t.Fatalf("count across organizations: %d", n)
+	}
+	period := repository.SubscriptionFilter{From: ptr(Month(2025, 1)), To: ptr(Month(2025, 1))}
+	if total, _ := repo.SumForPeriod(ctx, period); total != 10 {
+		t.Fatalf("sum across organizations: %d", total)
+	}
+
+	if err := repo.Delete(ctx, theirs.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("delete across organizations: want ErrNotFound, got %v", err)
+	}
+	foreign := *theirs
+	foreign.Price = 30
+	if err := repo.Update(ctx, &foreign); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("update across organizations: want ErrNotFound, got %v", err)
+	}
+	if n, _ := repo.DeleteByUser(ctx, user); n != 1 {
+		t.Fatalf("delete by user across organizations: %d", n)
+	}
+	if got, _ := repo.GetByID(other, theirs.ID); got == nil || got.Price != 20 {
+		t.Fatalf("another organization's subscription was changed: %+v", got)
+	}
+	_ = mine
+}
+
+// fixtures for the filter tests: two users, two services, spread over 2025
+func seedFilterFixtures(t *testing.T, repo repository.SubscriptionRepository) (users [2]uuid.UUID, subs []*domain.Subscription) {
+	ctx := context.Background()
+	users = [2]uuid.UUID{uuid.New(), uuid.New()}
+	rows := []struct {
+		user    int
+		service string
+		start   time.Time
+		end     *time.Time
+	}{
+		{0, "Netflix", Month(2024, 6), nil},
+		{0, "Netflix", Month(2025, 2), ptr(Month(2025, 4))},
+		{0, "Spotify", Month(2025, 5), ptr(Month(2025, 5))},
+		{0, "Spotify", Month(2025, 11), nil},
+		{1, "Netflix", Month(2024, 1), ptr(Month(2024, 12))},
+		{1, "Netflix", Month(2025, 8), nil},
+		{1, "Spotify", Month(2026, 1), nil},
+		{1, "Spotify", Month(2024, 3), ptr(Month(2025, 1))},
+	}
+	for i, r := range rows {
+		subs = append(subs, create(t, ctx, repo, &domain.Subscription{
+			ServiceName: r.service, Price: 100 + i, UserID: users[r.user], StartDate: r.start, EndDate: r.end,
+		}))
+	}
+	return users, subs
+}
+
+// matches is the reference for the WHERE clause of List, FindForPeriod and
+// Count: a subscription matches a period when it overlaps it.
+func matches(sub *domain.Subscription, f repository.SubscriptionFilter) bool {
+	if f.UserID != nil && sub.UserID != *f.UserID {
+		return false
+	}
+	if f.ServiceName != nil && sub.ServiceName != *f.ServiceName {
+		return false
+	}
+	if f.To != nil && sub.StartDate.After(*f.To) {
+		return false
+	}
+	if f.From != nil && sub.EndDate != nil && sub.EndDate.Before(*f.From) {
+		return false
+	}
+	return true
+}
+
+func ids(subs []*domain.Subscription) []uuid.UUID {
+	out := make([]uuid.UUID, 0, len(subs))
+	for _, s := range subs {
+		out = append(out, s.ID)
+	}
+	slices.SortFunc(out, func(a, b uuid.UUID) int { return slices.Compare(a[:], b[:]) })
+	return out
+}
+
+func testFilters(t *testing.T, h Harness) {
+	ctx := context.Background()
+	users, subs := seedFilterFixtures(t, h.Repo)
+
+	userOpts := []*uuid.UUID{nil, &users[0], &users[1], ptr(uuid.New())}
+	serviceOpts := []*string{nil, ptr("Netflix"), ptr("Spotify"), ptr("netflix")}
+	fromOpts := []*time.Time{nil, ptr(Month(2025, 1)), ptr(Month(2025, 5))}
+	toOpts := []*time.Time{nil, ptr(Month(2025, 4)), ptr(Month(2025, 12))}
+
+	for _, u := range userOpts {
+		for _, s := range serviceOpts {
+			for _, from := range fromOpts {
+				for _, to := range toOpts {
+					f := repository.SubscriptionFilter{UserID: u, ServiceName: s, From: from, To: to, Limit: 1000}
+					var want []*domain.Subscription
+					for _, sub := range subs {
+						if matches(sub, f) {
+							want = append(want, sub)
+						}
+					}
+
+					list, err := h.Repo.List(ctx, f)
+					if err != nil {
+						t.Fatalf("list %s: %v", describe(f), err)
+					}
+					if !slices.Equal(ids(list), ids(want)) {
+						t.Errorf("list %s: got %d subscriptions, want %d", describe(f), len(list), len(want))
+					}
+					found, err := h.Repo.FindForPeriod(ctx, f)
+					if err != nil || !slices.Equal(ids(found), ids(want)) {
+						t.Errorf("find for period %s: got %d subscriptions, want %d (%v)", describe(f), len(found), len(want), err)
+					}
+					n, err := h.Repo.Count(ctx, f)
+					if err != nil || n != int64(len(want)) {
+						t.Errorf("count %s: got %d, want %d (%v)", describe(f), n, len(want), err)
+					}
+				}
+			}
+		}
+	}
+}
+
+// prices identifies the fixtures of seedFilterFixtures, priced 100 to 107 in
+// creation order.
+func prices(subs []*domain.Subscription) []int {
+	out := make([]int, len(subs))
+	for i, s := range subs {
+		out[i] = s.Price
+	}
+	return out
+}
+
+func testMoreFilters(t *testing.T, h Harness) {
+	ctx := context.Background()
+	users, _ := seedFilterFixtures(t, h.Repo)
+
+	check := func(name string, f repository.SubscriptionFilter, want []int) {
+		t.Helper()
+		f.Limit = -1
+		list, err := h.Repo.List(ctx, f)
+		if err != nil {
+			t.Fatalf("%s: list: %v", name, err)
+		}
+		got := prices(list)
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: list got %v, want %v", name, got, want)
+		}
+		found, err := h.Repo.FindForPeriod(ctx, f)
+		if err != nil || len(found) != len(want) {
+			t.Errorf("%s: find for period got %d, want %d (%v)", name, len(found), len(want), err)
+		}
+		if n, err := h.Repo.Count(ctx, f); err != nil || n != int64(len(want)) {
+			t.Errorf("%s: count got %d, want %d (%v)", name, n, len(want), err)
+		}
+	}
+	all := []int{100, 101, 102, 103, 104, 105, 106, 107}
+	netflix := []int{100, 101, 104, 105}
+
+	check("user ids", repository.SubscriptionFilter{UserIDs: users[:]}, all)
+	check("user ids with unknown", repository.SubscriptionFilter{UserIDs: []uuid.UUID{users[1], uuid.New()}}, []int{104, 105, 106, 107})
+	check("user ids narrow user id", repository.SubscriptionFilter{UserID: &users[0], UserIDs: []uuid.UUID{users[1]}}, []int{})
+	check("price min", repository.SubscriptionFilter{PriceMin: ptr(103)}, []int{103, 104, 105, 106, 107})
+	check("price max", repository.SubscriptionFilter{PriceMax: ptr(101)}, []int{100, 101})
+	check("price range", repository.SubscriptionFilter{PriceMin: ptr(102), PriceMax: ptr(104)}, []int{102, 103, 104})
+	check("prefix", repository.SubscriptionFilter{ServiceNamePrefix: ptr("Net")}, netflix)
+	check("prefix is case-sensitive", repository.SubscriptionFilter{ServiceNamePrefix: ptr("net")}, []int{})
+	check("prefix ignoring case", repository.SubscriptionFilter{ServiceNamePrefix: ptr("net"), IgnoreCase: true}, netflix)
+	check("name ignoring case", repository.SubscriptionFilter{ServiceName: ptr("NETFLIX"), IgnoreCase: true}, netflix)
+	check("empty prefix", repository.SubscriptionFilter{ServiceNamePrefix: ptr("")}, all)
+	check("active on", repository.SubscriptionFilter{ActiveOn: ptr(Month(2025, 5))}, []int{100, 102})
+	check("active on last month", repository.SubscriptionFilter{ActiveOn: ptr(Month(2025, 1))}, []int{100, 107})
+	check("open-ended", repository.SubscriptionFilter{OpenEnded: ptr(true)}, []int{100, 103, 105, 106})
+	check("not open-ended", repository.SubscriptionFilter{OpenEnded: ptr(false)}, []int{101, 102, 104, 107})
+	check("active and open-ended", repository.SubscriptionFilter{ActiveOn: ptr(Month(2025, 12)), OpenEnded: ptr(true)}, []int{100, 103, 105})
+
+	// timestamps as stored, which may be coarser than the clock
+	stored, err := h.Repo.List(ctx, repository.SubscriptionFilter{Limit: -1})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var want []int
+	for _, s := range stored {
+		if s.CreatedAt.After(stored[3].CreatedAt) {
+			want = append(want, s.Price)
+		}
+	}
+	slices.Sort(want)
+	check("created after", repository.SubscriptionFilter{CreatedAfter: &stored[3].CreatedAt}, want)
+
+	after := stored[len(stored)-1].UpdatedAt
+	time.Sleep(10 * time.Millisecond)
+	changed := stored[2]
+	if err := h.Repo.Update(ctx, changed); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	check("updated after", repository.SubscriptionFilter{UpdatedAfter: &after}, []int{changed.Price})
+}
+
+func testSort(t *testing.T, h Harness) {
+	ctx := context.Background()
+	seedFilterFixtures(t, h.Repo)
+
+	cases := []struct {
+		name          string
+		sort          []repository.Sort
+		limit, offset int
+		want          []int
+	}{
+		{"price descending", []repository.Sort{{Key: repository.SortPrice, Desc: true}}, -1, 0,
+			[]int{107, 106, 105, 104, 103, 102, 101, 100}},
+		{"service, then latest start", []repository.Sort{{Key: repository.SortServiceName}, {Key: repository.SortStartDate, Desc: true}}, -1, 0,
+			[]int{105, 101, 100, 104, 106, 103, 102, 107}},
+		{"open-ended end last", []repository.Sort{{Key: repository.SortEndDate}}, -1, 0,
+			[]int{104, 107, 101, 102, 100, 103, 105, 106}},
+		{"open-ended end first descending", []repository.Sort{{Key: repository.SortEndDate, Desc: true}}, -1, 0,
+			[]int{100, 103, 105, 106, 102, 101, 107, 104}},
+		{"page of a sorted list", []repository.Sort{{Key: repository.SortPrice}}, 3, 2,
+			[]int{102, 103, 104}},
+	}
+	for _, c := range cases {
+		list, err := h.Repo.List(ctx, repository.SubscriptionFilter{Sort: c.sort, Limit: c.limit, Offset: c.offset})
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if got := prices(list); !slices.Equal(got, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func testSearch(t *testing.T, h Harness) {
+	ctx := context.Background()
+	users := [2]uuid.UUID{uuid.New(), uuid.New()}
+	for i, name := range []string{"Yandex Plus", "Netflix", "Spotify Premium", "YouTube Premium"} {
+		create(t, ctx, h.Repo, &domain.Subscription{ServiceName: name, Price: 100 + i, UserID: users[i/2], StartDate: Month(2025, 1)})
+	}
+	other := tenant.WithOrganization(ctx, &domain.Organization{ID: h.OtherOrg})
+	create(t, other, h.Repo, &domain.Subscription{ServiceName: "Yandex Music", Price: 1, UserID: users[0], StartDate: Month(2025, 1)})
+
+	names := func(hits []repository.SearchHit) []string {
+		out := make([]string, len(hits))
+		for i, hit := range hits {
+			out[i] = hit.Subscription.ServiceName
+		}
+		return out
+	}
+	cases := []struct {
+		name   string
+		query  string
+		filter repository.SubscriptionFilter
+		want   []string
+	}{
+		{"misspelt", "yandx", repository.SubscriptionFilter{}, []string{"Yandex Plus"}},
+		{"any case", "NETFLIX", repository.SubscriptionFilter{}, []string{"Netflix"}},
+		{"one word of several", "premium", repository.SubscriptionFilter{UserID: &users[1]}, []string{"Spotify Premium", "YouTube Premium"}},
+		{"filtered out", "premium", repository.SubscriptionFilter{UserIDs: users[:1]}, []string{}},
+		{"limit", "premium", repository.SubscriptionFilter{Limit: 1}, []string{"Spotify Premium"}},
+		{"nothing alike", "disney", repository.SubscriptionFilter{}, []string{}},
+	}
+	for _, c := range cases {
+		hits, err := h.Repo.Search(ctx, c.query, c.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if got := names(hits); !slices.Equal(got, c.want) {
+			t.Errorf("%s: search %q got %v, want %v", c.name, c.query, got, c.want)
+		}
+		for i, hit := range hits {
+			if hit.Score <= 0 || hit.Score > 1 || (i > 0 && hit.Score > hits[i-1].Score) {
+				t.Errorf("%s: scores must be in (0, 1] and descending, got %v at %d", c.name, hit.Score, i)
+			}
+		}
+	}
+
+	hits, err := h.Repo.Search(ctx, "netflix", repository.SubscriptionFilter{})
+	if err != nil || len(hits) != 1 || hits[0].Score != 1 {
+		t.Errorf("an exact match must score 1, got %+v, %v", hits, err)
+	}
+}
+
+func describe(f repository.SubscriptionFilter) string {
+	s := "{"
+	if f.UserID != nil {
+		s += " user=" + f.UserID.String()[:8]
+	}
+	if f.ServiceName != nil {
+		s += " service=" + *f.ServiceName
+	}
+	if f.From != nil {
+		s += " from=" + domain.FormatMonthYear(*f.From)
+	}
+	if f.To != nil {
+		s += " to=" + domain.FormatMonthYear(*f.To)
+	}
+	return s + " }"
+}
+
+func testLimitOffset(t *testing.T, h Harness) {
+	ctx := context.Background()
+	var all []*domain.Subscription
+	for i := range 5 {
+		all = append(all, create(t, ctx, h.Repo, &domain.Subscription{ServiceName: "S", Price: i, UserID: uuid.New(), StartDate: Month(2025, 1)}))
+	}
+
+	cases := []struct {
+		name          string
+		limit, offset int
+		want          int
+	}{
+		{"zero limit uses the default page size", 0, 0, 5},
+		{"negative limit is unlimited", -1, 0, 5},
+		{"limit below total", 2, 0, 2},
+		{"last partial page", 2, 4, 1},
+		{"offset at total", 10, 5, 0},
+		{"offset past total", 10, 50, 0},
+	}
+	for _, c := range cases {
+		got, err := h.Repo.List(ctx, repository.SubscriptionFilter{Limit: c.limit, Offset: c.offset})
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if len(got) != c.want {
+			t.Errorf("%s: got %d, want %d", c.name, len(got), c.want)
+		}
+	}
+
+	// consecutive pages cover every row once
+	var paged []*domain.Subscription
+	for offset := 0; offset < len(all); offset += 2 {
+		page, err := h.Repo.List(ctx, repository.SubscriptionFilter{Limit: 2, Offset: offset})
+		if err != nil {
+			t.Fatalf("page at %d: %v", offset, err)
+		}
+		paged = append(paged, page...)
+	}
+	if !slices.Equal(ids(paged), ids(all)) {
+		t.Errorf("pages do not cover every subscription exactly once")
+	}
+}
+
+func testCursor(t *testing.T, h Harness) {
+	ctx := context.Background()
+	for i := range 7 {
+		create(t, ctx, h.Repo, &domain.Subscription{ServiceName: "S", Price: i, UserID: uuid.New(), StartDate: Month(2025, 1)})
+	}
+
+	all, err := h.Repo.List(ctx, repository.SubscriptionFilter{Limit: -1})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !slices.IsSortedFunc(all, func(a, b *domain.Subscription) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return slices.Compare(a.ID[:], b.ID[:])
+	}) {
+		t.Fatalf("list is not ordered by created_at, id")
+	}
+
+	// forward from the first page, then back from the end
+	var forward []*domain.Subscription
+	filter := repository.SubscriptionFilter{Limit: 3}
+	for range len(all) {
+		page, err := h.Repo.List(ctx, filter)
+		if err != nil {
+			t.Fatalf("forward page: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		forward = append(forward, page...)
+		filter.Cursor = repository.CursorAfter(page[len(page)-1])
+	}
+	if !slices.Equal(listIDs(forward), listIDs(all)) {
+		t.Errorf("forward pages: got %v, want %v", listIDs(forward), listIDs(all))
+	}
+
+	var backward []*domain.Subscription
+	filter.Cursor = &repository.Cursor{CreatedAt: all[len(all)-1].CreatedAt, ID: all[len(all)-1].ID, Backward: true}
+	backward = append(backward, all[len(all)-1])
+	for range len(all) {
+		page, err := h.Repo.List(ctx, filter)
+		if err != nil {
+			t.Fatalf("backward page: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		backward = append(slices.Clone(page), backward...)
+		filter.Cursor = repository.CursorBefore(page[0])
+	}
+	if !slices.Equal(listIDs(backward), listIDs(all)) {
+		t.Errorf("backward pages: got %v, want %v", listIDs(backward), listIDs(all))
+	}
+
+	// a cursor takes the place of the offset
+	page, err := h.Repo.List(ctx, repository.SubscriptionFilter{Limit: 2, Offset: 100, Cursor: repository.CursorAfter(all[1])})
+	if err != nil {
+		t.Fatalf("cursor with offset: %v", err)
+	}
+	if !slices.Equal(listIDs(page), listIDs(all[2:4])) {
+		t.Errorf("cursor with offset: got %v, want %v", listIDs(page), listIDs(all[2:4]))
+	}
+}
+
+// listIDs are the ids of subs in their order.
+func listIDs(subs []*domain.Subscription) []uuid.UUID {
+	out := make([]uuid.UUID, len(subs))
+	for i, s := range subs {
+		out[i] = s.ID
+	}
+	return out
+}
+
+// SumCase is one subscription and the total it contributes to a period.
+type SumCase struct {
+	Name     string
+	Start    time.Time
+	End      *time.Time
+	Price    int
+	From, To time.Time
+	Want     int64
+}
+
+// SumCases pin down SumForPeriod: every month of the overlap of the
+// subscription and the period counts once, both ends inclusive, with the
+// month arithmetic of Postgres AGE for dates that are not the 1st.
+var SumCases = []SumCase{
+	{Name: "open-ended, started before the period", Start: Month(2024, 3), Price: 100, From: Month(2025, 1), To: Month(2025, 12), Want: 1200},
+	{Name: "covers the period on both sides", Start: Month(2024, 1), End: ptr(Month(2026, 6)), Price: 100, From: Month(2025, 1), To: Month(2025, 12), Want: 1200},
+	{Name: "inside the period", Start: Month(2025, 3), End: ptr(Month(2025, 5)), Price: 10, From: Month(2025, 1), To: Month(2025, 12), Want: 30},
+	{Name: "single month subscription", Start: Month(2025, 6), End: ptr(Month(2025, 6)), Price: 7, From: Month(2025, 1), To: Month(2025, 12), Want: 7},
+	{Name: "ends on the first month of the period", Start: Month(2024, 1), End: ptr(Month(2025, 1)), Price: 50, From: Month(2025, 1), To: Month(2025, 12), Want: 50},
+	{Name: "starts on the last month of the period", Start: Month(2025, 12), Price: 50, From: Month(2025, 1), To: Month(2025, 12), Want: 50},
+	{Name: "ends the month before the period", Start: Month(2024, 1), End: ptr(Month(2024, 12)), Price: 1000, From: Month(2025, 1), To: Month(2025, 12), Want: 0},
+	{Name: "starts the month after the period", Start: Month(2026, 1), Price: 1000, From: Month(2025, 1), To: Month(2025, 12), Want: 0},
+	{Name: "single month period", Start: Month(2024, 1), Price: 300, From: Month(2025, 4), To: Month(2025, 4), Want: 300},
+	{Name: "period across a year boundary", Start: Month(2024, 11), End: ptr(Month(2025, 2)), Price: 10, From: Month(2024, 12), To: Month(2025, 3), Want: 30},
+	{Name: "period bounds are truncated to the month", Start: Month(2025, 1), End: ptr(Month(2025, 3)), Price: 10, From: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC), To: time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC), Want: 30},
+	{Name: "mid-month start counts whole months only", Start: time.Date(2025, 11, 15, 0, 0, 0, 0, time.UTC), Price: 1, From: Month(2025, 1), To: Month(2025, 12), Want: 1},
+	{Name: "free subscription", Start: Month(2024, 1), Price: 0, From: Month(2025, 1), To: Month(2025, 12), Want: 0},
+	{Name: "from after to", Start: Month(2024, 1), Price: 100, From: Month(2025, 6), To: Month(2025, 1), Want: 0},
+}
+
+func testSumForPeriod(t *testing.T, newHarness NewHarness) {
+	for _, c := range SumCases {
+		t.Run(c.Name, func(t *testing.T) {
+			h := newHarness(t)
+			ctx := context.Background()
+			create(t, ctx, h.Repo, &domain.Subscription{ServiceName: "S", Price: c.Price, UserID: uuid.New(), StartDate: c.Start, EndDate: c.End})
+
+			total, err := h.Repo.SumForPeriod(ctx, repository.SubscriptionFilter{From: &c.From, To: &c.To})
+			if err != nil {
+				t.Fatalf("sum: %v", err)
+			}
+			if total != c.Want {
+				t.Fatalf("sum = %d, want %d", total, c.Want)
+			}
+		})
+	}
+}
+
+func testSumFilters(t *testing.T, h Harness) {
+	ctx := context.Background()
+	users, _ := seedFilterFixtures(t, h.Repo)
+
+	if total, err := h.Repo.SumForPeriod(ctx, repository.SubscriptionFilter{}); err != nil || total != 0 {
+		t.Fatalf("sum without a period must be 0, got %d, %v", total, err)
+	}
+
+	period := func(f repository.SubscriptionFilter) repository.SubscriptionFilter {
+		f.From, f.To = ptr(Month(2025, 1)), ptr(Month(2025, 12))
+		return f
+	}
+	cases := []struct {
+		name   string
+		filter repository.SubscriptionFilter
+		want   int64
+	}{
+		// 100*12 + 101*3 + 102*1 + 103*2 + 105*5 + 107*1
+		{"all", period(repository.SubscriptionFilter{}), 1200 + 303 + 102 + 206 + 525 + 107},
+		{"user", period(repository.SubscriptionFilter{UserID: &users[0]}), 1200 + 303 + 102 + 206},
+		{"service", period(repository.SubscriptionFilter{ServiceName: ptr("Spotify")}), 102 + 206 + 107},
+		{"user and service", period(repository.SubscriptionFilter{UserID: &users[1], ServiceName: ptr("Netflix")}), 525},
+		{"service is case-sensitive", period(repository.SubscriptionFilter{ServiceName: ptr("spotify")}), 0},
+		{"service ignoring case", period(repository.SubscriptionFilter{ServiceName: ptr("spotify"), IgnoreCase: true}), 102 + 206 + 107},
+		{"price range", period(repository.SubscriptionFilter{PriceMin: ptr(102), PriceMax: ptr(105)}), 102 + 206 + 525},
+		{"user ids", period(repository.SubscriptionFilter{UserIDs: []uuid.UUID{users[1]}}), 525 + 107},
+		{"open-ended", period(repository.SubscriptionFilter{OpenEnded: ptr(true)}), 1200 + 206 + 525},
+	}
+	for _, c := range cases {
+		total, err := h.Repo.SumForPeriod(ctx, c.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if total != c.want {
+			t.Errorf("%s: sum = %d, want %d", c.name, total, c.want)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatsRepository serves operational aggregates. Unlike the tenant
+// repositories it reads across all organizations.
+type StatsRepository interface {
+	// ActiveByOrg counts subscriptions active in the month of at.
+	ActiveByOrg(ctx context.Context, at time.Time) (map[uuid.UUID]int64, error)
+}
@@ -8,23 +8,113 @@ import (
 	"github.com/google/uuid"
 )
 
+// SubscriptionRepository stores subscriptions. Create fails with
+// domain.ErrConflict when the id is taken; GetByID, Update and Delete fail
+// with domain.ErrNotFound when no subscription of the tenant has it.
 type SubscriptionRepository interface {
 	Create(ctx context.Context, sub *domain.Subscription) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error)
 	Update(ctx context.Context, sub *domain.Subscription) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteMany(ctx context.Context, ids []uuid.UUID) (int64, error)
+	DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error)
 	List(ctx context.Context, filter SubscriptionFilter) ([]*domain.Subscription, error)
 
 	FindForPeriod(ctx context.Context, filter SubscriptionFilter) ([]*domain.Subscription, error)
 	SumForPeriod(ctx context.Context, filter SubscriptionFilter) (int64, error)
 	Count(ctx context.Context, filter SubscriptionFilter) (int64, error)
+	// Search finds subscriptions whose service name fuzzily matches query,
+	// best first, among those matching filter. Sort, Offset and Cursor are
+	// ignored; Limit defaults to 20.
+	Search(ctx context.Context, query string, filter SubscriptionFilter) ([]SearchHit, error)
 }
 
+// SearchHit is a subscription found by Search and how well it matched,
+// from 0 to 1.
+type SearchHit struct {
+	Subscription *domain.Subscription
+	Score        float64
+}
+
+// SubscriptionFilter selects subscriptions; every condition set must hold.
+// List returns them ordered by Sort, then by creation time and ID; the
+// default order without Sort is what Cursor positions refer to.
 type SubscriptionFilter struct {
-	UserID      *uuid.UUID
+	UserID *uuid.UUID
+	// UserIDs matches any of the users. It narrows UserID rather than
+	// replacing it.
+	UserIDs     []uuid.UUID
 	ServiceName *string
-	From        *time.Time
-	To          *time.Time
-	Limit       int
-	Offset      int
+	// ServiceNamePrefix matches service names starting with it.
+	ServiceNamePrefix *string
+	// IgnoreCase compares ServiceName and ServiceNamePrefix without regard
+	// to case.
+	IgnoreCase bool
+	// PriceMin and PriceMax bound the price, both inclusive.
+	PriceMin *int
+	PriceMax *int
+	// From and To select subscriptions overlapping the period.
+	From *time.Time
+	To   *time.Time
+	// ActiveOn selects subscriptions running in the month.
+	ActiveOn *time.Time
+	// OpenEnded selects subscriptions without an end date when true and
+	// with one when false.
+	OpenEnded    *bool
+	CreatedAfter *time.Time
+	UpdatedAfter *time.Time
+
+	Sort   []Sort
+	Limit  int
+	Offset int
+	// Cursor continues a List from a row instead of skipping Offset rows.
+	// It pages in the default order; Sort is ignored with it.
+	Cursor *Cursor
+}
+
+// SortKey is a column List can order by.
+type SortKey string
+
+const (
+	SortServiceName SortKey = "service_name"
+	SortPrice       SortKey = "price"
+	SortStartDate   SortKey = "start_date"
+	// SortEndDate orders open-ended subscriptions after every end date.
+	SortEndDate   SortKey = "end_date"
+	SortCreatedAt SortKey = "created_at"
+	SortUpdatedAt SortKey = "updated_at"
+)
+
+func (k SortKey) Valid() bool {
+	switch k {
+	case SortServiceName, SortPrice, SortStartDate, SortEndDate, SortCreatedAt, SortUpdatedAt:
+		return true
+	}
+	return false
+}
+
+// Sort is one key of a List order.
+type Sort struct {
+	Key  SortKey
+	Desc bool
+}
+
+// Cursor is a keyset position in the List order: the sort key and ID of the
+// row a page ends or starts at.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+	// Backward selects the rows before the position rather than after.
+	// They are still returned in List order.
+	Backward bool
+}
+
+// CursorAfter is the position following sub.
+func CursorAfter(sub *domain.Subscription) *Cursor {
+	return &Cursor{CreatedAt: sub.CreatedAt, ID: sub.ID}
+}
+
+// CursorBefore is the position preceding sub.
+func CursorBefore(sub *domain.Subscription) *Cursor {
+	return &Cursor{CreatedAt: sub.CreatedAt, ID: sub.ID, Backward: true}
 }
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"subcalc/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookRepository stores the webhooks of the tenant. GetByID, Update and
+// Delete fail with domain.ErrNotFound for ids of no webhook of the tenant.
+type WebhookRepository interface {
+	Create(ctx context.Context, hook *domain.Webhook) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error)
+	List(ctx context.Context) ([]*domain.Webhook, error)
+	Update(ctx context.Context, hook *domain.Webhook) error
+	// Delete removes the webhook together with its deliveries.
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListDeliveries returns the latest deliveries of a webhook, newest
+	// first.
+	ListDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]*domain.WebhookDelivery, error)
+}
+
+// OutboxRepository works through the events the subscription repository
+// records with each change. It is not tenant-scoped: one worker serves
+// every organization, and several workers may share a database.
+type OutboxRepository interface {
+	// Append records events that belong to no subscription change, such
+	// as reminders, and sets their ids and times.
+	Append(ctx context.Context, events ...*domain.Event) error
+	// DispatchEvents turns up to limit undispatched events, oldest first,
+	// into pending deliveries to the webhooks that want them and marks the
+	// events dispatched. It returns how many events it dispatched.
+	DispatchEvents(ctx context.Context, limit int) (int, error)
+	// ClaimDeliveries returns up to limit pending deliveries due at now and
+	// postpones them by lease, so that other workers skip them while the
+	// caller attempts them.
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]DueDelivery, error)
+	// SaveAttempt records the outcome of an attempt: the status, attempts,
+	// next attempt and response fields of d.
+	SaveAttempt(ctx context.Context, d *domain.WebhookDelivery) error
+	// Prune deletes the dispatched events and the finished deliveries
+	// created before t, and returns how many rows it deleted.
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+// DueDelivery is a claimed delivery and the webhook to send it to.
+type DueDelivery struct {
+	Delivery *domain.WebhookDelivery
+	Webhook  *domain.Webhook
+}
@@ -0,0 +1,139 @@
+// Package search ranks and highlights fuzzy matches of service names. It
+// follows the trigram model of Postgres' pg_trgm, which the Postgres
+// repository searches with, so that the other backends rank alike.
+package search
+
+import (
+	"cmp"
+	"html"
+	"slices"
+	"strings"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Threshold is the least score a match needs, pg_trgm's default
+// similarity threshold.
+const Threshold = 0.3
+
+// Trigrams are the trigrams of s as pg_trgm extracts them: every word,
+// lower-cased and padded with two spaces in front and one behind, is cut
+// into overlapping runs of three characters.
+func Trigrams(s string) map[string]struct{} {
+	out := map[string]struct{}{}
+	for _, w := range words(s) {
+		r := []rune("  " + strings.ToLower(w) + " ")
+		for i := 0; i+3 <= len(r); i++ {
+			out[string(r[i:i+3])] = struct{}{}
+		}
+	}
+	return out
+}
+
+// Similarity is the share of trigrams a and b have in common, from 0 to 1,
+// like pg_trgm's similarity.
+func Similarity(a, b string) float64 {
+	ta, tb := Trigrams(a), Trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// Score is how well query matches text: the better of their similarity
+// and the best similarity of query to a run of consecutive words of text.
+// The latter approximates pg_trgm's word_similarity, so that "plus" matches
+// "Yandex Plus" fully.
+func Score(query, text string) float64 {
+	best := Similarity(query, text)
+	ws := words(text)
+	for i := range ws {
+		for j := i + 1; j <= len(ws); j++ {
+			best = max(best, Similarity(query, strings.Join(ws[i:j], " ")))
+		}
+	}
+	return best
+}
+
+// Rank is Search for backends without trigram indexes: it scores subs,
+// which are in List order, and keeps those reaching Threshold, best first,
+// at most limit of them unless limit is negative.
+func Rank(subs []*domain.Subscription, query string, limit int) []repository.SearchHit {
+	hits := []repository.SearchHit{}
+	for _, sub := range subs {
+		if score := Score(query, sub.ServiceName); score >= Threshold {
+			hits = append(hits, repository.SearchHit{Subscription: sub, Score: score})
+		}
+	}
+	slices.SortStableFunc(hits, func(a, b repository.SearchHit) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	if limit >= 0 && limit < len(hits) {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// Highlight returns text HTML-escaped, with the words query matches
+// wrapped in <em>: words containing a query word, or similar to one.
+func Highlight(text, query string) string {
+	qs := words(query)
+	var b strings.Builder
+	for _, tok := range tokens(text) {
+		if tok.word && matchesAny(tok.s, qs) {
+			b.WriteString("<em>" + html.EscapeString(tok.s) + "</em>")
+		} else {
+			b.WriteString(html.EscapeString(tok.s))
+		}
+	}
+	return b.String()
+}
+
+func matchesAny(word string, queryWords []string) bool {
+	lw := strings.ToLower(word)
+	for _, q := range queryWords {
+		if strings.Contains(lw, strings.ToLower(q)) || Similarity(q, word) >= Threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// words splits s on anything but letters and digits, as pg_trgm does.
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return !isWordRune(r) })
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+type token struct {
+	s    string
+	word bool
+}
+
+// tokens splits s into words and the runs between them, keeping every
+// character.
+func tokens(s string) []token {
+	var out []token
+	for s != "" {
+		r, _ := utf8.DecodeRuneInString(s)
+		word := isWordRune(r)
+		i := strings.IndexFunc(s, func(r rune) bool { return isWordRune(r) != word })
+		if i < 0 {
+			i = len(s)
+		}
+		out = append(out, token{s: s[:i], word: word})
+		s = s[i:]
+	}
+	return out
+}
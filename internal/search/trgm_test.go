@@ -0,0 +1,55 @@
+package search
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimilarity_MatchesPgTrgm(t *testing.T) {
+	// from the pg_trgm documentation: similarity('word', 'two words')
+	if got := Similarity("word", "two words"); math.Abs(got-4.0/11) > 1e-9 {
+		t.Fatalf("similarity = %v, want 4/11", got)
+	}
+	if got := Similarity("Netflix", "NETFLIX"); got != 1 {
+		t.Fatalf("similarity ignores case, got %v", got)
+	}
+	if got := Similarity("", "Netflix"); got != 0 {
+		t.Fatalf("similarity to nothing = %v, want 0", got)
+	}
+}
+
+func TestScore(t *testing.T) {
+	cases := []struct {
+		query, text string
+		match       bool
+	}{
+		{"yandx", "Yandex Plus", true},
+		{"plus", "Yandex Plus", true},
+		{"yandex plus", "Yandex Plus", true},
+		{"spotfy", "Spotify Premium", true},
+		{"yandx", "Netflix", false},
+		{"premium", "Yandex Plus", false},
+	}
+	for _, c := range cases {
+		if got := Score(c.query, c.text) >= Threshold; got != c.match {
+			t.Errorf("Score(%q, %q) = %v, match %v, want %v", c.query, c.text, Score(c.query, c.text), got, c.match)
+		}
+	}
+	if Score("plus", "Yandex Plus") != 1 {
+		t.Errorf("a query equal to a word must score 1")
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	cases := []struct{ text, query, want string }{
+		{"Yandex Plus", "yandx", "<em>Yandex</em> Plus"},
+		{"Yandex Plus & <Kids>", "yandx kids", "<em>Yandex</em> Plus &amp; &lt;<em>Kids</em>&gt;"},
+		{"YouTube Premium", "tube", "<em>YouTube</em> Premium"},
+		{"Netflix", "spotify", "Netflix"},
+	}
+	for _, c := range cases {
+		if got := Highlight(c.text, c.query); got != c.want {
+			t.Errorf("Highlight(%q, %q) = %q, want %q", c.text, c.query, got, c.want)
+		}
+	}
+}
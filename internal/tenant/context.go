@@ -0,0 +1,34 @@
+package tenant
+
+import (
+	"context"
+	"subcalc/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// DefaultOrgID is the organization that pre-tenancy data was migrated into.
+// It is also used for callers that resolved no tenant (internal jobs).
+var DefaultOrgID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+type ctxKeyType struct{}
+
+var orgCtxKey = ctxKeyType{}
+
+func WithOrganization(ctx context.Context, org *domain.Organization) context.Context {
+	return context.WithValue(ctx, orgCtxKey, org)
+}
+
+// Organization returns the tenant resolved for the request, if any.
+func Organization(ctx context.Context) (*domain.Organization, bool) {
+	org, ok := ctx.Value(orgCtxKey).(*domain.Organization)
+	return org, ok && org != nil
+}
+
+// OrgID returns the id every tenant-owned query must be scoped by.
+func OrgID(ctx context.Context) uuid.UUID {
+	if org, ok := Organization(ctx); ok {
+		return org.ID
+	}
+	return DefaultOrgID
+}
@@ -0,0 +1,241 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"subcalc/internal/billing"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"subcalc/internal/usecase"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "subcalc"
+
+func start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// end ends span, failed unless err is nil or a lookup that found nothing.
+func end(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func filterAttrs(f repository.SubscriptionFilter) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.Int("filter.limit", f.Limit),
+		attribute.Int("filter.offset", f.Offset),
+	}
+	if f.UserID != nil {
+		attrs = append(attrs, attribute.String("filter.user_id", f.UserID.String()))
+	}
+	if f.ServiceName != nil {
+		attrs = append(attrs, attribute.String("filter.service_name", *f.ServiceName))
+	}
+	if f.From != nil {
+		attrs = append(attrs, attribute.String("filter.from", f.From.Format("01-2006")))
+	}
+	if f.To != nil {
+		attrs = append(attrs, attribute.String("filter.to", f.To.Format("01-2006")))
+	}
+	return attrs
+}
+
+type tracedUsecase struct {
+	next usecase.SubscriptionUsecase
+}
+
+// TraceSubscriptionUsecase starts a span around every usecase call.
+func TraceSubscriptionUsecase(next usecase.SubscriptionUsecase) usecase.SubscriptionUsecase {
+	return &tracedUsecase{next: next}
+}
+
+func (u *tracedUsecase) Create(ctx context.Context, sub *domain.Subscription) error {
+	ctx, span := start(ctx, "SubscriptionUsecase.Create")
+	err := u.next.Create(ctx, sub)
+	end(span, err)
+	return err
+}
+
+func (u *tracedUsecase) GetByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	ctx, span := start(ctx, "SubscriptionUsecase.GetByID", attribute.String("subscription.id", id.String()))
+	sub, err := u.next.GetByID(ctx, id)
+	end(span, err)
+	return sub, err
+}
+
+func (u *tracedUsecase) Update(ctx context.Context, sub *domain.Subscription) error {
+	ctx, span := start(ctx, "SubscriptionUsecase.Update", attribute.String("subscription.id", sub.ID.String()))
+	err := u.next.Update(ctx, sub)
+	end(span, err)
+	return err
+}
+
+func (u *tracedUsecase) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := start(ctx, "SubscriptionUsecase.Delete", attribute.String("subscription.id", id.String()))
+	err := u.next.Delete(ctx, id)
+	end(span, err)
+	return err
+}
+
+func (u *tracedUsecase) BulkDelete(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	ctx, span := start(ctx, "SubscriptionUsecase.BulkDelete", attribute.Int("ids.count", len(ids)))
+	n, err := u.next.BulkDelete(ctx, ids)
+	end(span, err)
+	return n, err
+}
+
+func (u *tracedUsecase) PurgeUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	ctx, span := start(ctx, "SubscriptionUsecase.PurgeUser", attribute.String("user_id", userID.String()))
+	n, err := u.next.PurgeUser(ctx, userID)
+	end(span, err)
+	return n, err
+}
+
+func (u *tracedUsecase) List(ctx context.Context, filter repository.SubscriptionFilter) ([]*domain.Subscription, error) {
+	ctx, span := start(ctx, "SubscriptionUsecase.List", filterAttrs(filter)...)
+	subs, err := u.next.List(ctx, filter)
+	end(span, err)
+	return subs, err
+}
+
+func (u *tracedUsecase) SumSubscriptions(ctx context.Context, filter repository.SubscriptionFilter) (int64, error) {
+	ctx, span := start(ctx, "SubscriptionUsecase.SumSubscriptions", filterAttrs(filter)...)
+	total, err := u.next.SumSubscriptions(ctx, filter)
+	end(span, err)
+	return total, err
+}
+
+func (u *tracedUsecase) Count(ctx context.Context, filter repository.SubscriptionFilter) (int64, error) {
+	ctx, span := start(ctx, "SubscriptionUsecase.Count", filterAttrs(filter)...)
+	n, err := u.next.Count(ctx, filter)
+	end(span, err)
+	return n, err
+}
+
+func (u *tracedUsecase) Search(ctx context.Context, query string, filter repository.SubscriptionFilter) ([]repository.SearchHit, error) {
+	ctx, span := start(ctx, "SubscriptionUsecase.Search", filterAttrs(filter)...)
+	hits, err := u.next.Search(ctx, query, filter)
+	if err == nil {
+		span.SetAttributes(attribute.Int("search.hits", len(hits)))
+	}
+	end(span, err)
+	return hits, err
+}
+
+func (u *tracedUsecase) Breakdown(ctx context.Context, filter repository.SubscriptionFilter) (*billing.Breakdown, error) {
+	ctx, span := start(ctx, "SubscriptionUsecase.Breakdown", filterAttrs(filter)...)
+	b, err := u.next.Breakdown(ctx, filter)
+	end(span, err)
+	return b, err
+}
+
+func (u *tracedUsecase) Forecast(ctx context.Context, filter repository.SubscriptionFilter, months int) (*billing.Breakdown, error) {
+	ctx, span := start(ctx, "SubscriptionUsecase.Forecast", append(filterAttrs(filter), attribute.Int("forecast.months", months))...)
+	b, err := u.next.Forecast(ctx, filter, months)
+	end(span, err)
+	return b, err
+}
+
+type tracedRepo struct {
+	next repository.SubscriptionRepository
+}
+
+// TraceSubscriptionRepo starts a client span around every repository query.
+// Implementations may add attributes such as db.statement to the span found
+// in their context.
+func TraceSubscriptionRepo(next repository.SubscriptionRepository) repository.SubscriptionRepository {
+	return &tracedRepo{next: next}
+}
+
+func startRepo(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("db.system", "postgresql"), attribute.String("db.operation", method))
+	return otel.Tracer(instrumentationName).Start(ctx, "SubscriptionRepository."+method,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+func (r *tracedRepo) Create(ctx context.Context, sub *domain.Subscription) error {
+	ctx, span := startRepo(ctx, "Create")
+	err := r.next.Create(ctx, sub)
+	end(span, err)
+	return err
+}
+
+func (r *tracedRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	ctx, span := startRepo(ctx, "GetByID", attribute.String("subscription.id", id.String()))
+	sub, err := r.next.GetByID(ctx, id)
+	end(span, err)
+	return sub, err
+}
+
+func (r *tracedRepo) Update(ctx context.Context, sub *domain.Subscription) error {
+	ctx, span := startRepo(ctx, "Update", attribute.String("subscription.id", sub.ID.String()))
+	err := r.next.Update(ctx, sub)
+	end(span, err)
+	return err
+}
+
+func (r *tracedRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := startRepo(ctx, "Delete", attribute.String("subscription.id", id.String()))
+	err := r.next.Delete(ctx, id)
+	end(span, err)
+	return err
+}
+
+func (r *tracedRepo) DeleteMany(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	ctx, span := startRepo(ctx, "DeleteMany", attribute.Int("ids.count", len(ids)))
+	n, err := r.next.DeleteMany(ctx, ids)
+	end(span, err)
+	return n, err
+}
+
+func (r *tracedRepo) DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	ctx, span := startRepo(ctx, "DeleteByUser", attribute.String("user_id", userID.String()))
+	n, err := r.next.DeleteByUser(ctx, userID)
+	end(span, err)
+	return n, err
+}
+
+func (r *tracedRepo) List(ctx context.Context, filter repository.SubscriptionFilter) ([]*domain.Subscription, error) {
+	ctx, span := startRepo(ctx, "List", filterAttrs(filter)...)
+	subs, err := r.next.List(ctx, filter)
+	end(span, err)
+	return subs, err
+}
+
+func (r *tracedRepo) FindForPeriod(ctx context.Context, filter repository.SubscriptionFilter) ([]*domain.Subscription, error) {
+	ctx, span := startRepo(ctx, "FindForPeriod", filterAttrs(filter)...)
+	subs, err := r.next.FindForPeriod(ctx, filter)
+	end(span, err)
+	return subs, err
+}
+
+func (r *tracedRepo) SumForPeriod(ctx context.Context, filter repository.SubscriptionFilter) (int64, error) {
+	ctx, span := startRepo(ctx, "SumForPeriod", filterAttrs(filter)...)
+	total, err := r.next.SumForPeriod(ctx, filter)
+	end(span, err)
+	return total, err
+}
+
+func (r *tracedRepo) Count(ctx context.Context, filter repository.SubscriptionFilter) (int64, error) {
+	ctx, span := startRepo(ctx, "Count", filterAttrs(filter)...)
+	n, err := r.next.Count(ctx, filter)
+	end(span, err)
+	return n, err
+}
+
+func (r *tracedRepo) Search(ctx context.Context, query string, filter repository.SubscriptionFilter) ([]repository.SearchHit, error) {
+	ctx, span := startRepo(ctx, "Search", filterAttrs(filter)...)
+	hits, err := r.next.Search(ctx, query, filter)
+	end(span, err)
+	return hits, err
+}
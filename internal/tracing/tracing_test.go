@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"context"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"subcalc/internal/usecase"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type nopRepo struct {
+	repository.SubscriptionRepository
+}
+
+func (nopRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	return &domain.Subscription{ID: id}, nil
+}
+
+func TestSetup_Exporters(t *testing.T) {
+	for _, exp := range []string{ExporterNone, ExporterStdout} {
+		shutdown, err := Setup(context.Background(), exp, 1)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", exp, err)
+		}
+		if err := shutdown(context.Background()); err != nil {
+			t.Fatalf("%s: shutdown: %v", exp, err)
+		}
+	}
+	if _, err := Setup(context.Background(), "jaeger", 1); err == nil {
+		t.Fatalf("expected error for unknown exporter")
+	}
+}
+
+func TestDecorators_NestUsecaseAndRepoSpans(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	defer otel.SetTracerProvider(prev)
+
+	uc := TraceSubscriptionUsecase(usecase.NewSubscriptionUsecase(TraceSubscriptionRepo(nopRepo{})))
+	if _, err := uc.GetByID(context.Background(), uuid.New()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	repoSpan, ucSpan := spans[0], spans[1]
+	if repoSpan.Name() != "SubscriptionRepository.GetByID" || ucSpan.Name() != "SubscriptionUsecase.GetByID" {
+		t.Fatalf("unexpected span names %q, %q", repoSpan.Name(), ucSpan.Name())
+	}
+	if repoSpan.Parent().SpanID() != ucSpan.SpanContext().SpanID() {
+		t.Fatalf("repository span must be a child of the usecase span")
+	}
+}
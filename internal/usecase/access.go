@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"subcalc/internal/auth"
+	"subcalc/internal/repository"
+)
+
+// requirePermission allows principals holding perm and callers without a
+// principal (auth disabled, internal callers).
+func requirePermission(ctx context.Context, perm auth.Permission) error {
+	if p, ok := auth.FromContext(ctx); ok && !p.Can(perm) {
+		return fmt.Errorf("%w: requires the %s permission", ErrForbidden, perm)
+	}
+	return nil
+}
+
+// scopeFilter forces principals bound to a single user onto their own data
+// regardless of the user_id they asked for.
+func scopeFilter(ctx context.Context, filter repository.SubscriptionFilter) repository.SubscriptionFilter {
+	p, ok := auth.FromContext(ctx)
+	if !ok || p.SeesAllUsers() {
+		return filter
+	}
+	uid := p.UserID
+	filter.UserID = &uid
+	return filter
+}
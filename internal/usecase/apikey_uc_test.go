@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"subcalc/internal/auth"
+	"subcalc/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type fakeKeyRepo struct {
+	byHash  map[string]*domain.APIKey
+	touched int
+}
+
+func newFakeKeyRepo() *fakeKeyRepo {
+	return &fakeKeyRepo{byHash: map[string]*domain.APIKey{}}
+}
+
+func (f *fakeKeyRepo) Create(ctx context.Context, key *domain.APIKey) error {
+	key.ID = uuid.New()
+	f.byHash[key.Hash] = key
+	return nil
+}
+func (f *fakeKeyRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	for _, k := range f.byHash {
+		if k.ID == id {
+			return k, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+func (f *fakeKeyRepo) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	k, ok := f.byHash[hash]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return k, nil
+}
+func (f *fakeKeyRepo) List(ctx context.Context) ([]*domain.APIKey, error) {
+	return nil, nil
+}
+func (f *fakeKeyRepo) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	k, _ := f.GetByID(ctx, id)
+	k.RevokedAt = &at
+	return nil
+}
+func (f *fakeKeyRepo) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	f.touched++
+	k, _ := f.GetByID(ctx, id)
+	k.LastUsedAt = &at
+	return nil
+}
+
+func TestAPIKey_IssueAuthenticateRevoke(t *testing.T) {
+	repo := newFakeKeyRepo()
+	uc := NewAPIKeyUsecase(repo)
+	ctx := context.Background()
+
+	key, raw, err := uc.Issue(ctx, "billing-job", []string{string(auth.PermReportsRead)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key.Hash == raw || key.Prefix != raw[:len(key.Prefix)] {
+		t.Fatalf("key must be stored hashed with a display prefix")
+	}
+
+	p, err := uc.Authenticate(ctx, raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !p.Can(auth.PermReportsRead) || p.Can(auth.PermSubscriptionsWrite) {
+		t.Fatalf("unexpected scopes: %v", p.Scopes)
+	}
+	if _, err := uc.Authenticate(ctx, raw); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.touched != 1 {
+		t.Fatalf("expected last_used_at to be written once, got %d", repo.touched)
+	}
+
+	if err := uc.Revoke(ctx, key.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := uc.Authenticate(ctx, raw); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("expected ErrInvalidAPIKey after revoke, got %v", err)
+	}
+}
+
+func TestAPIKey_IssueRejectsUnknownScope(t *testing.T) {
+	uc := NewAPIKeyUsecase(newFakeKeyRepo())
+	if _, _, err := uc.Issue(context.Background(), "x", []string{"everything"}); !errors.Is(err, domain.ErrValidation) {
+		t.Fatalf("expected ErrValidation, got %v", err)
+	}
+}
+
+func TestAPIKey_ManagementRequiresAdmin(t *testing.T) {
+	uc := NewAPIKeyUsecase(newFakeKeyRepo())
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: uuid.New(), Role: auth.RoleEditor})
+	if _, err := uc.List(ctx); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+}
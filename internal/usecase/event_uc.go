@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"subcalc/internal/auth"
+	"subcalc/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// streamedEvents are the events a subscription stream carries.
+var streamedEvents = []domain.EventType{
+	domain.EventSubscriptionCreated,
+	domain.EventSubscriptionUpdated,
+	domain.EventSubscriptionDeleted,
+}
+
+// EventSource is where streams get their events from; events.Broker in
+// production.
+type EventSource interface {
+	Subscribe(ctx context.Context, afterID int64, match func(*domain.Event) bool) (<-chan *domain.Event, error)
+}
+
+type EventUsecase interface {
+	// Stream sends the created, updated and deleted events of the
+	// subscriptions of userIDs, or of every user when empty: first those
+	// after afterID, then new ones. The channel is closed when ctx is done
+	// or the stream has to end; clients resume with the last id they got.
+	Stream(ctx context.Context, userIDs []uuid.UUID, afterID int64) (<-chan *domain.Event, error)
+}
+
+type eventUC struct {
+	source EventSource
+}
+
+func NewEventUsecase(source EventSource) EventUsecase {
+	return &eventUC{source: source}
+}
+
+func (u *eventUC) Stream(ctx context.Context, userIDs []uuid.UUID, afterID int64) (<-chan *domain.Event, error) {
+	if err := requirePermission(ctx, auth.PermSubscriptionsRead); err != nil {
+		return nil, err
+	}
+	if p, ok := auth.FromContext(ctx); ok && !p.SeesAllUsers() {
+		userIDs = []uuid.UUID{p.UserID}
+	}
+	return u.source.Subscribe(ctx, afterID, func(e *domain.Event) bool {
+		if !slices.Contains(streamedEvents, e.Type) || e.Data.Subscription == nil {
+			return false
+		}
+		return len(userIDs) == 0 || slices.Contains(userIDs, e.Data.Subscription.UserID)
+	})
+}
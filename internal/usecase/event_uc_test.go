@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"subcalc/internal/auth"
+	"subcalc/internal/domain"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type fakeEventSource struct {
+	afterID int64
+	match   func(*domain.Event) bool
+}
+
+func (f *fakeEventSource) Subscribe(ctx context.Context, afterID int64, match func(*domain.Event) bool) (<-chan *domain.Event, error) {
+	f.afterID, f.match = afterID, match
+	return nil, nil
+}
+
+func TestEvent_StreamFiltersByUser(t *testing.T) {
+	source := &fakeEventSource{}
+	uc := NewEventUsecase(source)
+	alice, bob := uuid.New(), uuid.New()
+	event := func(typ domain.EventType, user uuid.UUID) *domain.Event {
+		return &domain.Event{Type: typ, Data: domain.EventData{Subscription: &domain.Subscription{UserID: user}}}
+	}
+
+	if _, err := uc.Stream(context.Background(), []uuid.UUID{alice}, 7); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if source.afterID != 7 {
+		t.Fatalf("expected the stream to resume after 7, got %d", source.afterID)
+	}
+	if !source.match(event(domain.EventSubscriptionDeleted, alice)) || source.match(event(domain.EventSubscriptionCreated, bob)) {
+		t.Fatalf("expected only alice's events")
+	}
+	if source.match(event(domain.EventSubscriptionPriceChanged, alice)) {
+		t.Fatalf("expected only created, updated and deleted events")
+	}
+
+	_, _ = uc.Stream(context.Background(), nil, 0)
+	if !source.match(event(domain.EventSubscriptionUpdated, bob)) {
+		t.Fatalf("expected every user's events without user_id")
+	}
+
+	viewer := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: bob, Role: auth.RoleViewer})
+	_, _ = uc.Stream(viewer, []uuid.UUID{alice}, 0)
+	if source.match(event(domain.EventSubscriptionCreated, alice)) || !source.match(event(domain.EventSubscriptionCreated, bob)) {
+		t.Fatalf("expected a viewer bound to their own events")
+	}
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"subcalc/internal/auth"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"subcalc/internal/tenant"
+)
+
+var currencyRe = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type OrganizationUsecase interface {
+	// Current returns the organization of the request's tenant.
+	Current(ctx context.Context) (*domain.Organization, error)
+	UpdateSettings(ctx context.Context, name, defaultCurrency *string) (*domain.Organization, error)
+}
+
+type orgUC struct {
+	repo repository.OrganizationRepository
+}
+
+func NewOrganizationUsecase(repo repository.OrganizationRepository) OrganizationUsecase {
+	return &orgUC{repo: repo}
+}
+
+func (u *orgUC) Current(ctx context.Context) (*domain.Organization, error) {
+	return u.repo.GetByID(ctx, tenant.OrgID(ctx))
+}
+
+func (u *orgUC) UpdateSettings(ctx context.Context, name, defaultCurrency *string) (*domain.Organization, error) {
+	if err := requirePermission(ctx, auth.PermOrgManage); err != nil {
+		return nil, err
+	}
+	org, err := u.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]string{}
+	if name != nil {
+		n := strings.TrimSpace(*name)
+		if n == "" || len(n) > 255 {
+			fields["name"] = "required, max 255 chars"
+		}
+		org.Name = n
+	}
+	if defaultCurrency != nil {
+		cur := strings.ToUpper(strings.TrimSpace(*defaultCurrency))
+		if !currencyRe.MatchString(cur) {
+			fields["default_currency"] = "expected a 3-letter ISO 4217 code"
+		}
+		org.DefaultCurrency = cur
+	}
+	if len(fields) > 0 {
+		return nil, domain.NewValidationError("invalid organization settings", fields)
+	}
+	if err := u.repo.Update(ctx, org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"subcalc/internal/auth"
+	"subcalc/internal/domain"
+	"subcalc/internal/tenant"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type fakeOrgRepo struct {
+	orgs map[uuid.UUID]*domain.Organization
+}
+
+func (f *fakeOrgRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Organization, error) {
+	org, ok := f.orgs[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return org, nil
+}
+func (f *fakeOrgRepo) Update(ctx context.Context, org *domain.Organization) error {
+	f.orgs[org.ID] = org
+	return nil
+}
+
+func TestOrg_UpdateSettingsUsesTenant(t *testing.T) {
+	other := &domain.Organization{ID: uuid.New(), Name: "hr", DefaultCurrency: "RUB"}
+	repo := &fakeOrgRepo{orgs: map[uuid.UUID]*domain.Organization{
+		tenant.DefaultOrgID: {ID: tenant.DefaultOrgID, Name: "default", DefaultCurrency: "RUB"},
+		other.ID:            other,
+	}}
+	uc := NewOrganizationUsecase(repo)
+
+	ctx := tenant.WithOrganization(context.Background(), other)
+	cur := "usd"
+	org, err := uc.UpdateSettings(ctx, nil, &cur)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if org.ID != other.ID || org.DefaultCurrency != "USD" {
+		t.Fatalf("expected tenant org with USD, got %+v", org)
+	}
+	if repo.orgs[tenant.DefaultOrgID].DefaultCurrency != "RUB" {
+		t.Fatalf("default org must not change")
+	}
+
+	bad := "rubles"
+	if _, err := uc.UpdateSettings(ctx, nil, &bad); !errors.Is(err, domain.ErrValidation) {
+		t.Fatalf("expected ErrValidation, got %v", err)
+	}
+
+	editor := auth.WithPrincipal(ctx, &auth.Principal{UserID: uuid.New(), Role: auth.RoleEditor})
+	if _, err := uc.UpdateSettings(editor, nil, &cur); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+}
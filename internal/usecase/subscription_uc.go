@@ -2,57 +2,170 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"subcalc/internal/auth"
+	"subcalc/internal/billing"
 	"subcalc/internal/domain"
 	"subcalc/internal/repository"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+var ErrForbidden = errors.New("forbidden")
+
 type SubscriptionUsecase interface {
 	Create(ctx context.Context, sub *domain.Subscription) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error)
 	Update(ctx context.Context, sub *domain.Subscription) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	BulkDelete(ctx context.Context, ids []uuid.UUID) (int64, error)
+	PurgeUser(ctx context.Context, userID uuid.UUID) (int64, error)
 	List(ctx context.Context, filter repository.SubscriptionFilter) ([]*domain.Subscription, error)
 	SumSubscriptions(ctx context.Context, filter repository.SubscriptionFilter) (int64, error)
 	Count(ctx context.Context, filter repository.SubscriptionFilter) (int64, error)
+	Breakdown(ctx context.Context, filter repository.SubscriptionFilter) (*billing.Breakdown, error)
+	Forecast(ctx context.Context, filter repository.SubscriptionFilter, months int) (*billing.Breakdown, error)
+	Search(ctx context.Context, query string, filter repository.SubscriptionFilter) ([]repository.SearchHit, error)
 }
 
 type subscriptionUC struct {
 	repo repository.SubscriptionRepository
+	now  func() time.Time
 }
 
 func NewSubscriptionUsecase(repo repository.SubscriptionRepository) SubscriptionUsecase {
-	return &subscriptionUC{repo: repo}
+	return &subscriptionUC{repo: repo, now: time.Now}
 }
 
 func (u *subscriptionUC) Create(ctx context.Context, sub *domain.Subscription) error {
+	if p, ok := auth.FromContext(ctx); ok && !p.CanAccessUser(sub.UserID) {
+		return fmt.Errorf("%w: cannot create subscriptions for another user", ErrForbidden)
+	}
 	return u.repo.Create(ctx, sub)
 }
 
+// GetByID fails with domain.ErrNotFound both for missing subscriptions and
+// for subscriptions owned by another user, so foreign ids are
+// indistinguishable from unknown ones.
 func (u *subscriptionUC) GetByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
-	return u.repo.GetByID(ctx, id)
+	sub, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := auth.FromContext(ctx); ok && !p.CanAccessUser(sub.UserID) {
+		return nil, domain.ErrNotFound
+	}
+	return sub, nil
 }
 
 func (u *subscriptionUC) Update(ctx context.Context, sub *domain.Subscription) error {
+	if err := u.checkOwner(ctx, sub.ID); err != nil {
+		return err
+	}
+	if p, ok := auth.FromContext(ctx); ok && !p.CanAccessUser(sub.UserID) {
+		return fmt.Errorf("%w: cannot move subscriptions to another user", ErrForbidden)
+	}
 	return u.repo.Update(ctx, sub)
 }
 
 func (u *subscriptionUC) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := u.checkOwner(ctx, id); err != nil {
+		return err
+	}
 	return u.repo.Delete(ctx, id)
 }
 
+func (u *subscriptionUC) BulkDelete(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	if err := requirePermission(ctx, auth.PermSubscriptionsBulkDelete); err != nil {
+		return 0, err
+	}
+	return u.repo.DeleteMany(ctx, ids)
+}
+
+// PurgeUser removes every subscription owned by userID.
+func (u *subscriptionUC) PurgeUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	if err := requirePermission(ctx, auth.PermSubscriptionsPurge); err != nil {
+		return 0, err
+	}
+	return u.repo.DeleteByUser(ctx, userID)
+}
+
 func (u *subscriptionUC) List(ctx context.Context, filter repository.SubscriptionFilter) ([]*domain.Subscription, error) {
-	return u.repo.List(ctx, filter)
+	return u.repo.List(ctx, scopeFilter(ctx, filter))
 }
 
 func (u *subscriptionUC) SumSubscriptions(ctx context.Context, filter repository.SubscriptionFilter) (int64, error) {
 	if filter.From == nil || filter.To == nil {
 		return 0, nil
 	}
-	return u.repo.SumForPeriod(ctx, filter)
+	return u.repo.SumForPeriod(ctx, scopeFilter(ctx, filter))
 }
 
 func (u *subscriptionUC) Count(ctx context.Context, filter repository.SubscriptionFilter) (int64, error) {
-	return u.repo.Count(ctx, filter)
+	return u.repo.Count(ctx, scopeFilter(ctx, filter))
+}
+
+func (u *subscriptionUC) Search(ctx context.Context, query string, filter repository.SubscriptionFilter) ([]repository.SearchHit, error) {
+	return u.repo.Search(ctx, query, scopeFilter(ctx, filter))
+}
+
+// Breakdown splits the total SumSubscriptions reports by month and service.
+func (u *subscriptionUC) Breakdown(ctx context.Context, filter repository.SubscriptionFilter) (*billing.Breakdown, error) {
+	if filter.From == nil || filter.To == nil {
+		return &billing.Breakdown{}, nil
+	}
+	subs, err := u.findAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	b := billing.Compute(subs, *filter.From, *filter.To)
+	return &b, nil
+}
+
+// Forecast projects the charges of the next months, starting with the month
+// of filter.From or the current month.
+func (u *subscriptionUC) Forecast(ctx context.Context, filter repository.SubscriptionFilter, months int) (*billing.Breakdown, error) {
+	from := billing.Month(u.now())
+	if filter.From != nil {
+		from = billing.Month(*filter.From)
+	}
+	if months < 1 {
+		b := billing.Forecast(nil, from, 0)
+		return &b, nil
+	}
+	to := from.AddDate(0, months-1, 0)
+	filter.From, filter.To = &from, &to
+
+	subs, err := u.findAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	b := billing.Forecast(subs, from, months)
+	return &b, nil
+}
+
+// findAll returns every visible subscription overlapping the filter period.
+func (u *subscriptionUC) findAll(ctx context.Context, filter repository.SubscriptionFilter) ([]*domain.Subscription, error) {
+	filter.Limit, filter.Offset = -1, 0
+	return u.repo.FindForPeriod(ctx, scopeFilter(ctx, filter))
+}
+
+// checkOwner returns domain.ErrNotFound when the subscription does not
+// exist or is not visible to the caller. Principals that see all users and
+// callers without a principal are not checked.
+func (u *subscriptionUC) checkOwner(ctx context.Context, id uuid.UUID) error {
+	p, ok := auth.FromContext(ctx)
+	if !ok || p.SeesAllUsers() {
+		return nil
+	}
+	existing, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !p.CanAccessUser(existing.UserID) {
+		return domain.ErrNotFound
+	}
+	return nil
 }
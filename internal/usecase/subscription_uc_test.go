@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"errors"
+	"subcalc/internal/auth"
 	"subcalc/internal/domain"
 	"subcalc/internal/repository"
 	"testing"
@@ -15,25 +16,44 @@ type fakeRepo struct {
 	sumReturn int64
 	sumErr    error
 
+	getReturn *domain.Subscription
+
+	created    bool
+	deleted    bool
 	lastFilter repository.SubscriptionFilter
 }
 
 func (f *fakeRepo) Count(ctx context.Context, filter repository.SubscriptionFilter) (int64, error) {
+	f.lastFilter = filter
 	return 0, nil
 }
 func (f *fakeRepo) Create(ctx context.Context, sub *domain.Subscription) error {
+	f.created = true
 	return nil
 }
 func (f *fakeRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
-	return nil, nil
+	if f.getReturn == nil {
+		return nil, domain.ErrNotFound
+	}
+	return f.getReturn, nil
 }
 func (f *fakeRepo) Update(ctx context.Context, sub *domain.Subscription) error {
 	return nil
 }
 func (f *fakeRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	f.deleted = true
 	return nil
 }
+func (f *fakeRepo) DeleteMany(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	f.deleted = true
+	return int64(len(ids)), nil
+}
+func (f *fakeRepo) DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	f.deleted = true
+	return 0, nil
+}
 func (f *fakeRepo) List(ctx context.Context, filter repository.SubscriptionFilter) ([]*domain.Subscription, error) {
+	f.lastFilter = filter
 	return nil, nil
 }
 func (f *fakeRepo) FindForPeriod(ctx context.Context, filter repository.SubscriptionFilter) ([]*domain.Subscription, error) {
@@ -43,6 +63,10 @@ func (f *fakeRepo) SumForPeriod(ctx context.Context, filter repository.Subscript
 	f.lastFilter = filter
 	return f.sumReturn, f.sumErr
 }
+func (f *fakeRepo) Search(ctx context.Context, query string, filter repository.SubscriptionFilter) ([]repository.SearchHit, error) {
+	f.lastFilter = filter
+	return nil, nil
+}
 
 func TestSumSubscriptions_NoPeriod_ReturnsZero(t *testing.T) {
 	fr := &fakeRepo{sumReturn: 12345}
@@ -111,3 +135,99 @@ func TestSumSubscriptions_RepoError_Propagates(t *testing.T) {
 		t.Fatalf("expected total 0 on error, got %d", total)
 	}
 }
+
+func TestIsolation_UserFilterForcedToPrincipal(t *testing.T) {
+	fr := &fakeRepo{}
+	uc := NewSubscriptionUsecase(fr)
+
+	me := uuid.New()
+	other := uuid.New()
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: me, Role: auth.RoleEditor})
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := uc.SumSubscriptions(ctx, repository.SubscriptionFilter{UserID: &other, From: &from, To: &to}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fr.lastFilter.UserID == nil || *fr.lastFilter.UserID != me {
+		t.Fatalf("sum filter not scoped to principal")
+	}
+
+	if _, err := uc.List(ctx, repository.SubscriptionFilter{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fr.lastFilter.UserID == nil || *fr.lastFilter.UserID != me {
+		t.Fatalf("list filter not scoped to principal")
+	}
+}
+
+func TestIsolation_AdminKeepsFilter(t *testing.T) {
+	fr := &fakeRepo{}
+	uc := NewSubscriptionUsecase(fr)
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: uuid.New(), Role: auth.RoleAdmin})
+	if _, err := uc.Count(ctx, repository.SubscriptionFilter{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fr.lastFilter.UserID != nil {
+		t.Fatalf("admin filter must not be scoped")
+	}
+}
+
+func TestIsolation_CreateForOtherUserForbidden(t *testing.T) {
+	fr := &fakeRepo{}
+	uc := NewSubscriptionUsecase(fr)
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: uuid.New(), Role: auth.RoleEditor})
+	err := uc.Create(ctx, &domain.Subscription{UserID: uuid.New()})
+	if !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	if fr.created {
+		t.Fatalf("repo.Create must not be called")
+	}
+}
+
+func TestIsolation_ForeignIDLooksMissing(t *testing.T) {
+	foreign := &domain.Subscription{ID: uuid.New(), UserID: uuid.New()}
+	fr := &fakeRepo{getReturn: foreign}
+	uc := NewSubscriptionUsecase(fr)
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: uuid.New(), Role: auth.RoleEditor})
+
+	sub, err := uc.GetByID(ctx, foreign.ID)
+	if !errors.Is(err, domain.ErrNotFound) || sub != nil {
+		t.Fatalf("expected ErrNotFound for foreign id, got %v, %v", sub, err)
+	}
+	if err := uc.Delete(ctx, foreign.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound on delete, got %v", err)
+	}
+	if fr.deleted {
+		t.Fatalf("repo.Delete must not be called")
+	}
+	if err := uc.Update(ctx, foreign); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound on update, got %v", err)
+	}
+}
+
+func TestRBAC_BulkOperationsRequireAdmin(t *testing.T) {
+	fr := &fakeRepo{}
+	uc := NewSubscriptionUsecase(fr)
+
+	editor := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: uuid.New(), Role: auth.RoleEditor})
+	if _, err := uc.BulkDelete(editor, []uuid.UUID{uuid.New()}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for bulk delete, got %v", err)
+	}
+	if _, err := uc.PurgeUser(editor, uuid.New()); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for purge, got %v", err)
+	}
+	if fr.deleted {
+		t.Fatalf("repo must not be called")
+	}
+
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: uuid.New(), Role: auth.RoleAdmin})
+	n, err := uc.BulkDelete(admin, []uuid.UUID{uuid.New(), uuid.New()})
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 deleted, got %d, %v", n, err)
+	}
+}
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"subcalc/internal/auth"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"subcalc/internal/webhook"
+
+	"github.com/google/uuid"
+)
+
+const (
+	webhookSecretPrefix = "whsec_"
+	// deliveries listed when the caller sets no limit, and at most
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 200
+)
+
+type WebhookUsecase interface {
+	// Create registers a webhook and returns it together with its signing
+	// secret, which is only ever returned here.
+	Create(ctx context.Context, rawURL string, events []string, active bool) (*domain.Webhook, string, error)
+	List(ctx context.Context) ([]*domain.Webhook, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.Webhook, error)
+	Update(ctx context.Context, id uuid.UUID, rawURL string, events []string, active bool) (*domain.Webhook, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Deliveries lists the latest deliveries of a webhook, newest first.
+	Deliveries(ctx context.Context, id uuid.UUID, limit int) ([]*domain.WebhookDelivery, error)
+}
+
+type webhookUC struct {
+	repo repository.WebhookRepository
+}
+
+func NewWebhookUsecase(repo repository.WebhookRepository) WebhookUsecase {
+	return &webhookUC{repo: repo}
+}
+
+func (u *webhookUC) Create(ctx context.Context, rawURL string, events []string, active bool) (*domain.Webhook, string, error) {
+	if err := requirePermission(ctx, auth.PermWebhooksManage); err != nil {
+		return nil, "", err
+	}
+	hook := &domain.Webhook{Active: active}
+	if err := setWebhookFields(hook, rawURL, events); err != nil {
+		return nil, "", err
+	}
+
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", err
+	}
+	hook.Secret = webhookSecretPrefix + hex.EncodeToString(buf)
+	if err := u.repo.Create(ctx, hook); err != nil {
+		return nil, "", err
+	}
+	return hook, hook.Secret, nil
+}
+
+func (u *webhookUC) List(ctx context.Context) ([]*domain.Webhook, error) {
+	if err := requirePermission(ctx, auth.PermWebhooksManage); err != nil {
+		return nil, err
+	}
+	return u.repo.List(ctx)
+}
+
+func (u *webhookUC) Get(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	if err := requirePermission(ctx, auth.PermWebhooksManage); err != nil {
+		return nil, err
+	}
+	return u.repo.GetByID(ctx, id)
+}
+
+func (u *webhookUC) Update(ctx context.Context, id uuid.UUID, rawURL string, events []string, active bool) (*domain.Webhook, error) {
+	hook, err := u.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := setWebhookFields(hook, rawURL, events); err != nil {
+		return nil, err
+	}
+	hook.Active = active
+	if err := u.repo.Update(ctx, hook); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+func (u *webhookUC) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := requirePermission(ctx, auth.PermWebhooksManage); err != nil {
+		return err
+	}
+	return u.repo.Delete(ctx, id)
+}
+
+func (u *webhookUC) Deliveries(ctx context.Context, id uuid.UUID, limit int) ([]*domain.WebhookDelivery, error) {
+	if _, err := u.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultDeliveryLimit
+	}
+	return u.repo.ListDeliveries(ctx, id, min(limit, maxDeliveryLimit))
+}
+
+// setWebhookFields validates the endpoint and event types and sets them on
+// hook. Repeated event types are kept once. Endpoints on loopback, private
+// or link-local addresses are refused.
+func setWebhookFields(hook *domain.Webhook, rawURL string, events []string) error {
+	fields := map[string]string{}
+	rawURL = strings.TrimSpace(rawURL)
+	if u, err := url.Parse(rawURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		fields["url"] = "expected an absolute http or https URL"
+	} else if !webhook.PublicHost(u.Hostname()) {
+		// the worker checks resolved names again when it connects
+		fields["url"] = "must not point to a local or private address"
+	}
+
+	var types []domain.EventType
+	for _, e := range events {
+		t := domain.EventType(e)
+		if !t.Valid() {
+			fields["events"] = fmt.Sprintf("unknown event type %q", e)
+			break
+		}
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	if len(events) == 0 {
+		fields["events"] = "at least one event type required"
+	}
+	if len(fields) > 0 {
+		return domain.NewValidationError("invalid webhook", fields)
+	}
+	hook.URL = rawURL
+	hook.Events = types
+	return nil
+}
@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"subcalc/internal/auth"
+	"subcalc/internal/domain"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type fakeWebhookRepo struct {
+	hooks      map[uuid.UUID]*domain.Webhook
+	listLimits []int
+}
+
+func (f *fakeWebhookRepo) Create(ctx context.Context, hook *domain.Webhook) error {
+	hook.ID = uuid.New()
+	f.hooks[hook.ID] = hook
+	return nil
+}
+func (f *fakeWebhookRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	hook, ok := f.hooks[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	c := *hook
+	return &c, nil
+}
+func (f *fakeWebhookRepo) List(ctx context.Context) ([]*domain.Webhook, error) {
+	return nil, nil
+}
+func (f *fakeWebhookRepo) Update(ctx context.Context, hook *domain.Webhook) error {
+	f.hooks[hook.ID] = hook
+	return nil
+}
+func (f *fakeWebhookRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(f.hooks, id)
+	return nil
+}
+func (f *fakeWebhookRepo) ListDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]*domain.WebhookDelivery, error) {
+	f.listLimits = append(f.listLimits, limit)
+	return nil, nil
+}
+
+func TestWebhook_CreateValidatesAndIssuesSecret(t *testing.T) {
+	repo := &fakeWebhookRepo{hooks: map[uuid.UUID]*domain.Webhook{}}
+	uc := NewWebhookUsecase(repo)
+	ctx := context.Background()
+
+	hook, secret, err := uc.Create(ctx, " https://example.com/hook ", []string{"subscription.created", "subscription.created", "subscription.deleted"}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(secret, webhookSecretPrefix) || hook.Secret != secret {
+		t.Fatalf("expected a stored whsec_ secret, got %q", secret)
+	}
+	if hook.URL != "https://example.com/hook" || !slices.Equal(hook.Events, []domain.EventType{domain.EventSubscriptionCreated, domain.EventSubscriptionDeleted}) {
+		t.Fatalf("unexpected webhook %+v", hook)
+	}
+	if _, other, _ := uc.Create(ctx, "https://example.com/hook", []string{"subscription.created"}, true); other == secret {
+		t.Fatalf("secrets must differ between webhooks")
+	}
+
+	for _, tc := range []struct {
+		url    string
+		events []string
+		field  string
+	}{
+		{"ftp://example.com", []string{"subscription.created"}, "url"},
+		{"/relative", []string{"subscription.created"}, "url"},
+		{"http://localhost:8080/hook", []string{"subscription.created"}, "url"},
+		{"http://127.0.0.1/hook", []string{"subscription.created"}, "url"},
+		{"http://10.0.0.5/hook", []string{"subscription.created"}, "url"},
+		{"http://169.254.169.254/latest/meta-data", []string{"subscription.created"}, "url"},
+		{"http://[::1]:9000/hook", []string{"subscription.created"}, "url"},
+		{"http://[::ffff:192.168.0.1]/hook", []string{"subscription.created"}, "url"},
+		{"https://example.com", nil, "events"},
+		{"https://example.com", []string{"subscription.renamed"}, "events"},
+	} {
+		_, _, err := uc.Create(ctx, tc.url, tc.events, true)
+		var verr *domain.ValidationError
+		if !errors.As(err, &verr) || verr.Fields[tc.field] == "" {
+			t.Errorf("create(%q, %v): expected a validation error on %s, got %v", tc.url, tc.events, tc.field, err)
+		}
+	}
+}
+
+func TestWebhook_UpdateAndDeliveries(t *testing.T) {
+	repo := &fakeWebhookRepo{hooks: map[uuid.UUID]*domain.Webhook{}}
+	uc := NewWebhookUsecase(repo)
+	ctx := context.Background()
+
+	hook, secret, _ := uc.Create(ctx, "https://example.com/a", []string{"subscription.created"}, true)
+	updated, err := uc.Update(ctx, hook.ID, "https://example.com/b", []string{"subscription.price_changed"}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.URL != "https://example.com/b" || updated.Active || updated.Secret != secret ||
+		!slices.Equal(updated.Events, []domain.EventType{domain.EventSubscriptionPriceChanged}) {
+		t.Fatalf("unexpected webhook %+v", updated)
+	}
+	if _, err := uc.Update(ctx, uuid.New(), "https://example.com/b", []string{"subscription.created"}, true); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	for _, limit := range []int{0, 10, 1000} {
+		if _, err := uc.Deliveries(ctx, hook.ID, limit); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if !slices.Equal(repo.listLimits, []int{defaultDeliveryLimit, 10, maxDeliveryLimit}) {
+		t.Fatalf("expected limits defaulted and capped, got %v", repo.listLimits)
+	}
+	if _, err := uc.Deliveries(ctx, uuid.New(), 0); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestWebhook_RequiresPermission(t *testing.T) {
+	repo := &fakeWebhookRepo{hooks: map[uuid.UUID]*domain.Webhook{}}
+	uc := NewWebhookUsecase(repo)
+	editor := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: uuid.New(), Role: auth.RoleEditor})
+
+	if _, _, err := uc.Create(editor, "https://example.com", []string{"subscription.created"}, true); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	if _, err := uc.List(editor); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: uuid.New(), Role: auth.RoleAdmin})
+	if _, _, err := uc.Create(admin, "https://example.com", []string{"subscription.created"}, true); err != nil {
+		t.Fatalf("admin: unexpected error: %v", err)
+	}
+}
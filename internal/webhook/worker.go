@@ -0,0 +1,200 @@
+// Package webhook delivers the events of the outbox to the webhooks that
+// want them: signed POST requests, retried with exponential backoff until
+// they succeed or run out of attempts.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	EventHeader    = "X-Webhook-Event"
+	DeliveryHeader = "X-Webhook-Delivery"
+
+	userAgent = "subcalc-webhooks/1.0"
+	// maxResponseBody bounds the part of a response kept for inspection.
+	maxResponseBody = 1024
+	// pruneInterval is how often RunOnce deletes what Retention expired.
+	pruneInterval = time.Hour
+)
+
+type Options struct {
+	// Interval is the pause between polls of the outbox.
+	Interval time.Duration
+	// BatchSize bounds the events dispatched and deliveries attempted at
+	// once.
+	BatchSize int
+	// MaxAttempts is how often a delivery is tried before it fails.
+	MaxAttempts int
+	// The wait before retry n is BackoffBase * 2^(n-1), at most BackoffMax.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// Timeout bounds each request.
+	Timeout time.Duration
+	// Retention is how long dispatched events and finished deliveries are
+	// kept.
+	Retention time.Duration
+}
+
+// Worker moves events from the outbox to the webhooks. Several workers,
+// in one process or many, may share an outbox: each delivery is claimed
+// by one of them at a time.
+type Worker struct {
+	outbox    repository.OutboxRepository
+	opts      Options
+	client    *http.Client
+	log       *zap.SugaredLogger
+	now       func() time.Time
+	lastPrune time.Time
+}
+
+func NewWorker(outbox repository.OutboxRepository, opts Options, log *zap.SugaredLogger) *Worker {
+	return &Worker{
+		outbox: outbox,
+		opts:   opts,
+		client: newClient(opts.Timeout),
+		log:    log,
+		now:    func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run polls the outbox until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		if err := w.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			w.log.Errorf("webhooks: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce dispatches the new events, attempts the deliveries that are due
+// and, once in a while, prunes the outbox.
+func (w *Worker) RunOnce(ctx context.Context) error {
+	for {
+		n, err := w.outbox.DispatchEvents(ctx, w.opts.BatchSize)
+		if err != nil {
+			return err
+		}
+		if n < w.opts.BatchSize {
+			break
+		}
+	}
+
+	// the lease outlasts the requests, which run in parallel
+	due, err := w.outbox.ClaimDeliveries(ctx, w.now(), 2*w.opts.Timeout, w.opts.BatchSize)
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	for _, dd := range due {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.attempt(ctx, dd)
+		}()
+	}
+	wg.Wait()
+
+	if now := w.now(); now.Sub(w.lastPrune) >= pruneInterval {
+		if _, err := w.outbox.Prune(ctx, now.Add(-w.opts.Retention)); err != nil {
+			return err
+		}
+		w.lastPrune = now
+	}
+	return nil
+}
+
+// attempt sends a delivery once and records the outcome: success, a retry
+// after the backoff, or failure once the attempts are used up.
+func (w *Worker) attempt(ctx context.Context, dd repository.DueDelivery) {
+	d := dd.Delivery
+	status, body, err := w.send(ctx, dd.Webhook, d)
+	if ctx.Err() != nil {
+		// shutting down; the lease expires and the attempt is made again
+		return
+	}
+
+	now := w.now()
+	d.Attempts++
+	d.LastAttemptAt = &now
+	d.ResponseStatus, d.ResponseBody, d.Error = nil, body, ""
+	if err != nil {
+		d.Error = storable(err.Error())
+	} else {
+		d.ResponseStatus = &status
+	}
+	switch {
+	case err == nil && status >= 200 && status < 300:
+		d.Status, d.NextAttemptAt = domain.DeliverySucceeded, nil
+	case d.Attempts >= w.opts.MaxAttempts:
+		d.Status, d.NextAttemptAt = domain.DeliveryFailed, nil
+		w.log.Warnf("webhook %s: delivery %s of event %d failed after %d attempts", dd.Webhook.ID, d.ID, d.EventID, d.Attempts)
+	default:
+		next := now.Add(w.backoff(d.Attempts))
+		d.NextAttemptAt = &next
+	}
+
+	if err := w.outbox.SaveAttempt(ctx, d); err != nil {
+		// whatever the response held, the attempt must count, or the
+		// delivery is claimed again once its lease runs out and retried
+		// past MaxAttempts
+		w.log.Errorf("webhook %s: record delivery %s: %v", dd.Webhook.ID, d.ID, err)
+		d.ResponseBody, d.Error = "", "recording the response failed"
+		if err := w.outbox.SaveAttempt(ctx, d); err != nil {
+			w.log.Errorf("webhook %s: record delivery %s: %v", dd.Webhook.ID, d.ID, err)
+		}
+	}
+}
+
+func (w *Worker) send(ctx context.Context, hook *domain.Webhook, d *domain.WebhookDelivery) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(EventHeader, string(d.EventType))
+	req.Header.Set(DeliveryHeader, d.ID.String())
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, w.now(), d.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	return resp.StatusCode, storable(string(body)), nil
+}
+
+// storable makes text from an endpoint fit a text column: the cut at
+// maxResponseBody can split a character, and Postgres rejects invalid
+// UTF-8 and NUL bytes.
+func storable(s string) string {
+	return strings.ReplaceAll(strings.ToValidUTF8(s, "\uFFFD"), "\x00", "")
+}
+
+// backoff is the wait after the given number of failed attempts.
+func (w *Worker) backoff(attempts int) time.Duration {
+	d := w.opts.BackoffBase
+	for i := 1; i < attempts && d < w.opts.BackoffMax; i++ {
+		d *= 2
+	}
+	return min(d, w.opts.BackoffMax)
+}
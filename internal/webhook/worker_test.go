@@ -0,0 +1,373 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"subcalc/internal/domain"
+	"subcalc/internal/repository"
+	memrepo "subcalc/internal/repository/memory"
+	"subcalc/internal/repository/repotest"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"id":1}`)
+	sig := Sign("whsec_x", now, body)
+
+	if err := Verify("whsec_x", sig, body, now, time.Minute); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if err := Verify("whsec_y", sig, body, now, time.Minute); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("wrong secret: want ErrInvalidSignature, got %v", err)
+	}
+	if err := Verify("whsec_x", sig, []byte(`{"id":2}`), now, time.Minute); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("tampered body: want ErrInvalidSignature, got %v", err)
+	}
+	if err := Verify("whsec_x", sig, body, now.Add(time.Hour), time.Minute); !errors.Is(err, ErrStaleSignature) {
+		t.Fatalf("replay: want ErrStaleSignature, got %v", err)
+	}
+	if err := Verify("whsec_x", "garbage", body, now, time.Minute); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("malformed header: want ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	w := &Worker{opts: Options{BackoffBase: 10 * time.Second, BackoffMax: time.Minute}}
+	want := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute}
+	for i, d := range want {
+		if got := w.backoff(i + 1); got != d {
+			t.Errorf("backoff after %d attempts: want %s, got %s", i+1, d, got)
+		}
+	}
+}
+
+type fixture struct {
+	worker   *Worker
+	subs     repository.SubscriptionRepository
+	webhooks repository.WebhookRepository
+	hook     *domain.Webhook
+	clock    time.Time
+}
+
+// newFixture wires a worker to a memory outbox and a webhook on url
+// wanting subscription.created, on a clock the test advances.
+func newFixture(t *testing.T, url string, maxAttempts int) *fixture {
+	t.Helper()
+	store := memrepo.NewStore()
+	f := &fixture{
+		subs:     memrepo.NewMemorySubscriptionRepo(store),
+		webhooks: memrepo.NewMemoryWebhookRepo(store),
+		clock:    time.Now().UTC().Add(time.Second),
+	}
+	f.worker = NewWorker(memrepo.NewMemoryOutboxRepo(store), Options{
+		Interval:    time.Second,
+		BatchSize:   10,
+		MaxAttempts: maxAttempts,
+		BackoffBase: 10 * time.Second,
+		BackoffMax:  time.Hour,
+		Timeout:     5 * time.Second,
+		Retention:   time.Hour,
+	}, zap.NewNop().Sugar())
+	f.worker.now = func() time.Time { return f.clock }
+	// the test servers listen on loopback, which deliveries may not reach
+	f.worker.client.Transport = http.DefaultTransport
+
+	f.hook = &domain.Webhook{URL: url, Secret: "whsec_test", Events: []domain.EventType{domain.EventSubscriptionCreated}, Active: true}
+	if err := f.webhooks.Create(context.Background(), f.hook); err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+	return f
+}
+
+func (f *fixture) run(t *testing.T) {
+	t.Helper()
+	if err := f.worker.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+}
+
+func (f *fixture) delivery(t *testing.T) *domain.WebhookDelivery {
+	t.Helper()
+	ds, err := f.webhooks.ListDeliveries(context.Background(), f.hook.ID, 10)
+	if err != nil || len(ds) != 1 {
+		t.Fatalf("want one delivery, got %d, %v", len(ds), err)
+	}
+	return ds[0]
+}
+
+func TestWorker_DeliversSignedEvents(t *testing.T) {
+	var mu sync.Mutex
+	var got []*http.Request
+	var bodies [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		got, bodies = append(got, r), append(bodies, body)
+		mu.Unlock()
+		_, _ = w.Write([]byte("thanks"))
+	}))
+	defer srv.Close()
+
+	f := newFixture(t, srv.URL, 3)
+	sub := &domain.Subscription{ServiceName: "Netflix", Price: 499, UserID: uuid.New(), StartDate: repotest.Month(2025, 7)}
+	if err := f.subs.Create(context.Background(), sub); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	// not wanted by the webhook
+	if err := f.subs.Delete(context.Background(), sub.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	f.run(t)
+
+	if len(got) != 1 {
+		t.Fatalf("want one request, got %d", len(got))
+	}
+	r, body := got[0], bodies[0]
+	d := f.delivery(t)
+	if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" ||
+		r.Header.Get(EventHeader) != string(domain.EventSubscriptionCreated) || r.Header.Get(DeliveryHeader) != d.ID.String() {
+		t.Fatalf("unexpected request %s with headers %v", r.Method, r.Header)
+	}
+	if err := Verify("whsec_test", r.Header.Get(SignatureHeader), body, f.clock, time.Minute); err != nil {
+		t.Fatalf("signature: %v", err)
+	}
+	var e domain.Event
+	if err := json.Unmarshal(body, &e); err != nil || e.Type != domain.EventSubscriptionCreated || e.Data.Subscription.ID != sub.ID {
+		t.Fatalf("body %s: %v", body, err)
+	}
+
+	if d.Status != domain.DeliverySucceeded || d.Attempts != 1 || d.ResponseStatus == nil || *d.ResponseStatus != 200 || d.ResponseBody != "thanks" {
+		t.Fatalf("delivery not recorded as succeeded: %+v", d)
+	}
+	f.run(t)
+	if len(got) != 1 {
+		t.Fatalf("a succeeded delivery was sent again")
+	}
+}
+
+func TestWorker_RetriesWithBackoff(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls < 3 {
+			http.Error(w, "try later", http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	f := newFixture(t, srv.URL, 5)
+	if err := f.subs.Create(context.Background(), &domain.Subscription{ServiceName: "A", Price: 1, UserID: uuid.New(), StartDate: repotest.Month(2025, 1)}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	f.run(t)
+	d := f.delivery(t)
+	if d.Status != domain.DeliveryPending || d.Attempts != 1 || *d.ResponseStatus != 503 || !d.NextAttemptAt.Equal(f.clock.Add(10*time.Second)) {
+		t.Fatalf("first failure: want a retry in 10s, got %+v", d)
+	}
+
+	f.clock = f.clock.Add(5 * time.Second)
+	f.run(t)
+	if calls != 1 {
+		t.Fatalf("retried before the backoff elapsed")
+	}
+
+	f.clock = f.clock.Add(5 * time.Second)
+	f.run(t)
+	if d = f.delivery(t); d.Attempts != 2 || !d.NextAttemptAt.Equal(f.clock.Add(20*time.Second)) {
+		t.Fatalf("second failure: want a retry in 20s, got %+v", d)
+	}
+
+	f.clock = f.clock.Add(20 * time.Second)
+	f.run(t)
+	if d = f.delivery(t); d.Status != domain.DeliverySucceeded || d.Attempts != 3 || d.NextAttemptAt != nil {
+		t.Fatalf("third attempt: want success, got %+v", d)
+	}
+}
+
+func TestWorker_FailsAfterMaxAttempts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "broken", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	f := newFixture(t, srv.URL, 2)
+	if err := f.subs.Create(context.Background(), &domain.Subscription{ServiceName: "A", Price: 1, UserID: uuid.New(), StartDate: repotest.Month(2025, 1)}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	f.run(t)
+	f.clock = f.clock.Add(time.Minute)
+	f.run(t)
+
+	d := f.delivery(t)
+	if d.Status != domain.DeliveryFailed || d.Attempts != 2 || d.NextAttemptAt != nil || d.ResponseBody != "broken\n" {
+		t.Fatalf("want a failed delivery after 2 attempts, got %+v", d)
+	}
+}
+
+func TestWorker_RecordsTransportErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Close()
+
+	f := newFixture(t, srv.URL, 3)
+	if err := f.subs.Create(context.Background(), &domain.Subscription{ServiceName: "A", Price: 1, UserID: uuid.New(), StartDate: repotest.Month(2025, 1)}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	f.run(t)
+
+	d := f.delivery(t)
+	if d.Status != domain.DeliveryPending || d.Attempts != 1 || d.ResponseStatus != nil || d.Error == "" {
+		t.Fatalf("want a pending delivery with the error recorded, got %+v", d)
+	}
+}
+
+// strictOutbox rejects response bodies Postgres cannot store in a text
+// column.
+type strictOutbox struct {
+	repository.OutboxRepository
+	rejected int
+}
+
+func (o *strictOutbox) SaveAttempt(ctx context.Context, d *domain.WebhookDelivery) error {
+	if !utf8.ValidString(d.ResponseBody) || strings.ContainsRune(d.ResponseBody, 0) {
+		o.rejected++
+		return errors.New("invalid byte sequence for encoding UTF8")
+	}
+	return o.OutboxRepository.SaveAttempt(ctx, d)
+}
+
+func TestWorker_StoresNonUTF8Responses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		// the cut at maxResponseBody splits a Cyrillic letter
+		_, _ = w.Write([]byte("x\x00\xff" + strings.Repeat("ошибка", 200)))
+	}))
+	defer srv.Close()
+
+	f := newFixture(t, srv.URL, 3)
+	outbox := &strictOutbox{OutboxRepository: f.worker.outbox}
+	f.worker.outbox = outbox
+	if err := f.subs.Create(context.Background(), &domain.Subscription{ServiceName: "A", Price: 1, UserID: uuid.New(), StartDate: repotest.Month(2025, 1)}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	f.run(t)
+
+	d := f.delivery(t)
+	if outbox.rejected != 0 || d.Attempts != 1 || d.NextAttemptAt == nil || *d.ResponseStatus != 502 {
+		t.Fatalf("want the attempt recorded, got %d rejections and %+v", outbox.rejected, d)
+	}
+	if !utf8.ValidString(d.ResponseBody) || strings.ContainsRune(d.ResponseBody, 0) || !strings.HasPrefix(d.ResponseBody, "x�ошибка") {
+		t.Fatalf("unexpected response body %q", d.ResponseBody)
+	}
+}
+
+// failingOutbox fails the first attempt to record a delivery.
+type failingOutbox struct {
+	repository.OutboxRepository
+	failed bool
+}
+
+func (o *failingOutbox) SaveAttempt(ctx context.Context, d *domain.WebhookDelivery) error {
+	if !o.failed {
+		o.failed = true
+		return errors.New("value too long")
+	}
+	return o.OutboxRepository.SaveAttempt(ctx, d)
+}
+
+func TestWorker_CountsAttemptsWhoseResponseCannotBeStored(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "broken", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	f := newFixture(t, srv.URL, 1)
+	f.worker.outbox = &failingOutbox{OutboxRepository: f.worker.outbox}
+	if err := f.subs.Create(context.Background(), &domain.Subscription{ServiceName: "A", Price: 1, UserID: uuid.New(), StartDate: repotest.Month(2025, 1)}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	f.run(t)
+
+	if d := f.delivery(t); d.Status != domain.DeliveryFailed || d.Attempts != 1 || d.ResponseBody != "" {
+		t.Fatalf("want the delivery failed without its response, got %+v", d)
+	}
+}
+
+func TestWorker_RefusesPrivateAddresses(t *testing.T) {
+	hit := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hit = true }))
+	defer srv.Close()
+
+	f := newFixture(t, srv.URL, 3)
+	f.worker.client = newClient(5 * time.Second)
+	if err := f.subs.Create(context.Background(), &domain.Subscription{ServiceName: "A", Price: 1, UserID: uuid.New(), StartDate: repotest.Month(2025, 1)}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	f.run(t)
+
+	if d := f.delivery(t); hit || d.Attempts != 1 || !strings.Contains(d.Error, ErrPrivateAddress.Error()) {
+		t.Fatalf("want the loopback endpoint refused, got hit=%v, %+v", hit, d)
+	}
+}
+
+func TestWorker_DoesNotFollowRedirects(t *testing.T) {
+	followed := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hook", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/internal", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/internal", func(w http.ResponseWriter, r *http.Request) { followed = true })
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	f := newFixture(t, srv.URL+"/hook", 3)
+	if err := f.subs.Create(context.Background(), &domain.Subscription{ServiceName: "A", Price: 1, UserID: uuid.New(), StartDate: repotest.Month(2025, 1)}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	f.run(t)
+
+	if d := f.delivery(t); followed || d.Status != domain.DeliveryPending || d.ResponseStatus == nil || *d.ResponseStatus != http.StatusTemporaryRedirect {
+		t.Fatalf("want the redirect recorded as a failed attempt, got followed=%v, %+v", followed, d)
+	}
+}
+
+func TestPublicHost(t *testing.T) {
+	cases := map[string]bool{
+		"example.com":     true,
+		"93.184.215.14":   true,
+		"2606:4700::1111": true,
+		"localhost":       false,
+		"api.localhost":   false,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"100.64.0.1":      false,
+		"169.254.169.254": false,
+		"0.0.0.0":         false,
+		"[::1]":           false,
+		"fd00::1":         false,
+		"fe80::1":         false,
+		"::ffff:10.0.0.1": false,
+	}
+	for host, want := range cases {
+		if got := PublicHost(host); got != want {
+			t.Errorf("PublicHost(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_api_keys_org_id;
+DROP INDEX IF EXISTS idx_subscriptions_org_user;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS org_id;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS org_id;
+DROP TABLE IF EXISTS organizations;
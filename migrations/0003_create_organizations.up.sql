@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS organizations (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name text NOT NULL,
+    default_currency char(3) NOT NULL DEFAULT 'RUB',
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now()
+    );
+
+INSERT INTO organizations (id, name)
+VALUES ('00000000-0000-0000-0000-000000000001', 'default')
+ON CONFLICT (id) DO NOTHING;
+
+-- existing rows land in the default org; new rows must name their org
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS org_id uuid NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);
+ALTER TABLE subscriptions ALTER COLUMN org_id DROP DEFAULT;
+
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS org_id uuid NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations(id);
+ALTER TABLE api_keys ALTER COLUMN org_id DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_org_user ON subscriptions(org_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_org_id ON api_keys(org_id);
@@ -0,0 +1,2 @@
+-- the extension stays: other objects may have come to depend on it
+DROP INDEX IF EXISTS idx_subscriptions_service_name_trgm;
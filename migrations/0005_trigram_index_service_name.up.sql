@@ -0,0 +1,4 @@
+-- fuzzy search over service names ranks with pg_trgm; the index serves its
+-- % and <% operators
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_subscriptions_service_name_trgm ON subscriptions USING gin (service_name gin_trgm_ops);
@@ -0,0 +1,23 @@
+-- one row per reminder and channel; the unique key is what keeps the
+-- scheduler from sending a reminder twice
+CREATE TABLE IF NOT EXISTS reminders (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id uuid NOT NULL REFERENCES organizations(id),
+    subscription_id uuid NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    kind text NOT NULL,
+    due_on date NOT NULL,
+    channel text NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    sent_at timestamp with time zone NULL,
+    error text NOT NULL DEFAULT '',
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    UNIQUE (subscription_id, kind, due_on, channel)
+    );
+
+-- leases that let one replica at a time run a background job
+CREATE TABLE IF NOT EXISTS scheduler_locks (
+    name text PRIMARY KEY,
+    holder text NOT NULL,
+    expires_at timestamp with time zone NOT NULL
+    );
@@ -0,0 +1,28 @@
+// Package migrations embeds the SQL migrations so the binary can apply them
+// without the files on disk.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS holds the Postgres migrations.
+//
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// SQLite holds the same migrations written for SQLite. Versions must stay
+// in step with FS.
+var SQLite = mustSub(sqliteFS, "sqlite")
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -0,0 +1,14 @@
+-- dates and timestamps are stored as text in the driver's time format,
+-- which sorts chronologically as long as every value is UTC
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id text PRIMARY KEY,
+    service_name text NOT NULL,
+    price integer NOT NULL,
+    user_id text NOT NULL,
+    start_date datetime NOT NULL,
+    end_date datetime NULL,
+    created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_user_id ON subscriptions(user_id);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id text PRIMARY KEY,
+    name text NOT NULL,
+    prefix text NOT NULL,
+    hash text NOT NULL,
+    scopes text NOT NULL DEFAULT '',
+    created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at datetime NULL,
+    revoked_at datetime NULL
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_hash ON api_keys(hash);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS organizations (
+    id text PRIMARY KEY,
+    name text NOT NULL,
+    default_currency text NOT NULL DEFAULT 'RUB',
+    created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+INSERT INTO organizations (id, name)
+VALUES ('00000000-0000-0000-0000-000000000001', 'default')
+ON CONFLICT (id) DO NOTHING;
+
+-- SQLite can neither add a foreign key with a non-null default nor drop a
+-- default later, so org_id keeps the default org as default and has no
+-- REFERENCES; the repositories always set it
+ALTER TABLE subscriptions
+    ADD COLUMN org_id text NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+
+ALTER TABLE api_keys
+    ADD COLUMN org_id text NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_org_user ON subscriptions(org_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_org_id ON api_keys(org_id);
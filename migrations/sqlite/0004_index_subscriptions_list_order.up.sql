@@ -0,0 +1,2 @@
+-- List pages by keyset on (created_at, id) within an organization
+CREATE INDEX IF NOT EXISTS idx_subscriptions_org_created ON subscriptions(org_id, created_at, id);
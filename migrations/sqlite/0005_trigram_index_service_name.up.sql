@@ -0,0 +1,3 @@
+-- SQLite has no trigram index; search ranks in Go. Kept in step with the
+-- Postgres migrations.
+SELECT 1;
@@ -0,0 +1,45 @@
+-- AUTOINCREMENT keeps event ids from being reused after pruning, so they
+-- only ever grow
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    org_id text NOT NULL REFERENCES organizations(id),
+    type text NOT NULL,
+    data text NOT NULL,
+    created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    dispatched_at datetime NULL
+    );
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_undispatched ON outbox_events(id) WHERE dispatched_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id text PRIMARY KEY,
+    org_id text NOT NULL REFERENCES organizations(id),
+    url text NOT NULL,
+    secret text NOT NULL,
+    events text NOT NULL DEFAULT '',
+    active boolean NOT NULL DEFAULT true,
+    created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_org_id ON webhooks(org_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id text PRIMARY KEY,
+    org_id text NOT NULL REFERENCES organizations(id),
+    webhook_id text NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id integer NOT NULL,
+    event_type text NOT NULL,
+    payload text NOT NULL,
+    status text NOT NULL DEFAULT 'pending',
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at datetime NULL,
+    last_attempt_at datetime NULL,
+    response_status integer NULL,
+    response_body text NOT NULL DEFAULT '',
+    error text NOT NULL DEFAULT '',
+    created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS scheduler_locks;
+DROP TABLE IF EXISTS reminders;
@@ -0,0 +1,227 @@
+// Package client is a Go client for the subscriptions HTTP API.
+//
+//	c, err := client.New("http://localhost:8080", client.WithAPIKey(key))
+//	sub, err := c.Create(ctx, &client.CreateSubscriptionRequest{...})
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Client struct {
+	baseURL *url.URL
+	http    *http.Client
+	header  http.Header
+	retry   RetryPolicy
+}
+
+// RetryPolicy controls retries of failed requests. Network errors, 502, 503
+// and 504 are retried for idempotent methods; 429 is retried for every
+// method because the server rejected the request before handling it.
+// Delays grow exponentially from BaseDelay up to MaxDelay, with jitter;
+// a Retry-After header takes precedence.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+type Option func(*Client)
+
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithAPIKey authenticates with an "sk_..." key in X-API-Key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.header.Set("X-API-Key", key) }
+}
+
+// WithToken authenticates with a JWT bearer token.
+func WithToken(token string) Option {
+	return func(c *Client) { c.header.Set("Authorization", "Bearer "+token) }
+}
+
+// WithOrgID selects the organization via X-Org-Id.
+func WithOrgID(id uuid.UUID) Option {
+	return func(c *Client) { c.header.Set("X-Org-Id", id.String()) }
+}
+
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.header.Set("User-Agent", ua) }
+}
+
+// WithRetry replaces DefaultRetryPolicy; MaxAttempts 1 disables retries.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) { c.retry = p }
+}
+
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base url %q", baseURL)
+	}
+	c := &Client{
+		baseURL: u,
+		http:    &http.Client{Timeout: 30 * time.Second},
+		header:  http.Header{"User-Agent": {"subcalc-go-client"}},
+		retry:   DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.retry.MaxAttempts < 1 {
+		c.retry.MaxAttempts = 1
+	}
+	return c, nil
+}
+
+const (
+	mergePatchType = "application/merge-patch+json"
+	jsonPatchType  = "application/json-patch+json"
+)
+
+// typedBody is a request body sent as contentType rather than plain JSON.
+type typedBody struct {
+	contentType string
+	v           any
+}
+
+// do sends a request, retrying per the policy, and decodes a 2xx JSON body
+// into out when out is not nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) (http.Header, error) {
+	contentType := "application/json"
+	if tb, ok := in.(typedBody); ok {
+		contentType, in = tb.contentType, tb.v
+	}
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return nil, err
+		}
+	}
+	u := c.baseURL.JoinPath(path)
+	u.RawQuery = query.Encode()
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, method, u.String(), body, contentType)
+		if err != nil {
+			if ctx.Err() != nil || attempt >= c.retry.MaxAttempts || !idempotent(method) {
+				return nil, err
+			}
+			if err := c.sleep(ctx, attempt, 0); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			if out != nil && resp.StatusCode != http.StatusNoContent {
+				if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+					return resp.Header, fmt.Errorf("decode response: %w", err)
+				}
+			}
+			return resp.Header, nil
+		}
+
+		apiErr := decodeError(resp)
+		if attempt >= c.retry.MaxAttempts || !retryable(method, resp.StatusCode) {
+			return resp.Header, apiErr
+		}
+		if err := c.sleep(ctx, attempt, apiErr.RetryAfter); err != nil {
+			return resp.Header, err
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, rawURL string, body []byte, contentType string) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = c.header.Clone()
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return c.http.Do(req)
+}
+
+func decodeError(resp *http.Response) *Error {
+	defer resp.Body.Close()
+	e := &Error{StatusCode: resp.StatusCode}
+	// a body that is not an ErrorResponse (e.g. from a proxy) leaves only
+	// the status
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(e)
+	if s := resp.Header.Get("Retry-After"); s != "" {
+		if secs, err := strconv.Atoi(s); err == nil && secs >= 0 {
+			e.RetryAfter = time.Duration(secs) * time.Second
+		}
+	}
+	return e
+}
+
+func (c *Client) sleep(ctx context.Context, attempt int, retryAfter time.Duration) error {
+	d := retryAfter
+	if d <= 0 {
+		backoff := c.retry.BaseDelay << (attempt - 1)
+		if backoff <= 0 || backoff > c.retry.MaxDelay {
+			backoff = c.retry.MaxDelay
+		}
+		// equal jitter: at least half the backoff, so retries still spread out
+		d = backoff/2 + time.Duration(rand.Int64N(int64(backoff/2)+1))
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func retryable(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent(method)
+	}
+	return false
+}
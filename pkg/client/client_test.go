@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var fastRetry = WithRetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+func newTestClient(t *testing.T, h http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	c, err := New(srv.URL, append([]Option{fastRetry}, opts...)...)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	return c
+}
+
+func TestCreate_SendsMonthYearAndDecodesSubscription(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "sk_test" {
+			t.Errorf("missing api key header")
+		}
+		var req CreateSubscriptionRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.StartDate != "07-2025" {
+			t.Errorf("start_date = %q", req.StartDate)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"` + uuid.NewString() + `","service_name":"Netflix","price":499,"user_id":"` + uuid.NewString() + `","start_date":"07-2025","end_date":"12-2025"}`))
+	}, WithAPIKey("sk_test"))
+
+	sub, err := c.Create(context.Background(), &CreateSubscriptionRequest{
+		ServiceName: "Netflix", Price: 499, UserID: uuid.NewString(),
+		StartDate: MonthYear(time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sub.StartDate.Month() != time.July || sub.EndDate == nil || sub.EndDate.Month() != time.December {
+		t.Fatalf("dates not decoded: %+v", sub)
+	}
+}
+
+func TestUpdate_SendsMergePatch(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var patch map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&patch)
+		if r.Method != http.MethodPatch || r.Header.Get("Content-Type") != "application/merge-patch+json" {
+			t.Errorf("sent %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if v, ok := patch["end_date"]; !ok || v != nil || patch["price"] != 399.0 || len(patch) != 2 {
+			t.Errorf("patch = %v, want price and a null end_date", patch)
+		}
+		_, _ = w.Write([]byte(`{"id":"` + uuid.NewString() + `","service_name":"Netflix","price":399,"user_id":"` + uuid.NewString() + `","start_date":"07-2025"}`))
+	})
+
+	price, end := 399, "12-2025"
+	if _, err := c.Update(context.Background(), uuid.New(), &UpdateSubscriptionRequest{Price: &price, ClearEndDate: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.Update(context.Background(), uuid.New(), &UpdateSubscriptionRequest{EndDate: &end, ClearEndDate: true}); err == nil {
+		t.Fatalf("want EndDate with ClearEndDate refused")
+	}
+}
+
+func TestErrors_DecodeErrorResponse(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code":"invalid_field","message":"invalid id","fields":{"id":"invalid uuid"}}`))
+	})
+
+	_, err := c.Get(context.Background(), uuid.New())
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrBadRequest) || errors.Is(err, ErrNotFound) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if apiErr.Code != "invalid_field" || apiErr.Fields["id"] != "invalid uuid" {
+		t.Fatalf("unexpected error body: %+v", apiErr)
+	}
+}
+
+func TestRetry_IdempotentRequests(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	if err := c.Delete(context.Background(), uuid.New()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls.Load())
+	}
+}
+
+func TestRetry_PostOnlyOnRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	if _, err := c.Purge(context.Background(), uuid.New()); !errors.Is(err, ErrServer) {
+		t.Fatalf("expected ErrServer, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("POST must not be retried on 503, got %d attempts", calls.Load())
+	}
+}
+
+func TestRetry_StopsOnContextCancel(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.Get(ctx, uuid.New()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestAll_WalksEveryPage(t *testing.T) {
+	const total = 5
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		// the fake's cursor is the offset of the page
+		offset, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+		items := []map[string]any{}
+		for i := offset; i < total && i < offset+limit; i++ {
+			items = append(items, map[string]any{"id": uuid.NewString(), "price": i, "start_date": "01-2025"})
+		}
+		w.Header().Set("X-Total-Count", strconv.Itoa(total))
+		if offset+limit < total {
+			w.Header().Set("X-Next-Cursor", strconv.Itoa(offset+limit))
+		}
+		_ = json.NewEncoder(w).Encode(items)
+	})
+
+	var prices []int
+	for sub, err := range c.All(context.Background(), ListParams{Limit: 2}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		prices = append(prices, sub.Price)
+	}
+	if len(prices) != total || prices[4] != 4 {
+		t.Fatalf("unexpected items: %v", prices)
+	}
+}
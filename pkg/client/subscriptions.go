@@ -0,0 +1,290 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const subscriptionsPath = "/api/subscriptions"
+
+func (c *Client) Create(ctx context.Context, req *CreateSubscriptionRequest) (*Subscription, error) {
+	var sub Subscription
+	if _, err := c.do(ctx, http.MethodPost, subscriptionsPath, nil, req, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (c *Client) Get(ctx context.Context, id uuid.UUID) (*Subscription, error) {
+	var sub Subscription
+	if _, err := c.do(ctx, http.MethodGet, subscriptionsPath+"/"+id.String(), nil, nil, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Update changes the fields set in req and keeps the others, sending them
+// as a JSON merge patch.
+func (c *Client) Update(ctx context.Context, id uuid.UUID, req *UpdateSubscriptionRequest) (*Subscription, error) {
+	if req.ClearEndDate && req.EndDate != nil {
+		return nil, errors.New("EndDate and ClearEndDate are mutually exclusive")
+	}
+	patch := map[string]any{}
+	if req.ServiceName != nil {
+		patch["service_name"] = *req.ServiceName
+	}
+	if req.Price != nil {
+		patch["price"] = *req.Price
+	}
+	if req.UserID != nil {
+		patch["user_id"] = *req.UserID
+	}
+	if req.StartDate != nil {
+		patch["start_date"] = *req.StartDate
+	}
+	if req.EndDate != nil {
+		patch["end_date"] = *req.EndDate
+	}
+	if req.ClearEndDate {
+		patch["end_date"] = nil
+	}
+	var sub Subscription
+	if _, err := c.do(ctx, http.MethodPatch, subscriptionsPath+"/"+id.String(), nil, typedBody{mergePatchType, patch}, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Replace sets every field of the subscription; an EndDate left nil makes
+// it open-ended.
+func (c *Client) Replace(ctx context.Context, id uuid.UUID, req *ReplaceSubscriptionRequest) (*Subscription, error) {
+	var sub Subscription
+	if _, err := c.do(ctx, http.MethodPut, subscriptionsPath+"/"+id.String(), nil, req, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Patch applies JSON patch operations to the subscription, all or none of
+// them. A failed "test" operation is an ErrConflict.
+func (c *Client) Patch(ctx context.Context, id uuid.UUID, ops []PatchOperation) (*Subscription, error) {
+	var sub Subscription
+	if _, err := c.do(ctx, http.MethodPatch, subscriptionsPath+"/"+id.String(), nil, typedBody{jsonPatchType, ops}, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (c *Client) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := c.do(ctx, http.MethodDelete, subscriptionsPath+"/"+id.String(), nil, nil, nil)
+	return err
+}
+
+// List fetches one page.
+func (c *Client) List(ctx context.Context, p ListParams) (*Page, error) {
+	q := url.Values{}
+	if p.UserID != nil {
+		q.Add("user_id", p.UserID.String())
+	}
+	for _, id := range p.UserIDs {
+		q.Add("user_id", id.String())
+	}
+	if p.ServiceName != "" {
+		q.Set("service_name", p.ServiceName)
+	}
+	if p.ServiceNamePrefix != "" {
+		q.Set("service_name_prefix", p.ServiceNamePrefix)
+	}
+	if p.IgnoreCase {
+		q.Set("ignore_case", "true")
+	}
+	if p.PriceMin != nil {
+		q.Set("price_min", strconv.Itoa(*p.PriceMin))
+	}
+	if p.PriceMax != nil {
+		q.Set("price_max", strconv.Itoa(*p.PriceMax))
+	}
+	if p.From != nil {
+		q.Set("from", MonthYear(*p.From))
+	}
+	if p.To != nil {
+		q.Set("to", MonthYear(*p.To))
+	}
+	if p.ActiveOn != nil {
+		q.Set("active_on", MonthYear(*p.ActiveOn))
+	}
+	if p.OpenEnded != nil {
+		q.Set("open_ended", strconv.FormatBool(*p.OpenEnded))
+	}
+	if p.CreatedAfter != nil {
+		q.Set("created_after", p.CreatedAfter.Format(time.RFC3339))
+	}
+	if p.UpdatedAfter != nil {
+		q.Set("updated_after", p.UpdatedAfter.Format(time.RFC3339))
+	}
+	if p.Sort != "" {
+		q.Set("sort", p.Sort)
+	}
+	if p.Limit > 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Cursor != "" {
+		q.Set("cursor", p.Cursor)
+	} else if p.Offset > 0 {
+		q.Set("offset", strconv.Itoa(p.Offset))
+	}
+
+	var items []*Subscription
+	h, err := c.do(ctx, http.MethodGet, subscriptionsPath, q, nil, &items)
+	if err != nil {
+		return nil, err
+	}
+	page := &Page{Items: items, NextCursor: h.Get("X-Next-Cursor"), PrevCursor: h.Get("X-Prev-Cursor")}
+	page.Total, _ = strconv.ParseInt(h.Get("X-Total-Count"), 10, 64)
+	return page, nil
+}
+
+// All iterates over every subscription matching p, fetching pages of
+// p.Limit as it goes: by cursor, or by offset when p.Sort is set.
+// Iteration stops at the first error, which is yielded with a nil
+// subscription.
+//
+//	for sub, err := range c.All(ctx, client.ListParams{UserID: &uid}) {
+//		if err != nil { ... }
+//	}
+func (c *Client) All(ctx context.Context, p ListParams) iter.Seq2[*Subscription, error] {
+	return func(yield func(*Subscription, error) bool) {
+		for {
+			page, err := c.List(ctx, p)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, sub := range page.Items {
+				if !yield(sub, nil) {
+					return
+				}
+			}
+			if p.Sort != "" {
+				p.Offset += len(page.Items)
+				if len(page.Items) == 0 || int64(p.Offset) >= page.Total {
+					return
+				}
+				continue
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			p.Cursor = page.NextCursor
+		}
+	}
+}
+
+func (c *Client) Sum(ctx context.Context, p SumParams) (*TotalResponse, error) {
+	q := url.Values{}
+	q.Set("from", MonthYear(p.From))
+	q.Set("to", MonthYear(p.To))
+	if p.UserID != nil {
+		q.Set("user_id", p.UserID.String())
+	}
+	if p.ServiceName != "" {
+		q.Set("service_name", p.ServiceName)
+	}
+
+	var total TotalResponse
+	if _, err := c.do(ctx, http.MethodGet, subscriptionsPath+"/sum", q, nil, &total); err != nil {
+		return nil, err
+	}
+	return &total, nil
+}
+
+// Search finds subscriptions by a fuzzy match of their service name, best
+// first.
+func (c *Client) Search(ctx context.Context, p SearchParams) ([]SearchResult, error) {
+	q := url.Values{}
+	q.Set("q", p.Query)
+	if p.Limit > 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.UserID != nil {
+		q.Set("user_id", p.UserID.String())
+	}
+
+	var out []SearchResult
+	if _, err := c.do(ctx, http.MethodGet, subscriptionsPath+"/search", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Breakdown is Sum split by month and by service.
+func (c *Client) Breakdown(ctx context.Context, p SumParams) (*BreakdownResponse, error) {
+	q := url.Values{}
+	q.Set("from", MonthYear(p.From))
+	q.Set("to", MonthYear(p.To))
+	if p.UserID != nil {
+		q.Set("user_id", p.UserID.String())
+	}
+	if p.ServiceName != "" {
+		q.Set("service_name", p.ServiceName)
+	}
+
+	var out BreakdownResponse
+	if _, err := c.do(ctx, http.MethodGet, subscriptionsPath+"/breakdown", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Forecast projects the charges of the coming months.
+func (c *Client) Forecast(ctx context.Context, p ForecastParams) (*BreakdownResponse, error) {
+	q := url.Values{}
+	if p.Months > 0 {
+		q.Set("months", strconv.Itoa(p.Months))
+	}
+	if p.From != nil {
+		q.Set("from", MonthYear(*p.From))
+	}
+	if p.UserID != nil {
+		q.Set("user_id", p.UserID.String())
+	}
+	if p.ServiceName != "" {
+		q.Set("service_name", p.ServiceName)
+	}
+
+	var out BreakdownResponse
+	if _, err := c.do(ctx, http.MethodGet, subscriptionsPath+"/forecast", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BulkDelete removes the given subscriptions; it needs the admin role.
+func (c *Client) BulkDelete(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	req := BulkDeleteRequest{IDs: make([]string, len(ids))}
+	for i, id := range ids {
+		req.IDs[i] = id.String()
+	}
+	var out DeletedResponse
+	if _, err := c.do(ctx, http.MethodPost, subscriptionsPath+"/bulk-delete", nil, req, &out); err != nil {
+		return 0, err
+	}
+	return out.Deleted, nil
+}
+
+// Purge removes every subscription of a user; it needs the admin role.
+func (c *Client) Purge(ctx context.Context, userID uuid.UUID) (int64, error) {
+	req := PurgeRequest{UserID: userID.String()}
+	var out DeletedResponse
+	if _, err := c.do(ctx, http.MethodPost, subscriptionsPath+"/purge", nil, req, &out); err != nil {
+		return 0, err
+	}
+	return out.Deleted, nil
+}
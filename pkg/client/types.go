@@ -0,0 +1,108 @@
+package client
+
+import (
+	httpdto "subcalc/internal/delivery/http"
+	"subcalc/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The wire types are the server's own, aliased so that code outside this
+// module can name them.
+type (
+	Subscription               = domain.Subscription
+	CreateSubscriptionRequest  = httpdto.CreateSubscriptionRequest
+	ReplaceSubscriptionRequest = httpdto.ReplaceSubscriptionRequest
+	TotalResponse              = httpdto.TotalResponse
+	BreakdownResponse          = httpdto.BreakdownResponse
+	SearchResult               = httpdto.SearchResult
+	BulkDeleteRequest          = httpdto.BulkDeleteRequest
+	PurgeRequest               = httpdto.PurgeRequest
+	DeletedResponse            = httpdto.DeletedResponse
+)
+
+// UpdateSubscriptionRequest names the fields Update changes; fields left
+// nil are kept. ClearEndDate makes the subscription open-ended and cannot
+// be combined with EndDate.
+type UpdateSubscriptionRequest struct {
+	ServiceName  *string
+	Price        *int
+	UserID       *string
+	StartDate    *string
+	EndDate      *string
+	ClearEndDate bool
+}
+
+// PatchOperation is one operation of a JSON patch (RFC 6902), such as
+// {Op: "replace", Path: "/price", Value: 399}.
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// MonthYear formats t the way the API expects dates: "MM-YYYY".
+func MonthYear(t time.Time) string {
+	return domain.FormatMonthYear(t)
+}
+
+type ListParams struct {
+	UserID *uuid.UUID
+	// UserIDs matches any of the users.
+	UserIDs           []uuid.UUID
+	ServiceName       string
+	ServiceNamePrefix string
+	// IgnoreCase matches ServiceName and ServiceNamePrefix ignoring case.
+	IgnoreCase   bool
+	PriceMin     *int
+	PriceMax     *int
+	From         *time.Time
+	To           *time.Time
+	ActiveOn     *time.Time
+	OpenEnded    *bool
+	CreatedAfter *time.Time
+	UpdatedAfter *time.Time
+	// Sort is an order like "price,-start_date". It cannot be combined with
+	// Cursor, so All pages a sorted list by offset.
+	Sort string
+	// Limit defaults to the server's page size when zero.
+	Limit  int
+	Offset int
+	// Cursor is a Page's NextCursor or PrevCursor; it replaces Offset.
+	Cursor string
+}
+
+// Page is one page of a list and the total number of matching
+// subscriptions reported by the server.
+type Page struct {
+	Items []*Subscription
+	Total int64
+	// NextCursor and PrevCursor are empty when there is no such page.
+	NextCursor string
+	PrevCursor string
+}
+
+type SumParams struct {
+	From        time.Time
+	To          time.Time
+	UserID      *uuid.UUID
+	ServiceName string
+}
+
+type ForecastParams struct {
+	// Months defaults to 12 on the server when zero.
+	Months int
+	// From defaults to the current month when nil.
+	From        *time.Time
+	UserID      *uuid.UUID
+	ServiceName string
+}
+
+type SearchParams struct {
+	Query string
+	// Limit defaults to 20 on the server when zero.
+	Limit  int
+	UserID *uuid.UUID
+}